@@ -19,6 +19,9 @@ scp tmt@172.20.2.200:$remote_dir/workspaces.json "${backup_dir}/workspaces.json"
 scp tmt@172.20.2.200:$remote_dir/users.json "${backup_dir}/users.json"
 scp tmt@172.20.2.200:$remote_dir/vacations_hash.json "${backup_dir}/vacations_hash.json"
 scp tmt@172.20.2.200:$remote_dir/bss_vacations_hash.json "${backup_dir}/bss_vacations_hash.json"
+scp tmt@172.20.2.200:$remote_dir/scheduler_state.json "${backup_dir}/scheduler_state.json"
+scp tmt@172.20.2.200:$remote_dir/config.yaml "${backup_dir}/config.yaml"
+scp tmt@172.20.2.200:$remote_dir/holidays.json "${backup_dir}/holidays.json"
 scp tmt@172.20.2.200:$remote_dir/slack-bot.log "${backup_dir}/slack-bot.log"
 scp tmt@172.20.2.200:$remote_dir/slack-bot "${backup_dir}/slack-bot"
 scp tmt@172.20.2.200:$remote_dir/.env "${backup_dir}/prod.env"
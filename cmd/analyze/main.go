@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
@@ -28,7 +29,7 @@ func main() {
 
 	parkingLot := spaces.GetSpacesLot(*parkingFilename)
 	usersManager := user.NewManager(*usersFilename)
-	today := common.TodayDate()
+	today := common.DateOf(time.Now())
 	issues := 0
 
 	for spaceKey, space := range parkingLot.UnitSpaces {
@@ -10,6 +10,7 @@ import (
 	"net/http/httputil"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/AngelVI13/slack-bot/pkg/bss"
 	"github.com/AngelVI13/slack-bot/pkg/common"
@@ -22,7 +23,7 @@ type BssTokens struct {
 }
 
 func searchOperations(bssConfig BssConfig, tokens BssTokens) {
-	today := common.TodayDate()
+	today := common.DateOf(time.Now())
 	fullURL := bssConfig.url + "/staff/operations/:search"
 
 	data := map[string]any{
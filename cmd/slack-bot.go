@@ -1,20 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/AngelVI13/slack-bot/pkg/bss"
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/check_in"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
 	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/digest"
 	"github.com/AngelVI13/slack-bot/pkg/edit_parking_spaces"
 	"github.com/AngelVI13/slack-bot/pkg/edit_workspaces"
 	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/floor_map"
 	"github.com/AngelVI13/slack-bot/pkg/hcm"
+	"github.com/AngelVI13/slack-bot/pkg/import_export"
 	"github.com/AngelVI13/slack-bot/pkg/model"
 	"github.com/AngelVI13/slack-bot/pkg/parking_spaces"
 	"github.com/AngelVI13/slack-bot/pkg/parking_users"
+	"github.com/AngelVI13/slack-bot/pkg/plates"
 	"github.com/AngelVI13/slack-bot/pkg/roll"
 	"github.com/AngelVI13/slack-bot/pkg/slack"
 	"github.com/AngelVI13/slack-bot/pkg/workspaces"
@@ -36,101 +50,251 @@ func setupLogging(logPath string) *os.File {
 	return logFile
 }
 
-func addTimerEvents(ev *event.EventManager) {
-	resetParkingTimer := event.NewTimer(ev)
-	resetParkingTimer.AddDaily(
-		parking_spaces.ResetHour,
-		parking_spaces.ResetMin,
-		parking_spaces.ResetParking,
-	)
-	resetWorkspacesTimer := event.NewTimer(ev)
-	resetWorkspacesTimer.AddDaily(
-		workspaces.ResetHour,
-		workspaces.ResetMin,
-		workspaces.ResetWorkspaces,
-	)
-	handleHcmBookingTimer := event.NewTimer(ev)
+// shutdownTimeout How long to wait for in-flight events to be processed and
+// for data to be written to file before giving up.
+const shutdownTimeout = 20 * time.Second
+
+// scheduleTimerEvents (Re)schedules all daily timer events. It is called on
+// start & whenever the config file is reloaded.
+func scheduleTimerEvents(
+	scheduler *event.Scheduler,
+	schedules config.SchedulesConfig,
+	data *model.Data,
+) error {
+	var errs []error
 	// NOTE: HCM & BSS checks are triggered multiple times per day to account for
 	// people booking sick leaves or remote work early in the morning or late
 	// in the evening.
 	// Important! - the last check of the day has to be before the automatic
 	// release handling
-	for _, t := range []struct {
-		Hour int
-		Min  int
-	}{
-		{Hour: 6, Min: 0},
-		{Hour: 8, Min: 0},
-		{Hour: 9, Min: 30},
-		{Hour: parking_spaces.ResetHour - 1, Min: 45},
-	} {
-		handleHcmBookingTimer.AddDaily(
-			t.Hour,
-			t.Min,
-			hcm.HandleHcm,
-		)
+	// NOTE: default reset times of the config are only applied to the data
+	// once the schedules are valid (see model.Data.ApplyConfig)
+	parkingResetTimes := data.ParkingLot.AllResetTimesFor(schedules.ParkingReset)
+	workspacesResetTimes := data.WorkspacesLot.AllResetTimesFor(schedules.WorkspacesReset)
+	for _, resetTime := range parkingResetTimes {
+		errs = append(errs, schedules.ValidateParkingReset(resetTime))
+	}
+	for _, resetTime := range workspacesResetTimes {
+		errs = append(errs, schedules.ValidateWorkspacesReset(resetTime))
 	}
+	err := errors.Join(errs...)
+	if err != nil {
+		return err
+	}
+
+	errs = append(errs, scheduler.SetDaily(parking_spaces.ResetParking, parkingResetTimes))
+	errs = append(errs, scheduler.SetDaily(workspaces.ResetWorkspaces, workspacesResetTimes))
+	errs = append(errs, scheduler.SetCron(hcm.HandleHcm, schedules.HcmSync))
+	errs = append(errs, scheduler.SetCron(bss.HandleBss, schedules.BssSync))
+
+	// NOTE: empty schedules remove the job (i.e. disabled on reload)
+	errs = append(errs, scheduler.SetCron(
+		parking_spaces.RemindReleases,
+		optionalSchedules(schedules.ReleaseReminder),
+	))
+	errs = append(errs, scheduler.SetCron(digest.PostDigest, optionalSchedules(schedules.Digest)))
 
-	handleBssBookingTimer := event.NewTimer(ev)
-	for _, t := range []struct {
-		Hour int
-		Min  int
+	// NOTE: check-in is requested at the start of the window & no-shows are
+	// released at its end
+	for _, checkIn := range []struct {
+		window           *calendar.TimeSlot
+		request, release string
 	}{
-		{Hour: 6, Min: 5},
-		{Hour: 8, Min: 5},
-		{Hour: 9, Min: 35},
-		{Hour: parking_spaces.ResetHour - 1, Min: 50},
+		{schedules.ParkingCheckIn, check_in.RequestParkingCheckIns, check_in.ReleaseParkingNoShows},
+		{schedules.WorkspacesCheckIn, check_in.RequestWorkspacesCheckIns, check_in.ReleaseWorkspacesNoShows},
 	} {
-		handleBssBookingTimer.AddDaily(
-			t.Hour,
-			t.Min,
-			bss.HandleBss,
-		)
+		var start, end *calendar.TimeOfDay
+		if checkIn.window != nil {
+			start, end = &checkIn.window.Start, &checkIn.window.End
+		}
+		errs = append(errs, scheduler.SetDaily(checkIn.request, optionalTimes(start)))
+		errs = append(errs, scheduler.SetDaily(checkIn.release, optionalTimes(end)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func optionalTimes(t *calendar.TimeOfDay) []calendar.TimeOfDay {
+	if t == nil {
+		return nil
+	}
+	return []calendar.TimeOfDay{*t}
+}
+
+func optionalSchedules(schedule *event.CronSchedule) []*event.CronSchedule {
+	if schedule == nil {
+		return nil
+	}
+	return []*event.CronSchedule{schedule}
+}
+
+// rescheduleOnReload Applies schedules of a reloaded config file
+func rescheduleOnReload(
+	scheduler *event.Scheduler,
+	data *model.Data,
+) func(*config.Config) error {
+	return func(conf *config.Config) error {
+		return scheduleTimerEvents(scheduler, conf.Schedules, data)
 	}
 }
 
 func main() {
+	configPath := flag.String("config", config.DefaultConfigFilename, "path to yaml config file")
+	checkConfig := flag.Bool("check-config", false, "validate config file and exit")
+	flag.Parse()
+
+	if *checkConfig {
+		_, err := config.NewConfig(*configPath, ".env")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Config %s is invalid: %v\n", *configPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Config %s is valid\n", *configPath)
+		return
+	}
+
+	// NOTE: import/export subcommands work directly on data files. If the bot
+	// is running it picks up the changed files on its own.
+	if import_export.IsCliCmd(flag.Args()) {
+		conf, err := config.NewConfig(*configPath, ".env")
+		if err != nil {
+			log.Fatalf("Config %s is invalid: %v", *configPath, err)
+		}
+
+		data := model.NewData(conf, clock.Real{})
+		err = import_export.RunCli(flag.Args(), data, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	logFile := setupLogging("slack-bot.log")
 	defer logFile.Close()
 
-	config := config.NewConfigFromEnv(".env")
-	data := model.NewData(config)
+	// NOTE: SIGTERM is sent by systemd on service stop/restart
+	ctx, stopSignals := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+	defer stopSignals()
+
+	config, err := config.NewConfig(*configPath, ".env")
+	if err != nil {
+		log.Fatalf("Config %s is invalid: %v", *configPath, err)
+	}
+	clk := clock.Real{}
+	data := model.NewData(config, clk)
+
+	// NOTE: components are stopped in reverse order, therefore data has to be
+	// added first so that it is flushed to file after everything else stopped
+	lifecycle := event.NewLifecycle()
+	lifecycle.Add("data", data)
 
 	eventManager := event.NewEventManager()
+	scheduler := event.NewScheduler(
+		eventManager,
+		clk,
+		config.Location,
+		config.SchedulerStateFilename,
+	)
+	err = scheduleTimerEvents(scheduler, config.Schedules, data)
+	if err != nil {
+		log.Fatalf("Failed to schedule timer events: %v", err)
+	}
+
+	// NOTE: watcher is stopped right before data is flushed to file
+	watcher := model.NewWatcher(
+		data,
+		*configPath,
+		".env",
+		rescheduleOnReload(scheduler, data),
+	)
+	lifecycle.Add("watcher", watcher)
 
 	logger := event.NewEventLogger()
 	eventManager.Subscribe(logger, event.AnyEvent)
 
-	addTimerEvents(eventManager)
-
 	parkingSpacesManager := parking_spaces.NewManager(eventManager, data, config)
 	eventManager.SubscribeWithContext(parkingSpacesManager, event.AnyEvent)
+	lifecycle.Add("parking spaces", parkingSpacesManager)
 
 	workspacesManager := workspaces.NewManager(eventManager, data, config)
 	eventManager.SubscribeWithContext(workspacesManager, event.AnyEvent)
+	lifecycle.Add("workspaces", workspacesManager)
 
 	parkingUsersManager := parking_users.NewManager(eventManager, data, config)
 	eventManager.SubscribeWithContext(parkingUsersManager, event.AnyEvent)
+	lifecycle.Add("parking users", parkingUsersManager)
 
-	editParkingSpacesManager := edit_parking_spaces.NewManager(eventManager, data, config)
+	editParkingSpacesManager := edit_parking_spaces.NewManager(eventManager, data, config, scheduler)
 	eventManager.SubscribeWithContext(editParkingSpacesManager, event.AnyEvent)
+	lifecycle.Add("edit parking spaces", editParkingSpacesManager)
 
-	editWorkspacesManager := edit_workspaces.NewManager(eventManager, data, config)
+	editWorkspacesManager := edit_workspaces.NewManager(eventManager, data, config, scheduler)
 	eventManager.SubscribeWithContext(editWorkspacesManager, event.AnyEvent)
+	lifecycle.Add("edit workspaces", editWorkspacesManager)
+
+	importExportManager := import_export.NewManager(eventManager, data, config)
+	eventManager.SubscribeWithContext(importExportManager, event.AnyEvent)
+	lifecycle.Add("import export", importExportManager)
+
+	platesManager := plates.NewManager(eventManager, data, config)
+	eventManager.SubscribeWithContext(platesManager, event.AnyEvent)
+	lifecycle.Add("plates", platesManager)
 
 	rollManager := roll.NewManager(eventManager, config)
 	eventManager.Subscribe(rollManager, event.SlashCmdEvent)
+	lifecycle.Add("roll", rollManager)
 
 	hcmManager := hcm.NewManager(eventManager, data, config)
 	eventManager.Subscribe(hcmManager, event.TimerEvent)
+	lifecycle.Add("hcm", hcmManager)
 
 	bssManager := bss.NewManager(eventManager, data, config)
 	eventManager.Subscribe(bssManager, event.TimerEvent)
+	lifecycle.Add("bss", bssManager)
+
+	digestManager := digest.NewManager(eventManager, data, config)
+	eventManager.Subscribe(digestManager, event.TimerEvent)
+	lifecycle.Add("digest", digestManager)
+
+	checkInManager := check_in.NewManager(eventManager, data, config)
+	eventManager.SubscribeWithContext(checkInManager, event.AnyEvent)
+	lifecycle.Add("check-in", checkInManager)
+
+	floorMapServer := floor_map.NewServer(data, config)
+	lifecycle.Add("floor map server", floorMapServer)
+
+	// NOTE: event manager is stopped (drained) after scheduler & slack client
+	// stopped producing events but before any of the managers are stopped.
+	// Its event loop doesn't stop on the shutdown signal so that responses
+	// of in-flight events are still delivered to the slack client.
+	lifecycle.Add("event manager", eventManager)
+
+	lifecycle.Add("scheduler", scheduler)
 
-	slackClient := slack.NewClient(config, eventManager)
+	slackClient := slack.NewClient(config, eventManager, clk)
 	eventManager.Subscribe(slackClient, event.ResponseEvent)
+	lifecycle.Add("slack client", slackClient)
 
-	go slackClient.Listen()
+	err = lifecycle.Start(ctx)
+	if err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
+
+	<-ctx.Done()
+	slog.Info("Shutdown signal received. Stopping...")
 
-	eventManager.ManageEvents()
+	stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err = lifecycle.Stop(stopCtx)
+	if err != nil {
+		slog.Error("Failed to stop gracefully", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("Stopped")
 }
@@ -5,6 +5,7 @@ go 1.22.0
 require (
 	github.com/joho/godotenv v1.4.0
 	github.com/slack-go/slack v0.13.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/gorilla/websocket v1.4.2 // indirect
@@ -2,6 +2,7 @@ package bss
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,7 +15,9 @@ import (
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
 	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
 	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces"
 )
 
 const (
@@ -46,6 +49,10 @@ const (
 	HandleBss                = "HandleBSS"
 	LoginEndpoint            = "/auth"
 	SearchOperationsEndpoint = "/staff/operations/:search"
+
+	// requestTimeout Max duration of a BSS request (including reading the
+	// response) so that a hung BSS doesn't stall the sync forever
+	requestTimeout = time.Minute
 )
 
 type Manager struct {
@@ -56,6 +63,9 @@ type Manager struct {
 	reportPersonId  string
 	bssHashFilename string
 	vacationsHash   common.VacationsHash
+	// notificationThrottle Min time between free space notifications sent
+	// to the same user
+	notificationThrottle time.Duration
 }
 
 func NewManager(
@@ -64,13 +74,14 @@ func NewManager(
 	conf *config.Config,
 ) *Manager {
 	return &Manager{
-		eventManager:    eventManager,
-		data:            data,
-		bssConf:         conf.Bss,
-		debug:           conf.Debug,
-		reportPersonId:  conf.ReportPersonId,
-		bssHashFilename: conf.Bss.VacationsHashFilename,
-		vacationsHash:   common.LoadVacationsHash(conf.Bss.VacationsHashFilename),
+		eventManager:         eventManager,
+		data:                 data,
+		bssConf:              conf.Bss,
+		debug:                conf.Debug,
+		reportPersonId:       conf.ReportPersonId,
+		bssHashFilename:      conf.Bss.VacationsHashFilename,
+		vacationsHash:        common.LoadVacationsHash(conf.Bss.VacationsHashFilename),
+		notificationThrottle: conf.NotificationThrottle,
 	}
 }
 
@@ -95,10 +106,32 @@ func (m *Manager) Context() string {
 	return HandleBss
 }
 
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Writes processed vacations hashes to file
+func (m *Manager) Stop(ctx context.Context) error {
+	return m.SynchronizeToFile()
+}
+
 func (m *Manager) handleBss(eventTime time.Time) *common.Response {
 	var actions []event.ResponseAction
 
-	quadData, err := m.vacationsInfo(user.Quad)
+	// NOTE: BSS is requested without the data lock so that a slow BSS
+	// doesn't block slack interactions. The lock is only held while fetched
+	// vacations are applied so that users & spaces are not reloaded in the
+	// middle of it.
+	operations, err := m.fetchOperations(user.Quad)
+	if err != nil {
+		actions = append(actions, m.reportErrorAction(err.Error()))
+		return common.NewResponseEvent("BSS", actions...)
+	}
+
+	m.data.Lock()
+	defer m.data.Unlock()
+
+	quadData, err := m.vacationsInfo(operations, user.Quad)
 	if err != nil {
 		actions = append(actions, m.reportErrorAction(err.Error()))
 		return common.NewResponseEvent("BSS", actions...)
@@ -167,7 +200,7 @@ func (m *Manager) login(company user.Company) (*BssTokens, error) {
 
 func (m *Manager) searchOperations(tokens *BssTokens) (*BssResponse, error) {
 	fullURL := m.bssConf.Url + SearchOperationsEndpoint
-	today := common.TodayDate()
+	today := common.DateOf(m.data.Clock.Now())
 
 	// NOTE: we want to get list of latest updated records which have status approved
 	data := map[string]any{
@@ -221,7 +254,7 @@ func (m *Manager) searchOperations(tokens *BssTokens) (*BssResponse, error) {
 }
 
 func makeRequest(fullURL, token string, body io.Reader) ([]byte, error) {
-	client := &http.Client{}
+	client := &http.Client{Timeout: requestTimeout}
 	req, err := http.NewRequest(http.MethodPost, fullURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bss request (%q): %v", fullURL, err)
@@ -285,25 +318,30 @@ func (v Vacation) String() string {
 
 type VacationData []Vacation
 
-func (m *Manager) vacationsInfo(
-	company user.Company,
-) (VacationData, error) {
-	vacationData := VacationData{}
-
-	tokens, err := m.login(user.Quad)
+// fetchOperations Fetches approved BSS records of the company
+func (m *Manager) fetchOperations(company user.Company) ([]Operation, error) {
+	tokens, err := m.login(company)
 	if err != nil {
-		return vacationData, err
+		return nil, err
 	}
 
 	resp, err := m.searchOperations(tokens)
 	if err != nil {
-		return vacationData, err
+		return nil, err
 	}
+	return resp.Data, nil
+}
 
-	operations := resp.Data
+// vacationsInfo Returns vacations of users from fetched BSS records (only
+// current and future ones which were not processed yet)
+func (m *Manager) vacationsInfo(
+	operations []Operation,
+	company user.Company,
+) (VacationData, error) {
+	vacationData := VacationData{}
 
 	// filter only current and future vacations
-	today := common.TodayDate()
+	today := common.DateOf(m.data.Clock.Now())
 	location := today.Location()
 	for _, operation := range operations {
 		key := common.MakeBssVacationHash(
@@ -365,7 +403,7 @@ func (m *Manager) vacationsInfo(
 		})
 	}
 
-	err = m.SynchronizeToFile()
+	err := m.SynchronizeToFile()
 	return vacationData, err
 }
 
@@ -376,7 +414,8 @@ func (m *Manager) addVacationReleases(
 ) []event.ResponseAction {
 	var actions []event.ResponseAction
 
-	todayDate := common.TodayDate()
+	now := m.data.Clock.Now()
+	todayDate := common.DateOf(now)
 
 	for i, vacation := range vacationInfo {
 		// copy to local variable cause we are taking pointers to it and in
@@ -400,8 +439,9 @@ func (m *Manager) addVacationReleases(
 		release := m.data.ParkingLot.ToBeReleased.Add(
 			fmt.Sprintf("bssViewId_%s_%d", vacation.Key, i),
 			"ParkingBot",
-			"ParkingBotId",
+			spaces.BotReleaserId,
 			space,
+			m.data.Clock.Now(),
 		)
 		slog.Info(
 			"processing vacation",
@@ -411,16 +451,29 @@ func (m *Manager) addVacationReleases(
 			vacation,
 		)
 
-		release.StartDate = &vacation.StartDay
-		if release.StartDate.Before(todayDate) {
+		startDate := vacation.StartDay
+		if startDate.Before(todayDate) {
 			// NOTE: we only create requests for the future. so
 			// if a vacation period started 5 days ago and it continues for
 			// 3 more days then here we create the release from today
 			// till the end of the vacation.
-			release.StartDate = &todayDate
+			startDate = todayDate
 		}
+		// Releases only matter on working days so start from the first one
+		startDate = m.data.Calendar.WorkingDayOnOrAfter(startDate)
+		release.StartDate = &startDate
 		release.EndDate = &vacation.EndDay
 
+		if release.EndDate.Before(*release.StartDate) {
+			slog.Info("vacation has no working days", "vacation", vacation)
+			m.vacationsHash[vacation.Key] = true
+			err := m.data.ParkingLot.ToBeReleased.Remove(release)
+			if err != nil {
+				actions = append(actions, m.reportErrorAction(err.Error()))
+			}
+			continue
+		}
+
 		overlaps := m.data.ParkingLot.ToBeReleased.CheckOverlap(release)
 		if len(overlaps) > 0 {
 			slog.Info("vacation overlaps", "overlaps", overlaps, "vacation", vacation)
@@ -432,12 +485,18 @@ func (m *Manager) addVacationReleases(
 		}
 
 		m.vacationsHash[vacation.Key] = true
-		release.MarkSubmitted("BSS")
+		release.MarkSubmitted("BSS", m.data.Clock.Now())
 
-		if common.EqualDate(*release.StartDate, todayDate) {
-			// Directly release space if release start from today
-			space.Reserved = false
-			release.MarkActive()
+		bookingDate := m.data.Calendar.BookingDate(
+			now,
+			m.data.ParkingLot.SpaceResetTime(space.Key()),
+		)
+		if !release.StartDate.After(bookingDate) {
+			// Directly release space if release starts on the day for which
+			// reservations are currently made (today or next working day
+			// if reset already happened)
+			m.data.ParkingLot.FreeSpace(space)
+			release.MarkActive(now)
 		}
 		m.data.ParkingLot.ToBeReleased.Update(release)
 
@@ -469,6 +528,12 @@ func (m *Manager) addVacationReleases(
 
 	m.data.ParkingLot.SynchronizeToFile()
 
+	// NOTE: spaces of releases that start immediately are free now
+	actions = append(
+		actions,
+		parking_spaces.FreeSpaceNotifications(m.data, m.notificationThrottle)...,
+	)
+
 	return actions
 }
 
@@ -0,0 +1,200 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat      = "2006-01-02"
+	yearlyFormat    = "01-02"
+	maxDaysToSearch = 366
+)
+
+// Holiday Public holiday. Date is either a full date (2025-04-21) for
+// holidays that move every year (i.e. Easter Monday) or month-day (12-24)
+// for holidays which are on the same date every year.
+type Holiday struct {
+	Date string
+	Name string
+}
+
+type calendarFile struct {
+	// Weekend Names of non-working week days (i.e. Saturday). If empty
+	// Saturday & Sunday are used.
+	Weekend  []string
+	Holidays []Holiday
+}
+
+// Calendar Working day calendar. A day is a working day if it is not a
+// weekend day and not a public holiday.
+type Calendar struct {
+	weekend map[time.Weekday]bool
+	// holidays date (2006-01-02) -> holiday name
+	holidays map[string]string
+	// yearly month-day (01-02) -> holiday name
+	yearly map[string]string
+}
+
+// NewCalendar Creates calendar with Saturday & Sunday as weekend and no
+// public holidays
+func NewCalendar() *Calendar {
+	return &Calendar{
+		weekend: map[time.Weekday]bool{
+			time.Saturday: true,
+			time.Sunday:   true,
+		},
+		holidays: map[string]string{},
+		yearly:   map[string]string{},
+	}
+}
+
+func NewCalendarFromJson(data []byte) (*Calendar, error) {
+	var file calendarFile
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := NewCalendar()
+
+	if len(file.Weekend) > 0 {
+		calendar.weekend = map[time.Weekday]bool{}
+		for _, dayName := range file.Weekend {
+			day, err := parseWeekday(dayName)
+			if err != nil {
+				return nil, err
+			}
+			calendar.weekend[day] = true
+		}
+	}
+
+	for _, holiday := range file.Holidays {
+		if _, err := time.Parse(dateFormat, holiday.Date); err == nil {
+			calendar.holidays[holiday.Date] = holiday.Name
+			continue
+		}
+
+		// NOTE: year 2000 is a leap year so 02-29 is also accepted
+		if _, err := time.Parse(dateFormat, "2000-"+holiday.Date); err == nil {
+			calendar.yearly[holiday.Date] = holiday.Name
+			continue
+		}
+
+		return nil, fmt.Errorf(
+			"invalid date %q for holiday %q: expected YYYY-MM-DD or MM-DD",
+			holiday.Date,
+			holiday.Name,
+		)
+	}
+
+	return calendar, nil
+}
+
+// LoadCalendar Loads holidays from file. If filename is empty the default
+// calendar (weekends only) is used.
+func LoadCalendar(filename string) *Calendar {
+	if filename == "" {
+		slog.Info("INIT: No holidays file provided. Only weekends are non-working days")
+		return NewCalendar()
+	}
+
+	calendar, err := ReadCalendar(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	slog.Info(
+		"INIT: Holidays loaded successfully",
+		"file", filename,
+		"holidays", len(calendar.holidays)+len(calendar.yearly),
+	)
+	return calendar
+}
+
+// ReadCalendar Reads & validates holidays file. Empty filename means default
+// calendar.
+func ReadCalendar(filename string) (*Calendar, error) {
+	if filename == "" {
+		return NewCalendar(), nil
+	}
+
+	fileData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read holidays file (%s): %w", filename, err)
+	}
+
+	calendar, err := NewCalendarFromJson(fileData)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse holidays file (%s): %w", filename, err)
+	}
+	return calendar, nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekend day %q", name)
+}
+
+// Holiday Returns the name of the public holiday on the given date (if any)
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	if name, ok := c.holidays[t.Format(dateFormat)]; ok {
+		return name, true
+	}
+	name, ok := c.yearly[t.Format(yearlyFormat)]
+	return name, ok
+}
+
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, isHoliday := c.Holiday(t)
+	return !isHoliday
+}
+
+// WorkingDayOnOrAfter Returns the start (00:00) of the first working day
+// which is on or after the date of t.
+func (c *Calendar) WorkingDayOnOrAfter(t time.Time) time.Time {
+	day := startOfDay(t)
+	// NOTE: limit the search in case the calendar is misconfigured (i.e.
+	// all week days are weekend)
+	for i := 0; i < maxDaysToSearch; i++ {
+		if c.IsWorkingDay(day) {
+			return day
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	slog.Error("No working day found within a year", "from", t)
+	return startOfDay(t)
+}
+
+// NextWorkingDay Returns the start (00:00) of the first working day after
+// the date of t.
+func (c *Calendar) NextWorkingDay(t time.Time) time.Time {
+	return c.WorkingDayOnOrAfter(startOfDay(t).AddDate(0, 0, 1))
+}
+
+// BookingDate Returns the date (00:00) for which a reservation made at `now`
+// is valid. Before the daily reset on a working day this is today. After the
+// reset or on a non-working day this is the next working day.
+func (c *Calendar) BookingDate(now time.Time, reset TimeOfDay) time.Time {
+	if c.IsWorkingDay(now) && now.Before(reset.On(now)) {
+		return startOfDay(now)
+	}
+	return c.NextWorkingDay(now)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,60 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+// 2026-10-12 is a Monday
+func day(d, hour, min int) time.Time {
+	return time.Date(2026, 10, d, hour, min, 0, 0, time.UTC)
+}
+
+func TestBookingDate(t *testing.T) {
+	calendar, err := NewCalendarFromJson([]byte(`{
+		"Holidays": [
+			{"Date": "10-14", "Name": "Yearly holiday"},
+			{"Date": "2026-10-19", "Name": "One-off holiday"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"working day before reset", day(12, 16, 59), day(12, 0, 0)},
+		{"working day at reset", day(12, 17, 0), day(13, 0, 0)},
+		{"working day after reset", day(12, 17, 1), day(13, 0, 0)},
+		{"working day after reset next day holiday", day(13, 18, 0), day(15, 0, 0)},
+		{"yearly holiday", day(14, 9, 0), day(15, 0, 0)},
+		{"friday before reset", day(16, 16, 59), day(16, 0, 0)},
+		{"friday after reset", day(16, 17, 0), day(20, 0, 0)},
+		{"saturday", day(17, 9, 0), day(20, 0, 0)},
+		{"sunday after reset", day(18, 20, 0), day(20, 0, 0)},
+		{"one-off holiday before reset", day(19, 9, 0), day(20, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		got := calendar.BookingDate(tt.now, TimeOfDay{Hour: 17})
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: BookingDate(%v) = %v; want %v", tt.name, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestNewCalendarFromJsonInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"Weekend": ["Caturday"]}`,
+		`{"Holidays": [{"Date": "2026-13-01"}]}`,
+		`{"Holidays": [{"Date": "02-30"}]}`,
+		`not json`,
+	} {
+		if _, err := NewCalendarFromJson([]byte(data)); err == nil {
+			t.Errorf("NewCalendarFromJson(%s) expected error", data)
+		}
+	}
+}
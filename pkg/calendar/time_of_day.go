@@ -0,0 +1,71 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeOfDay Time within a day (i.e. 17:00). It is stored as "HH:MM" in json
+// files and config.
+type TimeOfDay struct {
+	Hour int
+	Min  int
+}
+
+func NewTimeOfDay(hour, min int) (TimeOfDay, error) {
+	if hour < 0 || hour > 23 || min < 0 || min > 59 {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day %d:%02d", hour, min)
+	}
+	return TimeOfDay{Hour: hour, Min: min}, nil
+}
+
+// ParseTimeOfDay Parses time in "HH:MM" format (i.e. 17:00 or 9:30)
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	var hour, min int
+	_, err := fmt.Sscanf(s, "%d:%d", &hour, &min)
+	if err != nil {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day %q: expected HH:MM", s)
+	}
+	return NewTimeOfDay(hour, min)
+}
+
+// TimeOfDayOf Returns time of day of t (seconds are ignored)
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Min: t.Minute()}
+}
+
+// String Returns time in "HH:MM" format (same as used by slack time pickers)
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Min)
+}
+
+func (t TimeOfDay) Before(other TimeOfDay) bool {
+	return t.Hour < other.Hour || (t.Hour == other.Hour && t.Min < other.Min)
+}
+
+// On Returns the time on the date of `date`
+func (t TimeOfDay) On(date time.Time) time.Time {
+	return time.Date(
+		date.Year(),
+		date.Month(),
+		date.Day(),
+		t.Hour,
+		t.Min,
+		0,
+		0,
+		date.Location(),
+	)
+}
+
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimeOfDay(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
@@ -0,0 +1,74 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TimeSlot Part of a day (i.e. 08:00-12:00) which can be booked separately.
+// It is stored as "HH:MM-HH:MM" in json files and config.
+type TimeSlot struct {
+	Start TimeOfDay
+	End   TimeOfDay
+}
+
+func NewTimeSlot(start, end TimeOfDay) (TimeSlot, error) {
+	if !start.Before(end) {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %s-%s: start is not before end", start, end)
+	}
+	return TimeSlot{Start: start, End: end}, nil
+}
+
+// ParseTimeSlot Parses time slot in "HH:MM-HH:MM" format (i.e. 08:00-12:00)
+func ParseTimeSlot(s string) (TimeSlot, error) {
+	startStr, endStr, found := strings.Cut(s, "-")
+	if !found {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: expected HH:MM-HH:MM", s)
+	}
+
+	start, err := ParseTimeOfDay(strings.TrimSpace(startStr))
+	if err != nil {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: %w", s, err)
+	}
+	end, err := ParseTimeOfDay(strings.TrimSpace(endStr))
+	if err != nil {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: %w", s, err)
+	}
+	return NewTimeSlot(start, end)
+}
+
+// String Returns slot in "HH:MM-HH:MM" format
+func (s TimeSlot) String() string {
+	return fmt.Sprintf("%s-%s", s.Start, s.End)
+}
+
+func (s TimeSlot) Overlaps(other TimeSlot) bool {
+	return s.Start.Before(other.End) && other.Start.Before(s.End)
+}
+
+func (s TimeSlot) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *TimeSlot) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimeSlot(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// ValidateTimeSlots Checks that slots are ordered by time & don't overlap
+func ValidateTimeSlots(slots []TimeSlot) error {
+	for i := 1; i < len(slots); i++ {
+		prev, slot := slots[i-1], slots[i]
+		if slot.Overlaps(prev) {
+			return fmt.Errorf("time slot %s overlaps with %s", slot, prev)
+		}
+		if slot.Start.Before(prev.Start) {
+			return fmt.Errorf("time slot %s is not after %s", slot, prev)
+		}
+	}
+	return nil
+}
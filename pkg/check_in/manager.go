@@ -0,0 +1,316 @@
+package check_in
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+const (
+	Identifier = "Check-in: "
+
+	RequestParkingCheckIns    = "Request parking check-ins"
+	ReleaseParkingNoShows     = "Release parking no-shows"
+	RequestWorkspacesCheckIns = "Request workspaces check-ins"
+	ReleaseWorkspacesNoShows  = "Release workspaces no-shows"
+
+	checkInActionId = "checkIn"
+
+	parkingKind    = "parking"
+	workspacesKind = "workspaces"
+)
+
+// Manager Requests check-in for reservations that are released
+// automatically & releases reservations of users that did not check in
+// (no-shows) so that others can book the spaces
+type Manager struct {
+	eventManager         *event.EventManager
+	data                 *model.Data
+	notificationThrottle time.Duration
+}
+
+func NewManager(
+	eventManager *event.EventManager,
+	data *model.Data,
+	conf *config.Config,
+) *Manager {
+	return &Manager{
+		eventManager:         eventManager,
+		data:                 data,
+		notificationThrottle: conf.NotificationThrottle,
+	}
+}
+
+func (m *Manager) Consume(e event.Event) {
+	switch e.Type() {
+	case event.TimerEvent:
+		data := e.(*event.TimerDone)
+
+		var kind string
+		var request bool
+		switch data.Label {
+		case RequestParkingCheckIns:
+			kind, request = parkingKind, true
+		case ReleaseParkingNoShows:
+			kind = parkingKind
+		case RequestWorkspacesCheckIns:
+			kind, request = workspacesKind, true
+		case ReleaseWorkspacesNoShows:
+			kind = workspacesKind
+		default:
+			return
+		}
+
+		// NOTE: spaces are not booked for non-working days. Missed runs are
+		// not caught up as users could not check in while the bot was down.
+		if !m.data.Calendar.IsWorkingDay(data.Scheduled) || data.CatchUp {
+			slog.Info("Skip check-in timer", "label", data.Label, "scheduled", data.Scheduled)
+			return
+		}
+
+		m.data.Lock()
+		defer m.data.Unlock()
+
+		var response *common.Response
+		if request {
+			response = m.handleRequestCheckIns(kind)
+		} else {
+			response = m.handleReleaseNoShows(kind, data.Time)
+		}
+		if response != nil {
+			m.eventManager.Publish(response)
+		}
+
+	case event.AppHomeOpenedEvent:
+		data := e.(*slackApi.AppHomeOpened)
+
+		m.data.Lock()
+		defer m.data.Unlock()
+
+		m.eventManager.Publish(common.NewResponseEvent(
+			data.UserName,
+			m.homeAction(data.UserId, ""),
+		))
+
+	case event.BlockActionEvent:
+		data := e.(*slackApi.BlockAction)
+
+		m.data.Lock()
+		defer m.data.Unlock()
+
+		response := m.handleBlockActions(data)
+		if response != nil {
+			m.eventManager.Publish(response)
+		}
+	}
+}
+
+func (m *Manager) Context() string {
+	return Identifier
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) lot(kind string) *spaces.SpacesLot {
+	if kind == parkingKind {
+		return m.data.ParkingLot
+	}
+	return m.data.WorkspacesLot
+}
+
+func (m *Manager) handleRequestCheckIns(kind string) *common.Response {
+	lot := m.lot(kind)
+	window := lot.CheckInWindow()
+	if window == nil {
+		return nil
+	}
+
+	var actions []event.ResponseAction
+	for _, checkIn := range lot.RequireCheckIns() {
+		txt := fmt.Sprintf(
+			":wave: Please check in to your reserved %s *%s*%s by *%s* "+
+				"(here or in the Home tab of the app). "+
+				"Otherwise the reservation is released so that others can use it.",
+			spaceName(kind),
+			checkIn.SpaceKey,
+			slotsStr(checkIn.Slots),
+			window.End,
+		)
+		blocks := []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", txt, false, false), nil, nil),
+			checkInBlock(kind, checkIn.SpaceKey),
+		}
+		actions = append(actions, common.NewPostBlocksAction(checkIn.ReservedById, txt, blocks...))
+	}
+	slog.Info("RequestCheckIns", "kind", kind, "reservations", len(actions))
+	if len(actions) == 0 {
+		return nil
+	}
+
+	return common.NewResponseEvent("CheckIn Timer", actions...)
+}
+
+func (m *Manager) handleReleaseNoShows(kind string, now time.Time) *common.Response {
+	lot := m.lot(kind)
+	window := lot.CheckInWindow()
+	noShows := lot.ReleaseNoShows()
+	slog.Info("ReleaseNoShows", "kind", kind, "noShows", len(noShows))
+	if len(noShows) == 0 {
+		return nil
+	}
+
+	var actions []event.ResponseAction
+	for _, noShow := range noShows {
+		m.data.UserManager.AddNoShow(noShow.ReservedById, noShow.ReservedBy, user.NoShow{
+			Date:  now,
+			Space: string(noShow.SpaceKey),
+		})
+
+		deadline := ""
+		if window != nil {
+			deadline = fmt.Sprintf(" by *%s*", window.End)
+		}
+		txt := fmt.Sprintf(
+			":warning: Your reservation of %s *%s*%s was released because you "+
+				"did not check in%s. Repeated no-shows can limit your booking rights.",
+			spaceName(kind),
+			noShow.SpaceKey,
+			slotsStr(noShow.Slots),
+			deadline,
+		)
+		actions = append(actions, common.NewPostAction(noShow.ReservedById, txt, false))
+	}
+	m.data.UserManager.SynchronizeToFile()
+
+	if kind == parkingKind {
+		actions = append(
+			actions,
+			parking_spaces.FreeSpaceNotifications(m.data, m.notificationThrottle)...,
+		)
+	}
+	return common.NewResponseEvent("CheckIn Timer", actions...)
+}
+
+func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Response {
+	var actions []event.ResponseAction
+	for _, action := range data.Actions {
+		if action.ActionID != checkInActionId {
+			continue
+		}
+
+		kind, _, _ := strings.Cut(strings.TrimPrefix(action.BlockID, Identifier), "/")
+		spaceKey := views.ActionValues{}.Decode(action.Value).SpaceKey
+
+		txt := fmt.Sprintf(":white_check_mark: You checked in to %s *%s*.", spaceName(kind), spaceKey)
+		err := m.lot(kind).CheckIn(data.UserId, spaceKey)
+		if err != nil {
+			slog.Error("Failed to check in", "user", data.UserName, "space", spaceKey, "err", err)
+			txt = fmt.Sprintf(":warning: Check-in failed: %v", err)
+		}
+
+		if data.ViewType == slack.VTHomeTab {
+			actions = append(actions, m.homeAction(data.UserId, txt))
+		} else {
+			actions = append(actions, common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, txt))
+		}
+	}
+
+	if len(actions) == 0 {
+		return nil
+	}
+	return common.NewResponseEvent(data.UserName, actions...)
+}
+
+// homeAction Home tab of the app with all reservations of the user waiting
+// for check-in. status is the result of the last check-in (if any).
+func (m *Manager) homeAction(userId, status string) *common.PublishHomeAction {
+	lines := []string{"*Check-in*"}
+	if status != "" {
+		lines = append(lines, status)
+	}
+	blocks := common.TextBlocks(lines)
+
+	waiting := false
+	for _, kind := range []string{parkingKind, workspacesKind} {
+		lot := m.lot(kind)
+		window := lot.CheckInWindow()
+		if window == nil {
+			continue
+		}
+
+		for _, checkIn := range lot.UserCheckIns(userId) {
+			waiting = true
+			txt := fmt.Sprintf(
+				"Your reserved %s *%s*%s is waiting for check-in by *%s*.",
+				spaceName(kind),
+				checkIn.SpaceKey,
+				slotsStr(checkIn.Slots),
+				window.End,
+			)
+			blocks = append(
+				blocks,
+				slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", txt, false, false), nil, nil),
+				checkInBlock(kind, checkIn.SpaceKey),
+			)
+		}
+	}
+
+	if !waiting {
+		blocks = append(blocks, common.TextBlocks([]string{
+			"_You have no reservations waiting for check-in._",
+		})...)
+	}
+	return common.NewPublishHomeAction(userId, blocks...)
+}
+
+// checkInBlock Check-in button of the reservation.
+// NOTE: messages & the app home are routed by block ID so it starts with the
+// identifier. The rest of the block ID is the kind & the space (block IDs
+// have to be unique within the app home).
+func checkInBlock(kind string, spaceKey spaces.SpaceKey) *slack.ActionBlock {
+	checkInBtn := slack.NewButtonBlockElement(
+		checkInActionId,
+		views.ActionValues{SpaceKey: spaceKey}.Encode(),
+		slack.NewTextBlockObject("plain_text", "Check in", true, false),
+	).WithStyle(slack.StylePrimary)
+	return slack.NewActionBlock(fmt.Sprintf("%s%s/%s", Identifier, kind, spaceKey), checkInBtn)
+}
+
+func spaceName(kind string) string {
+	if kind == parkingKind {
+		return "parking space"
+	}
+	return "workspace"
+}
+
+// slotsStr Reserved slots for messages. Empty for whole day reservations.
+func slotsStr(slots []calendar.TimeSlot) string {
+	if len(slots) == 0 {
+		return ""
+	}
+	var slotStrs []string
+	for _, slot := range slots {
+		slotStrs = append(slotStrs, slot.String())
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(slotStrs, ", "))
+}
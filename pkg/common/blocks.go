@@ -1,6 +1,14 @@
 package common
 
-import "github.com/slack-go/slack"
+import (
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// maxSectionLen Slack rejects section blocks with text longer than 3000
+// characters
+const maxSectionLen = 3000
 
 // NewInputBlock this is the same as slack.NewInputBlock but add the optional arg
 func NewInputBlock(
@@ -18,3 +26,33 @@ func NewInputBlock(
 		Optional: optional,
 	}
 }
+
+// TextBlocks Joins lines into as few section blocks as possible
+func TextBlocks(lines []string) []slack.Block {
+	var allBlocks []slack.Block
+	var section []string
+	sectionLen := 0
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if len(section) > 0 && sectionLen+len(line)+1 > maxSectionLen {
+			allBlocks = append(allBlocks, newTextBlock(strings.Join(section, "\n")))
+			section, sectionLen = nil, 0
+		}
+		section = append(section, line)
+		sectionLen += len(line) + 1
+	}
+	if len(section) > 0 {
+		allBlocks = append(allBlocks, newTextBlock(strings.Join(section, "\n")))
+	}
+	return allBlocks
+}
+
+func newTextBlock(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn", text, false, false),
+		nil,
+		nil,
+	)
+}
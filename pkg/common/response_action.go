@@ -93,6 +93,20 @@ func NewPostAction(channelId, txt string, escape bool) *PostAction {
 	}
 }
 
+// NewPostBlocksAction Posts message with blocks (i.e. buttons). txt is shown
+// in notifications.
+func NewPostBlocksAction(channelId, txt string, blocks ...slack.Block) *PostAction {
+	return &PostAction{
+		action:    event.Post,
+		ChannelId: channelId,
+		MsgOption: slack.MsgOptionCompose(
+			slack.MsgOptionText(txt, false),
+			slack.MsgOptionBlocks(blocks...),
+		),
+		Txt: txt,
+	}
+}
+
 type PostEphemeralAction struct {
 	PostAction
 	UserId string
@@ -112,3 +126,103 @@ func NewPostEphemeralAction(
 		UserId: userId,
 	}
 }
+
+// UpdateMessageAction Replaces text (and blocks) of a posted message
+type UpdateMessageAction struct {
+	ChannelId string
+	Timestamp string
+	MsgOption slack.MsgOption
+	Txt       string
+}
+
+// NewUpdateMessageAction Replaces the message with txt. Blocks of the message
+// (i.e. buttons) are removed.
+func NewUpdateMessageAction(channelId, timestamp, txt string) *UpdateMessageAction {
+	textBlock := slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, txt, false, false),
+		nil,
+		nil,
+	)
+	return &UpdateMessageAction{
+		ChannelId: channelId,
+		Timestamp: timestamp,
+		MsgOption: slack.MsgOptionCompose(
+			slack.MsgOptionText(txt, false),
+			slack.MsgOptionBlocks(textBlock),
+		),
+		Txt: txt,
+	}
+}
+
+func (u *UpdateMessageAction) Action() event.ResponseActionType {
+	return event.UpdateMessage
+}
+
+func (u *UpdateMessageAction) Info() map[string]any {
+	return map[string]any{
+		"txt":       u.Txt,
+		"channelId": u.ChannelId,
+		"timestamp": u.Timestamp,
+	}
+}
+
+// PublishHomeAction Replaces the home tab of the app as seen by the user
+type PublishHomeAction struct {
+	UserId string
+	View   slack.HomeTabViewRequest
+}
+
+func NewPublishHomeAction(userId string, blocks ...slack.Block) *PublishHomeAction {
+	return &PublishHomeAction{
+		UserId: userId,
+		View: slack.HomeTabViewRequest{
+			Type:   slack.VTHomeTab,
+			Blocks: slack.Blocks{BlockSet: blocks},
+		},
+	}
+}
+
+func (p *PublishHomeAction) Action() event.ResponseActionType {
+	return event.PublishHome
+}
+
+func (p *PublishHomeAction) Info() map[string]any {
+	return map[string]any{
+		"userId": p.UserId,
+		"blocks": len(p.View.Blocks.BlockSet),
+	}
+}
+
+// UploadFileAction Uploads file to a channel. If the channel is a user ID the
+// file is sent as a direct message.
+type UploadFileAction struct {
+	ChannelId string
+	Filename  string
+	Content   []byte
+	Comment   string
+}
+
+func NewUploadFileAction(
+	channelId, filename string,
+	content []byte,
+	comment string,
+) *UploadFileAction {
+	return &UploadFileAction{
+		ChannelId: channelId,
+		Filename:  filename,
+		Content:   content,
+		Comment:   comment,
+	}
+}
+
+func (u *UploadFileAction) Action() event.ResponseActionType {
+	return event.UploadFile
+}
+
+func (u *UploadFileAction) Info() map[string]any {
+	return map[string]any{
+		"filename":  u.Filename,
+		"size":      len(u.Content),
+		"channelId": u.ChannelId,
+	}
+}
@@ -5,18 +5,8 @@ import (
 	"time"
 )
 
-func CheckDateRange(start, end time.Time) string {
-	today := time.Now()
-	todayDate := time.Date(
-		today.Year(),
-		today.Month(),
-		today.Day(),
-		0,
-		0,
-		0,
-		0,
-		today.Location(),
-	)
+func CheckDateRange(start, end, now time.Time) string {
+	todayDate := DateOf(now)
 
 	if start.Before(todayDate) {
 		return fmt.Sprintf("Start date is in the past: %s", start.Format("2006-01-02"))
@@ -33,20 +23,18 @@ func CheckDateRange(start, end time.Time) string {
 	return ""
 }
 
-func TodayDate() time.Time {
-	today := time.Now()
-	todayDate := time.Date(
-		today.Year(),
-		today.Month(),
-		today.Day(),
+// DateOf Returns the start (00:00) of the day of t
+func DateOf(t time.Time) time.Time {
+	return time.Date(
+		t.Year(),
+		t.Month(),
+		t.Day(),
 		0,
 		0,
 		0,
 		0,
-		today.Location(),
+		t.Location(),
 	)
-
-	return todayDate
 }
 
 func EqualDate(date1, date2 time.Time) bool {
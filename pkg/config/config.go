@@ -1,15 +1,37 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"log/slog"
-	"os"
-	"strconv"
+	"time"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	"github.com/joho/godotenv"
 )
 
+const (
+	DefaultConfigFilename         = "config.yaml"
+	DefaultSchedulerStateFilename = "scheduler_state.json"
+
+	DefaultResetTime = "17:00"
+	// NOTE: HCM & BSS checks are triggered multiple times per day to account
+	// for people booking sick leaves or remote work early in the morning or
+	// late in the evening.
+	DefaultHcmSyncTimes = "6:00,8:00,9:30,16:45"
+	DefaultBssSyncTimes = "6:05,8:05,9:35,16:50"
+
+	DefaultGuestBookingsPerWeek = 3
+
+	DefaultNotificationThrottle = time.Hour
+)
+
+// DefaultPlateLookupRoles Roles that can look up car owners by plate if
+// plate_lookup_roles is not set
+var DefaultPlateLookupRoles = []user.Role{user.RoleSecurity}
+
 type BssCompanyConfig struct {
 	Username      string
 	Password      string
@@ -17,34 +39,6 @@ type BssCompanyConfig struct {
 	CompanyId     int
 }
 
-func NewBssCompanyConfig(
-	username, password, envIdStr, companyIdStr string,
-) BssCompanyConfig {
-	envId, err := strconv.Atoi(envIdStr)
-	if err != nil {
-		log.Fatalf(
-			"Failed to convert BSS ENVIRONMENT_ID to int: %q; %v",
-			envIdStr,
-			err,
-		)
-	}
-
-	companyId, err := strconv.Atoi(companyIdStr)
-	if err != nil {
-		log.Fatalf(
-			"Failed to convert BSS COMPANY_ID to int: %q; %v",
-			companyIdStr,
-			err,
-		)
-	}
-	return BssCompanyConfig{
-		Username:      username,
-		Password:      password,
-		EnvironmentId: envId,
-		CompanyId:     companyId,
-	}
-}
-
 type BssConfig struct {
 	Url                   string
 	VacationsHashFilename string
@@ -52,22 +46,117 @@ type BssConfig struct {
 	Quad                  BssCompanyConfig
 }
 
-func NewBssConfig(
-	url, vacationsHashFilename string,
-	qdev, quad BssCompanyConfig,
-) BssConfig {
-	return BssConfig{
-		Url:                   url,
-		VacationsHashFilename: vacationsHashFilename,
-		Qdev:                  qdev,
-		Quad:                  quad,
+// SchedulesConfig Times of all timer events. Parking & workspaces reset
+// times are the default ones - admins can change them (also per floor) at
+// runtime & their changes take precedence (they are stored together with
+// spaces data). HCM & BSS syncs,
+// the digest & release reminders can also be cron expressions.
+type SchedulesConfig struct {
+	ParkingReset    calendar.TimeOfDay
+	WorkspacesReset calendar.TimeOfDay
+	HcmSync         []*event.CronSchedule
+	BssSync         []*event.CronSchedule
+	// ParkingRestrictedOpen & WorkspacesRestrictedOpen Time after which
+	// restricted spaces that are still free can be reserved by everyone.
+	// nil means restricted spaces are never opened.
+	ParkingRestrictedOpen    *calendar.TimeOfDay
+	WorkspacesRestrictedOpen *calendar.TimeOfDay
+	// Digest Time when availability for the next working day is posted to
+	// the digest channel. nil means the digest is not posted.
+	Digest *event.CronSchedule
+	// ReleaseReminder Time when owners are reminded about temporary releases
+	// that start or end on the next working day. nil means no reminders.
+	ReleaseReminder *event.CronSchedule
+	// ParkingCheckIn & WorkspacesCheckIn Part of the day in which users have
+	// to check in to their reservations. Check-in is requested at the start
+	// & reservations without check-in are released at the end. nil means
+	// check-in is not required.
+	ParkingCheckIn    *calendar.TimeSlot
+	WorkspacesCheckIn *calendar.TimeSlot
+}
+
+// ValidateParkingReset Checks that all HCM & BSS syncs happen before the
+// given parking reset time. Otherwise vacations that start on the next day
+// are only processed after the spaces were already reset. Release reminders
+// have to be sent before the reset so that owners can still cancel releases
+// that start on the next day. The check-in window has to end before the reset
+// so that reservations of the next day are not released. The digest has to be
+// posted after the reset so that it shows the next working day.
+func (s SchedulesConfig) ValidateParkingReset(resetTime calendar.TimeOfDay) error {
+	var errs []error
+	for _, sync := range []struct {
+		name      string
+		schedules []*event.CronSchedule
+	}{
+		{"HCM sync", s.HcmSync},
+		{"BSS sync", s.BssSync},
+		{"release reminder", optionalSchedules(s.ReleaseReminder)},
+	} {
+		for _, schedule := range sync.schedules {
+			for _, syncTime := range schedule.TimesOfDay() {
+				if !syncTime.Before(resetTime) {
+					errs = append(errs, fmt.Errorf(
+						"%s at %s is not before parking reset at %s",
+						sync.name,
+						syncTime,
+						resetTime,
+					))
+				}
+			}
+		}
+	}
+	errs = append(errs, validateCheckIn("parking", s.ParkingCheckIn, resetTime))
+	errs = append(errs, s.validateDigest("parking", resetTime))
+	return errors.Join(errs...)
+}
+
+// ValidateWorkspacesReset Checks that the check-in window ends before & the
+// digest is posted after the given workspaces reset time
+func (s SchedulesConfig) ValidateWorkspacesReset(resetTime calendar.TimeOfDay) error {
+	return errors.Join(
+		validateCheckIn("workspaces", s.WorkspacesCheckIn, resetTime),
+		s.validateDigest("workspaces", resetTime),
+	)
+}
+
+func validateCheckIn(name string, window *calendar.TimeSlot, resetTime calendar.TimeOfDay) error {
+	if window == nil || window.End.Before(resetTime) {
+		return nil
 	}
+	return fmt.Errorf("%s check-in %s does not end before %s reset at %s", name, window, name, resetTime)
+}
+
+func (s SchedulesConfig) validateDigest(name string, resetTime calendar.TimeOfDay) error {
+	var errs []error
+	for _, schedule := range optionalSchedules(s.Digest) {
+		for _, digestTime := range schedule.TimesOfDay() {
+			if !resetTime.Before(digestTime) {
+				errs = append(errs, fmt.Errorf(
+					"digest at %s is not after %s reset at %s",
+					digestTime,
+					name,
+					resetTime,
+				))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// optionalSchedules Returns no schedules if schedule is not set
+func optionalSchedules(schedule *event.CronSchedule) []*event.CronSchedule {
+	if schedule == nil {
+		return nil
+	}
+	return []*event.CronSchedule{schedule}
 }
 
 type Config struct {
 	SlackAuthToken   string
 	SlackTaChannelId string
 	SlackAppToken    string
+	// SlackDigestChannelId Channel where the daily digest is posted
+	SlackDigestChannelId string
 
 	DevicesFilename    string
 	UsersFilename      string
@@ -89,67 +178,86 @@ type Config struct {
 	HcmVacationsHashFilename string
 
 	Bss BssConfig
-}
 
-// NewConfigFromEnv Creates config instance by reading corresponding ENV variables.
-// Make sure godotenv.Load is called beforehand
-func NewConfigFromEnv(envPath string) *Config {
-	// Env variables are used to configure slack client, devices, spaces & users data
-	godotenv.Load(envPath)
+	// Location Timezone in which all schedules are evaluated
+	Location               *time.Location
+	SchedulerStateFilename string
 
-	taEndpoint := os.Getenv("SL_TA_ENDPOINT")
+	// HolidaysFilename Public holidays used to determine working days. If
+	// empty only weekends are non-working days.
+	HolidaysFilename string
 
-	bssQuad := NewBssCompanyConfig(
-		os.Getenv("BSS_QUAD_USERNAME"),
-		os.Getenv("BSS_QUAD_PASSWORD"),
-		os.Getenv("BSS_QUAD_ENVIRONMENT_ID"),
-		os.Getenv("BSS_QUAD_COMPANY_ID"),
-	)
+	Schedules SchedulesConfig
 
-	bssQdev := NewBssCompanyConfig(
-		os.Getenv("BSS_QDEV_USERNAME"),
-		os.Getenv("BSS_QDEV_PASSWORD"),
-		os.Getenv("BSS_QDEV_ENVIRONMENT_ID"),
-		os.Getenv("BSS_QDEV_COMPANY_ID"),
-	)
+	// Channels Initial channels where workspaces can be booked. A channel is
+	// added to workspaces data the first time /workspace is used in it,
+	// afterwards it is managed by admins through the edit workspaces modal.
+	Channels []ChannelConfig
+	// Admins Slack user IDs that always have admin rights (regardless of
+	// rights stored in users file)
+	Admins []string
+	// GuestBookingsPerWeek Max number of parking guest bookings a host can
+	// make for days of the same week
+	GuestBookingsPerWeek int
+	// PlateLookupRoles Roles (besides admins) that can look up car owners by
+	// plate
+	PlateLookupRoles []user.Role
+	// NotificationThrottle Min time between two free space notifications
+	// sent to the same user
+	NotificationThrottle time.Duration
+	// WorkspaceSlots Parts of the day for which workspaces can be booked
+	// separately (ordered & not overlapping). If empty workspaces are only
+	// booked for whole days.
+	WorkspaceSlots []calendar.TimeSlot
+	// Quota Default limits of reservations of users without a permanent
+	// space (admins can override them per user)
+	Quota user.Quota
+	// FloorMapListen Address of the HTTP server which serves rendered floor
+	// maps (i.e. :8081). Empty means the server is not started.
+	FloorMapListen string
+	// FloorMapUrl URL through which slack reaches the floor map server.
+	// Empty means floor maps are not shown.
+	FloorMapUrl string
+	// FloorMapSecret Key with which floor map URLs are signed
+	FloorMapSecret string
+}
 
-	bssConfig := NewBssConfig(
-		os.Getenv("BSS_URL"),
-		os.Getenv("BSS_HASH_FILE"),
-		bssQdev,
-		bssQuad,
-	)
+// ChannelConfig Slack channel (i.e. qdev_technologies) & the workspace floors
+// that can be booked from it
+type ChannelConfig struct {
+	Name   string `yaml:"name"`
+	Floors []int  `yaml:"floors"`
+}
 
-	testingActive := os.Getenv("TESTING") == "1"
-	if testingActive {
-		slog.Info("Testing is ACTIVE! Use slash commands starting with test-")
+// NewLocation Loads timezone by name (i.e. Europe/Vilnius). Empty name means
+// local timezone of the machine.
+func NewLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
 	}
+	return time.LoadLocation(name)
+}
 
-	return &Config{
-		SlackAuthToken:   os.Getenv("SLACK_AUTH_TOKEN"),
-		SlackTaChannelId: os.Getenv("SLACK_TA_CHANNEL_ID"),
-		SlackAppToken:    os.Getenv("SLACK_APP_TOKEN"),
-
-		DevicesFilename: os.Getenv("SL_DEVICES_FILE"),
-		UsersFilename:   os.Getenv("SL_USERS_FILE"),
-
-		ParkingFilename:    os.Getenv("SL_PARKING_FILE"),
-		WorkspacesFilename: os.Getenv("SL_WORKSPACES_FILE"),
-
-		Debug:           os.Getenv("SL_DEBUG") == "1",
-		TaEndpoint:      taEndpoint,
-		WorkersEndpoint: fmt.Sprintf("%s/workers", taEndpoint),
-		ProxyEndpoint:   fmt.Sprintf("%s/proxy", taEndpoint),
-
-		ReportPersonId: os.Getenv("REPORT_PERSON_ID"),
+// NewConfig Creates config instance from yaml config file. Secrets can be
+// provided through ENV variables (or .env file at envPath) which take
+// precedence over the values in the config file. All problems found in the
+// config are reported in a single error.
+func NewConfig(filename, envPath string) (*Config, error) {
+	godotenv.Load(envPath)
 
-		TestingActive: testingActive,
+	file, err := readConfigFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	file.applyEnvOverrides()
 
-		HcmQdevUrl:               os.Getenv("HCM_QDEV_URL"),
-		HcmQuadUrl:               os.Getenv("HCM_QUAD_URL"),
-		HcmApiToken:              os.Getenv("HCM_API_TOKEN"),
-		HcmVacationsHashFilename: os.Getenv("HCM_HASH_FILE"),
+	config, err := file.toConfig()
+	if err != nil {
+		return nil, err
+	}
 
-		Bss: bssConfig,
+	if config.TestingActive {
+		slog.Info("Testing is ACTIVE! Use slash commands starting with test-")
 	}
+	return config, nil
 }
@@ -0,0 +1,557 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"gopkg.in/yaml.v3"
+)
+
+// Company IDs as used in users file (see user.Company)
+var knownCompanies = []string{"Qdev", "Quad"}
+
+type slackFile struct {
+	// NOTE: tokens should be provided through env variables
+	// (SLACK_AUTH_TOKEN, SLACK_APP_TOKEN)
+	AuthToken      string `yaml:"auth_token"`
+	AppToken       string `yaml:"app_token"`
+	TaChannelId    string `yaml:"ta_channel_id"`
+	ReportPersonId string `yaml:"report_person_id"`
+	// DigestChannelId Channel where the daily digest is posted (see
+	// schedules.digest)
+	DigestChannelId string `yaml:"digest_channel_id"`
+}
+
+type storageFile struct {
+	Users          string `yaml:"users"`
+	Parking        string `yaml:"parking"`
+	Workspaces     string `yaml:"workspaces"`
+	Devices        string `yaml:"devices"`
+	Holidays       string `yaml:"holidays"`
+	SchedulerState string `yaml:"scheduler_state"`
+	HcmHash        string `yaml:"hcm_hash"`
+	BssHash        string `yaml:"bss_hash"`
+}
+
+// schedulesFile NOTE: times are kept as strings so that all invalid times are
+// reported and not only the first one
+type schedulesFile struct {
+	ParkingReset    string   `yaml:"parking_reset"`
+	WorkspacesReset string   `yaml:"workspaces_reset"`
+	HcmSync         []string `yaml:"hcm_sync"`
+	BssSync         []string `yaml:"bss_sync"`
+
+	ParkingRestrictedOpen    string `yaml:"parking_restricted_open"`
+	WorkspacesRestrictedOpen string `yaml:"workspaces_restricted_open"`
+	Digest                   string `yaml:"digest"`
+	ReleaseReminder          string `yaml:"release_reminder"`
+	ParkingCheckIn           string `yaml:"parking_check_in"`
+	WorkspacesCheckIn        string `yaml:"workspaces_check_in"`
+}
+
+type companyBssFile struct {
+	// NOTE: credentials should be provided through env variables
+	// (BSS_<COMPANY>_USERNAME, BSS_<COMPANY>_PASSWORD)
+	Username      string `yaml:"username"`
+	Password      string `yaml:"password"`
+	EnvironmentId int    `yaml:"environment_id"`
+	CompanyId     int    `yaml:"company_id"`
+}
+
+type companyFile struct {
+	HcmUrl string         `yaml:"hcm_url"`
+	Bss    companyBssFile `yaml:"bss"`
+}
+
+// quotasFile 0 means unlimited
+type quotasFile struct {
+	PerWeek         int `yaml:"per_week"`
+	PerMonth        int `yaml:"per_month"`
+	ConsecutiveDays int `yaml:"consecutive_days"`
+	HorizonDays     int `yaml:"horizon_days"`
+}
+
+// floorMapFile Both values have to be set to show rendered floor maps
+type floorMapFile struct {
+	Listen    string `yaml:"listen"`
+	PublicUrl string `yaml:"public_url"`
+	// NOTE: secret should be provided through FLOOR_MAP_SECRET env variable
+	Secret string `yaml:"secret"`
+}
+
+type integrationsFile struct {
+	TaEndpoint string `yaml:"ta_endpoint"`
+	Hcm        struct {
+		// NOTE: token should be provided through HCM_API_TOKEN env variable
+		ApiToken string `yaml:"api_token"`
+	} `yaml:"hcm"`
+	Bss struct {
+		Url string `yaml:"url"`
+	} `yaml:"bss"`
+}
+
+// fileConfig Layout of the yaml config file (see config.example.yaml)
+type fileConfig struct {
+	Slack     slackFile              `yaml:"slack"`
+	Storage   storageFile            `yaml:"storage"`
+	Timezone  string                 `yaml:"timezone"`
+	Schedules schedulesFile          `yaml:"schedules"`
+	Companies map[string]companyFile `yaml:"companies"`
+	Channels  []ChannelConfig        `yaml:"channels"`
+	Admins    []string               `yaml:"admins"`
+	// GuestBookingsPerWeek 0 means default
+	GuestBookingsPerWeek int `yaml:"guest_bookings_per_week"`
+	// PlateLookupRoles nil means default
+	PlateLookupRoles []string `yaml:"plate_lookup_roles"`
+	// NotificationThrottleMinutes 0 means default
+	NotificationThrottleMinutes int `yaml:"notification_throttle_minutes"`
+	// WorkspaceSlots empty means workspaces are only booked for whole days
+	WorkspaceSlots []string         `yaml:"workspace_slots"`
+	Quotas         quotasFile       `yaml:"quotas"`
+	FloorMap       floorMapFile     `yaml:"floor_map"`
+	Integrations   integrationsFile `yaml:"integrations"`
+	Testing        bool             `yaml:"testing"`
+	Debug          bool             `yaml:"debug"`
+}
+
+func readConfigFile(filename string) (*fileConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// NOTE: report typos in config keys instead of silently ignoring them
+	decoder.KnownFields(true)
+	err = decoder.Decode(&file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", filename, err)
+	}
+	return &file, nil
+}
+
+// applyEnvOverrides Secrets are not meant to be stored in the config file. If
+// the corresponding env variable is set, it takes precedence over the file.
+func (f *fileConfig) applyEnvOverrides() {
+	override := func(field *string, name string) {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	override(&f.Slack.AuthToken, "SLACK_AUTH_TOKEN")
+	override(&f.Slack.AppToken, "SLACK_APP_TOKEN")
+	override(&f.Integrations.Hcm.ApiToken, "HCM_API_TOKEN")
+	override(&f.FloorMap.Secret, "FLOOR_MAP_SECRET")
+
+	for name, company := range f.Companies {
+		prefix := "BSS_" + strings.ToUpper(name)
+		override(&company.Bss.Username, prefix+"_USERNAME")
+		override(&company.Bss.Password, prefix+"_PASSWORD")
+		f.Companies[name] = company
+	}
+
+	if os.Getenv("TESTING") == "1" {
+		f.Testing = true
+	}
+}
+
+// configErrors Collects all config errors so they can be reported at once
+type configErrors []error
+
+func (e *configErrors) add(format string, args ...any) {
+	*e = append(*e, fmt.Errorf(format, args...))
+}
+
+func (e configErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "found %d config error(s):", len(e))
+	for _, err := range e {
+		fmt.Fprintf(&msg, "\n  - %v", err)
+	}
+	return errors.New(msg.String())
+}
+
+func (e *configErrors) requireFile(name, filename string, required bool) {
+	if filename == "" {
+		if required {
+			e.add("%s: file is not set", name)
+		}
+		return
+	}
+
+	_, err := os.Stat(filename)
+	if err != nil {
+		e.add("%s: %v", name, err)
+	}
+}
+
+func (e *configErrors) timeOfDay(
+	name, value, defaultValue string,
+) calendar.TimeOfDay {
+	if value == "" {
+		value = defaultValue
+	}
+
+	timeOfDay, err := calendar.ParseTimeOfDay(value)
+	if err != nil {
+		e.add("%s: %v", name, err)
+	}
+	return timeOfDay
+}
+
+// optionalTimeOfDay Returns nil if value is not set
+func (e *configErrors) optionalTimeOfDay(name, value string) *calendar.TimeOfDay {
+	if value == "" {
+		return nil
+	}
+
+	timeOfDay := e.timeOfDay(name, value, "")
+	return &timeOfDay
+}
+
+// schedule Parses either "HH:MM" (daily) or a cron expression
+func (e *configErrors) schedule(name, value string) *event.CronSchedule {
+	schedule, err := event.ParseSchedule(value)
+	if err != nil {
+		e.add("%s: %v", name, err)
+	}
+	return schedule
+}
+
+// optionalSchedule Returns nil if value is not set
+func (e *configErrors) optionalSchedule(name, value string) *event.CronSchedule {
+	if value == "" {
+		return nil
+	}
+	return e.schedule(name, value)
+}
+
+func (e *configErrors) schedules(
+	name string,
+	values []string,
+	defaultValues string,
+) []*event.CronSchedule {
+	if len(values) == 0 {
+		values = strings.Split(defaultValues, ",")
+	}
+
+	var schedules []*event.CronSchedule
+	for _, value := range values {
+		schedule := e.schedule(name, value)
+		if schedule != nil {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules
+}
+
+// optionalTimeSlot Returns nil if value is not set
+func (e *configErrors) optionalTimeSlot(name, value string) *calendar.TimeSlot {
+	if value == "" {
+		return nil
+	}
+
+	slot, err := calendar.ParseTimeSlot(value)
+	if err != nil {
+		e.add("%s: %v", name, err)
+	}
+	return &slot
+}
+
+func (e *configErrors) timeSlots(name string, values []string) []calendar.TimeSlot {
+	var slots []calendar.TimeSlot
+	for i, value := range values {
+		slot, err := calendar.ParseTimeSlot(value)
+		if err != nil {
+			e.add("%s[%d]: %v", name, i, err)
+			continue
+		}
+		slots = append(slots, slot)
+	}
+
+	err := calendar.ValidateTimeSlots(slots)
+	if err != nil {
+		e.add("%s: %v", name, err)
+	}
+	return slots
+}
+
+// toConfig Validates the file config & converts it to Config. All found
+// problems are returned as a single error.
+func (f *fileConfig) toConfig() (*Config, error) {
+	var errs configErrors
+
+	if f.Slack.AuthToken == "" {
+		errs.add("slack.auth_token: not set (use SLACK_AUTH_TOKEN env variable)")
+	}
+	if f.Slack.AppToken == "" {
+		errs.add("slack.app_token: not set (use SLACK_APP_TOKEN env variable)")
+	}
+
+	errs.requireFile("storage.users", f.Storage.Users, true)
+	errs.requireFile("storage.parking", f.Storage.Parking, true)
+	errs.requireFile("storage.workspaces", f.Storage.Workspaces, true)
+	errs.requireFile("storage.holidays", f.Storage.Holidays, false)
+
+	schedulerState := f.Storage.SchedulerState
+	if schedulerState == "" {
+		schedulerState = DefaultSchedulerStateFilename
+	}
+
+	location, err := NewLocation(f.Timezone)
+	if err != nil {
+		errs.add("timezone: %v", err)
+	}
+
+	schedules := SchedulesConfig{
+		ParkingReset: errs.timeOfDay(
+			"schedules.parking_reset",
+			f.Schedules.ParkingReset,
+			DefaultResetTime,
+		),
+		WorkspacesReset: errs.timeOfDay(
+			"schedules.workspaces_reset",
+			f.Schedules.WorkspacesReset,
+			DefaultResetTime,
+		),
+		HcmSync: errs.schedules("schedules.hcm_sync", f.Schedules.HcmSync, DefaultHcmSyncTimes),
+		BssSync: errs.schedules("schedules.bss_sync", f.Schedules.BssSync, DefaultBssSyncTimes),
+		ParkingRestrictedOpen: errs.optionalTimeOfDay(
+			"schedules.parking_restricted_open",
+			f.Schedules.ParkingRestrictedOpen,
+		),
+		WorkspacesRestrictedOpen: errs.optionalTimeOfDay(
+			"schedules.workspaces_restricted_open",
+			f.Schedules.WorkspacesRestrictedOpen,
+		),
+		Digest: errs.optionalSchedule("schedules.digest", f.Schedules.Digest),
+		ReleaseReminder: errs.optionalSchedule(
+			"schedules.release_reminder",
+			f.Schedules.ReleaseReminder,
+		),
+		ParkingCheckIn: errs.optionalTimeSlot(
+			"schedules.parking_check_in",
+			f.Schedules.ParkingCheckIn,
+		),
+		WorkspacesCheckIn: errs.optionalTimeSlot(
+			"schedules.workspaces_check_in",
+			f.Schedules.WorkspacesCheckIn,
+		),
+	}
+	err = errors.Join(
+		schedules.ValidateParkingReset(schedules.ParkingReset),
+		schedules.ValidateWorkspacesReset(schedules.WorkspacesReset),
+	)
+	if err != nil {
+		errs.add("schedules: %v", strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	if schedules.Digest != nil && f.Slack.DigestChannelId == "" {
+		errs.add("slack.digest_channel_id: not set (required by schedules.digest)")
+	}
+
+	for name := range f.Companies {
+		if !slices.Contains(knownCompanies, name) {
+			errs.add("companies.%s: unknown company (expected one of %v)", name, knownCompanies)
+		}
+	}
+
+	hcmUsed := false
+	for _, name := range knownCompanies {
+		hcmUsed = hcmUsed || f.Companies[name].HcmUrl != ""
+	}
+	if hcmUsed && f.Integrations.Hcm.ApiToken == "" {
+		errs.add("integrations.hcm.api_token: not set (use HCM_API_TOKEN env variable)")
+	}
+
+	bss := BssConfig{
+		Url:                   f.Integrations.Bss.Url,
+		VacationsHashFilename: f.Storage.BssHash,
+		Qdev:                  errs.bssCompany(f, "Qdev"),
+		Quad:                  errs.bssCompany(f, "Quad"),
+	}
+
+	channelNames := map[string]bool{}
+	for i, channel := range f.Channels {
+		if channel.Name == "" {
+			errs.add("channels[%d]: name is not set", i)
+		}
+		if channelNames[channel.Name] {
+			errs.add("channels[%d]: duplicate channel %q", i, channel.Name)
+		}
+		channelNames[channel.Name] = true
+
+		if len(channel.Floors) == 0 {
+			errs.add("channels[%d] (%s): no floors", i, channel.Name)
+		}
+	}
+
+	for i, admin := range f.Admins {
+		if strings.TrimSpace(admin) == "" {
+			errs.add("admins[%d]: empty slack user id", i)
+		}
+	}
+
+	guestBookingsPerWeek := f.GuestBookingsPerWeek
+	if guestBookingsPerWeek < 0 {
+		errs.add("guest_bookings_per_week: must not be negative")
+	} else if guestBookingsPerWeek == 0 {
+		guestBookingsPerWeek = DefaultGuestBookingsPerWeek
+	}
+
+	plateLookupRoles := DefaultPlateLookupRoles
+	if f.PlateLookupRoles != nil {
+		plateLookupRoles = nil
+	}
+	for i, role := range f.PlateLookupRoles {
+		if !slices.Contains(user.AllRoles, user.Role(role)) {
+			errs.add("plate_lookup_roles[%d]: unknown role %q", i, role)
+		}
+		plateLookupRoles = append(plateLookupRoles, user.Role(role))
+	}
+
+	notificationThrottle := time.Duration(f.NotificationThrottleMinutes) * time.Minute
+	if f.NotificationThrottleMinutes < 0 {
+		errs.add("notification_throttle_minutes: must not be negative")
+	} else if f.NotificationThrottleMinutes == 0 {
+		notificationThrottle = DefaultNotificationThrottle
+	}
+
+	workspaceSlots := errs.timeSlots("workspace_slots", f.WorkspaceSlots)
+
+	for _, quota := range []struct {
+		name  string
+		value int
+	}{
+		{"quotas.per_week", f.Quotas.PerWeek},
+		{"quotas.per_month", f.Quotas.PerMonth},
+		{"quotas.consecutive_days", f.Quotas.ConsecutiveDays},
+		{"quotas.horizon_days", f.Quotas.HorizonDays},
+	} {
+		if quota.value < 0 {
+			errs.add("%s: must not be negative", quota.name)
+		}
+	}
+
+	errs.floorMap(f.FloorMap)
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	taEndpoint := f.Integrations.TaEndpoint
+	return &Config{
+		SlackAuthToken:       f.Slack.AuthToken,
+		SlackTaChannelId:     f.Slack.TaChannelId,
+		SlackAppToken:        f.Slack.AppToken,
+		SlackDigestChannelId: f.Slack.DigestChannelId,
+
+		DevicesFilename:    f.Storage.Devices,
+		UsersFilename:      f.Storage.Users,
+		ParkingFilename:    f.Storage.Parking,
+		WorkspacesFilename: f.Storage.Workspaces,
+
+		Debug:           f.Debug,
+		TaEndpoint:      taEndpoint,
+		WorkersEndpoint: fmt.Sprintf("%s/workers", taEndpoint),
+		ProxyEndpoint:   fmt.Sprintf("%s/proxy", taEndpoint),
+
+		ReportPersonId: f.Slack.ReportPersonId,
+
+		TestingActive: f.Testing,
+
+		HcmQdevUrl:               f.Companies["Qdev"].HcmUrl,
+		HcmQuadUrl:               f.Companies["Quad"].HcmUrl,
+		HcmApiToken:              f.Integrations.Hcm.ApiToken,
+		HcmVacationsHashFilename: f.Storage.HcmHash,
+
+		Bss: bss,
+
+		Location:               location,
+		SchedulerStateFilename: schedulerState,
+
+		HolidaysFilename: f.Storage.Holidays,
+
+		Schedules: schedules,
+
+		Channels: f.Channels,
+		Admins:   f.Admins,
+
+		GuestBookingsPerWeek: guestBookingsPerWeek,
+		PlateLookupRoles:     plateLookupRoles,
+		NotificationThrottle: notificationThrottle,
+		WorkspaceSlots:       workspaceSlots,
+		Quota:                user.Quota(f.Quotas),
+
+		FloorMapListen: f.FloorMap.Listen,
+		FloorMapUrl:    strings.TrimSuffix(f.FloorMap.PublicUrl, "/"),
+		FloorMapSecret: f.FloorMap.Secret,
+	}, nil
+}
+
+// floorMap Floor maps are served by the bot & fetched by slack, therefore
+// they can only be shown if slack can reach the bot through the public URL
+func (e *configErrors) floorMap(floorMap floorMapFile) {
+	if floorMap.Listen == "" && floorMap.PublicUrl == "" {
+		return
+	}
+	if floorMap.Listen == "" {
+		e.add("floor_map.listen: not set (required by floor_map.public_url)")
+	}
+	if floorMap.Secret == "" {
+		e.add("floor_map.secret: not set (use FLOOR_MAP_SECRET env variable)")
+	}
+	if floorMap.PublicUrl == "" {
+		e.add("floor_map.public_url: not set (required by floor_map.listen)")
+		return
+	}
+
+	publicUrl, err := url.Parse(floorMap.PublicUrl)
+	if err != nil {
+		e.add("floor_map.public_url: %v", err)
+		return
+	}
+	if (publicUrl.Scheme != "http" && publicUrl.Scheme != "https") || publicUrl.Host == "" {
+		e.add("floor_map.public_url: expected absolute http(s) URL, got %q", floorMap.PublicUrl)
+	}
+}
+
+func (e *configErrors) bssCompany(f *fileConfig, name string) BssCompanyConfig {
+	company := f.Companies[name].Bss
+	if f.Integrations.Bss.Url != "" {
+		prefix := "BSS_" + strings.ToUpper(name)
+		if company.Username == "" {
+			e.add("companies.%s.bss.username: not set (use %s_USERNAME env variable)", name, prefix)
+		}
+		if company.Password == "" {
+			e.add("companies.%s.bss.password: not set (use %s_PASSWORD env variable)", name, prefix)
+		}
+		if company.EnvironmentId <= 0 {
+			e.add("companies.%s.bss.environment_id: not set", name)
+		}
+		if company.CompanyId <= 0 {
+			e.add("companies.%s.bss.company_id: not set", name)
+		}
+	}
+
+	return BssCompanyConfig{
+		Username:      company.Username,
+		Password:      company.Password,
+		EnvironmentId: company.EnvironmentId,
+		CompanyId:     company.CompanyId,
+	}
+}
@@ -0,0 +1,68 @@
+package datafile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrChanged File was changed on disk (i.e. edited by hand) since it was last
+// read or written by the bot
+var ErrChanged = errors.New("file changed on disk since it was last read or written")
+
+// ConflictSuffix Suffix of the file where in-memory data is written if the
+// data file itself was changed on disk
+const ConflictSuffix = ".conflict"
+
+// Version Modification time & size of a data file at the time it was last
+// read or written by the bot.
+type Version struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Stat Returns current version of the file. Zero version is returned if the
+// file doesn't exist.
+func Stat(filename string) Version {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return Version{}
+	}
+	return Version{ModTime: info.ModTime(), Size: info.Size()}
+}
+
+func (v Version) IsZero() bool {
+	return v.ModTime.IsZero() && v.Size == 0
+}
+
+func (v Version) Equal(other Version) bool {
+	return v.ModTime.Equal(other.ModTime) && v.Size == other.Size
+}
+
+// Changed Checks if the file on disk differs from the given version. Zero
+// version means that the file was never read so it is not considered changed.
+func Changed(filename string, version Version) bool {
+	return !version.IsZero() && !Stat(filename).Equal(version)
+}
+
+// Write Writes data to file & returns its new version. If the file was
+// changed on disk since `version`, it is not overwritten. Instead data is
+// written to <filename>.conflict and ErrChanged is returned so that neither
+// the manual edit nor the in-memory changes are lost.
+func Write(filename string, data []byte, version Version) (Version, error) {
+	if Changed(filename, version) {
+		conflictFilename := filename + ConflictSuffix
+		err := os.WriteFile(conflictFilename, data, 0o666)
+		if err != nil {
+			return version, err
+		}
+		return version, fmt.Errorf("%w: %s (unsaved data written to %s)", ErrChanged, filename, conflictFilename)
+	}
+
+	err := os.WriteFile(filename, data, 0o666)
+	if err != nil {
+		return version, err
+	}
+	return Stat(filename), nil
+}
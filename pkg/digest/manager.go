@@ -0,0 +1,181 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	"github.com/slack-go/slack"
+)
+
+const PostDigest = "PostDigest"
+
+// Manager Posts availability of parking spaces & workspaces for the next
+// working day to the digest channel
+type Manager struct {
+	eventManager *event.EventManager
+	data         *model.Data
+	channelId    string
+}
+
+func NewManager(
+	eventManager *event.EventManager,
+	data *model.Data,
+	conf *config.Config,
+) *Manager {
+	return &Manager{
+		eventManager: eventManager,
+		data:         data,
+		channelId:    conf.SlackDigestChannelId,
+	}
+}
+
+func (m *Manager) Consume(e event.Event) {
+	switch e.Type() {
+	case event.TimerEvent:
+		data := e.(*event.TimerDone)
+		if data.Label != PostDigest {
+			return
+		}
+
+		// NOTE: spaces are not reset on non-working days so the digest would
+		// be the same as the one of the previous working day
+		if !m.data.Calendar.IsWorkingDay(data.Scheduled) {
+			slog.Info("Skip PostDigest: not a working day", "scheduled", data.Scheduled)
+			return
+		}
+
+		m.data.Lock()
+		defer m.data.Unlock()
+
+		response := m.handleDigest(data.Time)
+		m.eventManager.Publish(response)
+	}
+}
+
+func (m *Manager) Context() string {
+	return PostDigest
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) handleDigest(now time.Time) *common.Response {
+	// NOTE: digest is scheduled after all resets so this is always the next
+	// working day
+	date := m.data.Calendar.BookingDate(now, m.data.ParkingLot.ResetTimes.Default)
+	txt := fmt.Sprintf(":calendar: Availability for %s", date.Format("Mon 2006-01-02"))
+	slog.Info("PostDigest", "date", date)
+
+	allBlocks := []slack.Block{createTextBlock(fmt.Sprintf("*%s*", txt))}
+
+	allBlocks = append(allBlocks, slack.NewDividerBlock())
+	allBlocks = append(allBlocks, common.TextBlocks(
+		append([]string{"*:car: Parking spaces*"}, floorLines(m.data.ParkingLot)...),
+	)...)
+
+	releasedLines := []string{"*Released permanent spaces*"}
+	for _, release := range m.data.ParkingLot.ReleasedOn(date) {
+		line, ok := m.releaseLine(release)
+		if !ok {
+			continue
+		}
+		releasedLines = append(releasedLines, line)
+	}
+	if len(releasedLines) == 1 {
+		releasedLines = append(releasedLines, "_No permanent spaces are released_")
+	}
+	allBlocks = append(allBlocks, common.TextBlocks(releasedLines)...)
+
+	allBlocks = append(allBlocks, slack.NewDividerBlock())
+	allBlocks = append(allBlocks, common.TextBlocks(
+		append([]string{"*:desk: Workspaces*"}, floorLines(m.data.WorkspacesLot)...),
+	)...)
+
+	// NOTE: messages are routed by block ID so it starts with the identifier
+	// of the parking manager which opens the booking modal
+	bookBtn := slack.NewButtonBlockElement(
+		views.OpenBookingActionId,
+		"",
+		slack.NewTextBlockObject("plain_text", "Book parking :car:", true, false),
+	).WithStyle(slack.StylePrimary)
+	allBlocks = append(
+		allBlocks,
+		slack.NewActionBlock(parking_spaces.Identifier+"digest", bookBtn),
+	)
+
+	return common.NewResponseEvent(
+		"Digest Timer",
+		common.NewPostBlocksAction(m.channelId, txt, allBlocks...),
+	)
+}
+
+// releaseLine Returns false if the released space no longer exists
+func (m *Manager) releaseLine(release spaces.ReleaseInfo) (string, bool) {
+	space := m.data.ParkingLot.GetSpace(release.SpaceKey)
+	if space == nil {
+		return "", false
+	}
+
+	line := fmt.Sprintf(
+		"%s *%s* of %s (until %s)",
+		space.GetStatusEmoji(),
+		space.Key(),
+		release.OwnerName,
+		release.EndDate.Format("2006-01-02"),
+	)
+	if space.Reserved {
+		// NOTE: names instead of mentions so that nobody is pinged daily
+		line += fmt.Sprintf(" - taken by %s", space.ReservedBy)
+	}
+	return line, true
+}
+
+// floorLines Number of free spaces per floor
+func floorLines(lot *spaces.SpacesLot) []string {
+	var lines []string
+	for _, floor := range lot.GetAllFloorNumbers() {
+		total, free := 0, 0
+		for _, space := range lot.UnitSpaces {
+			if space.Floor != floor {
+				continue
+			}
+			total++
+			if !lot.IsTaken(space) {
+				free++
+			}
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%s *%s*: %d of %d free",
+			spaces.StatusEmoji(free == 0),
+			spaces.MakeFloorStr(floor),
+			free,
+			total,
+		))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "_No spaces_")
+	}
+	return lines
+}
+
+func createTextBlock(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn", text, false, false),
+		nil,
+		nil,
+	)
+}
@@ -4,22 +4,40 @@ import (
 	"fmt"
 	"log"
 	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/floor_map"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
 	"github.com/slack-go/slack"
 )
 
 const (
-	selectEditOptionId  = "selectEditOptionId"
-	selectSpaceOptionId = "selectSpaceOptionId"
-	addFloorActionId    = "addFloorActionId"
-	addFloorBlockId     = "addFloorBlockId"
-	addSpaceActionId    = "addSpaceActionId"
-	addSpaceBlockId     = "addSpaceBlockId"
-	changePlanBlockId   = "changePlanBlockId"
-	changePlanActionId  = "changePlanActionId"
+	selectEditOptionId    = "selectEditOptionId"
+	selectSpaceOptionId   = "selectSpaceOptionId"
+	addFloorActionId      = "addFloorActionId"
+	addFloorBlockId       = "addFloorBlockId"
+	addSpaceActionId      = "addSpaceActionId"
+	addSpaceBlockId       = "addSpaceBlockId"
+	addAttributesBlockId  = "addAttributesBlockId"
+	addAttributesActionId = "addAttributesActionId"
+	addGroupsBlockId      = "addGroupsBlockId"
+	addGroupsActionId     = "addGroupsActionId"
+	changePlanBlockId     = "changePlanBlockId"
+	changePlanActionId    = "changePlanActionId"
+	changeResetBlockId    = "changeResetBlockId"
+	changeResetActionId   = "changeResetActionId"
+	mapSpaceBlockId       = "mapSpaceBlockId"
+	mapSpaceActionId      = "mapSpaceActionId"
+	mapXBlockId           = "mapXBlockId"
+	mapYBlockId           = "mapYBlockId"
+	mapPositionActionId   = "mapPositionActionId"
+	// defaultResetFloor Used instead of floor name in block/action IDs of the
+	// lot's default reset time input
+	defaultResetFloor = "default"
 )
 
 type editOption string
@@ -29,12 +47,16 @@ const (
 	addSpaceOption    editOption = "Add Space"
 	removeSpaceOption editOption = "Remove Space/s"
 	changePlansOption editOption = "Change Plan/s"
+	changeResetOption editOption = "Change Reset Times"
+	changeMapOption   editOption = "Change Map Positions"
 )
 
 var editOptions = []editOption{
 	addSpaceOption,
 	removeSpaceOption,
 	changePlansOption,
+	changeResetOption,
+	changeMapOption,
 }
 
 var parkSpaceManagementTitle = Identifier
@@ -62,6 +84,10 @@ func (m *Manager) generateAddRemoveBlocks(userId string) []slack.Block {
 		allBlocks = append(allBlocks, m.generateRemoveSpaceBlocks()...)
 	case changePlansOption:
 		allBlocks = append(allBlocks, m.generateChangePlansBlocks()...)
+	case changeResetOption:
+		allBlocks = append(allBlocks, m.generateChangeResetBlocks()...)
+	case changeMapOption:
+		allBlocks = append(allBlocks, m.generateChangeMapBlocks()...)
 	case notSelectedOption:
 		// do nothing
 	default:
@@ -180,9 +206,61 @@ func (m *Manager) generateAddSpaceBlocks() []slack.Block {
 	)
 	allBlocks = append(allBlocks, numberInputBlock)
 
+	allBlocks = append(allBlocks, generateAttributesInput(spaces.ParkingAttributes))
+	allBlocks = append(allBlocks, m.generateGroupsInput())
+
 	return allBlocks
 }
 
+func generateAttributesInput(attributes []spaces.Attribute) *slack.InputBlock {
+	var options []*slack.OptionBlockObject
+	for _, attr := range attributes {
+		options = append(options, slack.NewOptionBlockObject(
+			string(attr),
+			slack.NewTextBlockObject("plain_text", spaces.AttributeLabel(attr), true, false),
+			nil,
+		))
+	}
+
+	attributesInput := slack.NewCheckboxGroupsBlockElement(addAttributesActionId, options...)
+	attributesLabel := slack.NewTextBlockObject("plain_text", "Attributes", false, false)
+	attributesHint := slack.NewTextBlockObject(
+		"plain_text",
+		"Attributes of existing parking spaces can be changed by importing them with /bulk-data",
+		false,
+		false,
+	)
+
+	return common.NewInputBlock(
+		addAttributesBlockId,
+		attributesLabel,
+		attributesHint,
+		attributesInput,
+		true,
+	)
+}
+
+func (m *Manager) generateGroupsInput() *slack.InputBlock {
+	groupsPlaceholder := slack.NewTextBlockObject("plain_text", "management, visitors", false, false)
+	groupsInput := slack.NewPlainTextInputBlockElement(groupsPlaceholder, addGroupsActionId)
+	groupsLabel := slack.NewTextBlockObject("plain_text", "Restricted to groups", false, false)
+
+	hint := "Comma separated user groups that can reserve the space. Leave empty for everyone. " +
+		"Groups of existing parking spaces can be changed by importing them with /bulk-data"
+	if groups := m.data.UserManager.AllGroups(); len(groups) > 0 {
+		hint += fmt.Sprintf(". Existing groups: %s", strings.Join(groups, ", "))
+	}
+	groupsHint := slack.NewTextBlockObject("plain_text", hint, false, false)
+
+	return common.NewInputBlock(
+		addGroupsBlockId,
+		groupsLabel,
+		groupsHint,
+		groupsInput,
+		true,
+	)
+}
+
 func (m *Manager) generateChangePlansBlocks() []slack.Block {
 	var allBlocks []slack.Block
 
@@ -227,6 +305,112 @@ func (m *Manager) generateFloorPlanInput(floor, link string) *slack.InputBlock {
 	)
 }
 
+func (m *Manager) generateChangeResetBlocks() []slack.Block {
+	allBlocks := []slack.Block{
+		m.generateResetTimeInput(
+			defaultResetFloor,
+			"All floors",
+			"Floors without their own reset time are reset at this time",
+			m.data.ParkingLot.ResetTimes.Default,
+		),
+	}
+
+	for _, floor := range m.data.ParkingLot.GetAllFloors() {
+		allBlocks = append(allBlocks, m.generateResetTimeInput(
+			floor,
+			floor,
+			"Set it to the same time as 'All floors' to use the default",
+			m.data.ParkingLot.ResetTime(floor),
+		))
+	}
+
+	return allBlocks
+}
+
+func (m *Manager) generateResetTimeInput(
+	floor, label, hint string,
+	resetTime calendar.TimeOfDay,
+) *slack.InputBlock {
+	timePicker := slack.NewTimePickerBlockElement(floor + changeResetActionId)
+	timePicker.InitialTime = resetTime.String()
+
+	return common.NewInputBlock(
+		floor+changeResetBlockId,
+		slack.NewTextBlockObject(
+			slack.PlainTextType,
+			fmt.Sprintf("%s reset time", label),
+			false,
+			false,
+		),
+		slack.NewTextBlockObject(slack.PlainTextType, hint, false, false),
+		timePicker,
+		false,
+	)
+}
+
+// generateChangeMapBlocks Inputs for the map position of a space & the
+// current maps of all floors (if floor maps are enabled)
+func (m *Manager) generateChangeMapBlocks() []slack.Block {
+	var allBlocks []slack.Block
+
+	for _, floor := range m.data.ParkingLot.GetAllFloors() {
+		// NOTE: no user is given so that all reserved spaces are shown as taken
+		mapUrl := m.floorMaps.ParkingMap(m.data.ParkingLot, floor, "")
+		if mapUrl == "" {
+			continue
+		}
+		allBlocks = append(allBlocks, slack.NewImageBlock(
+			mapUrl,
+			fmt.Sprintf("Map of %s parking spaces", floor),
+			"",
+			slack.NewTextBlockObject(slack.PlainTextType, floor+" map", false, false),
+		))
+	}
+
+	var optionGroups []*slack.OptionGroupBlockObject
+	for _, floor := range m.data.ParkingLot.GetAllFloors() {
+		optionGroups = append(optionGroups, m.generateSpaceOptionsByFloor(floor))
+	}
+	spaceSelect := slack.NewOptionsGroupSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Select space", false, false),
+		mapSpaceActionId,
+		optionGroups...,
+	)
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		mapSpaceBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Space", false, false),
+		nil,
+		spaceSelect,
+		false,
+	))
+
+	hint := fmt.Sprintf(
+		"Counted from the top left corner of the map (0-%d). Leave both empty to remove the space from the map.",
+		floor_map.MaxGridSize-1,
+	)
+	for _, input := range []struct {
+		blockId, label string
+	}{
+		{mapXBlockId, "Column"},
+		{mapYBlockId, "Row"},
+	} {
+		numberInput := slack.NewNumberInputBlockElement(nil, mapPositionActionId, false)
+		numberInput.MinValue = "0"
+		numberInput.MaxValue = strconv.Itoa(floor_map.MaxGridSize - 1)
+
+		allBlocks = append(allBlocks, common.NewInputBlock(
+			input.blockId,
+			slack.NewTextBlockObject(slack.PlainTextType, input.label, false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, hint, false, false),
+			numberInput,
+			true,
+		))
+	}
+
+	return allBlocks
+}
+
 func (m *Manager) generateRemoveSpaceBlocks() []slack.Block {
 	return m.generateSelectSpaceOptions()
 }
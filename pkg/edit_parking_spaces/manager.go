@@ -1,6 +1,8 @@
 package edit_parking_spaces
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -8,11 +10,15 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/floor_map"
 	"github.com/AngelVI13/slack-bot/pkg/model"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces"
 	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
 	"github.com/slack-go/slack"
 )
@@ -50,14 +56,19 @@ type Manager struct {
 	eventManager       *event.EventManager
 	data               *model.Data
 	slackClient        *slack.Client
+	scheduler          *event.Scheduler
 	selectedEditOption selectedEditOptionMap
 	testingActive      bool
+	// floorMaps URLs of rendered floor maps (zero value means floor maps
+	// are not shown)
+	floorMaps floor_map.Links
 }
 
 func NewManager(
 	eventManager *event.EventManager,
 	data *model.Data,
 	conf *config.Config,
+	scheduler *event.Scheduler,
 ) *Manager {
 	parkSpaceManagementTitle = common.MakeTitle(
 		parkSpaceManagementTitle,
@@ -66,12 +77,18 @@ func NewManager(
 	return &Manager{
 		eventManager:       eventManager,
 		data:               data,
+		scheduler:          scheduler,
 		selectedEditOption: selectedEditOptionMap{},
 		testingActive:      conf.TestingActive,
+		floorMaps:          floor_map.NewLinks(conf),
 	}
 }
 
 func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
 	switch e.Type() {
 	case event.SlashCmdEvent:
 		data := e.(*slackApi.Slash)
@@ -116,8 +133,21 @@ func (m *Manager) Context() string {
 	return Identifier
 }
 
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - parking spaces are written to file by model.Data
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
 func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
-	if !m.data.UserManager.IsAdminId(data.UserId) {
+	if !m.data.UserManager.HasAnyPermission(
+		data.UserId,
+		user.PermEditParkingSpaces,
+		user.PermAudit,
+	) {
 		errTxt := fmt.Sprintf(
 			"You don't have permission to execute '%s' command",
 			data.Command,
@@ -234,7 +264,15 @@ func (m *Manager) handleAddSpaceSubmission(
 		return actions
 	}
 
-	space := spaces.NewSpace(spaceNumber, floor, "")
+	attributes, err := spaces.ParseAttributes(data.IValue("", addAttributesActionId))
+	if err != nil {
+		errTxt := fmt.Sprintf("Parking space was not added - %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	space := spaces.NewSpace(spaceNumber, floor, "", attributes...)
+	space.Groups = user.ParseGroups(data.IValueString(addGroupsBlockId, addGroupsActionId))
 	spaceKey := space.Key()
 
 	_, found := m.data.ParkingLot.UnitSpaces[spaceKey]
@@ -291,6 +329,139 @@ func (m *Manager) handleChangePlanSubmission(
 	return actions
 }
 
+func (m *Manager) handleChangeResetSubmission(
+	data *slackApi.ViewSubmission,
+) []event.ResponseAction {
+	var actions []event.ResponseAction
+	lot := m.data.ParkingLot
+
+	selectedTime := func(floor string) (calendar.TimeOfDay, error) {
+		return calendar.ParseTimeOfDay(
+			data.IValueString(floor+changeResetBlockId, floor+changeResetActionId),
+		)
+	}
+
+	defaultTime, err := selectedTime(defaultResetFloor)
+	if err != nil {
+		errTxt := fmt.Sprintf("Reset times were not changed - %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	// NOTE: only floors where the selected time differs from the current
+	// one are changed. Otherwise changing the default time would turn all
+	// floors which use the default into floors with their own reset time.
+	changedFloors := map[string]calendar.TimeOfDay{}
+	for _, floor := range lot.GetAllFloors() {
+		floorTime, err := selectedTime(floor)
+		if err != nil {
+			errTxt := fmt.Sprintf("Reset times were not changed - %s: %v", floor, err)
+			actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+			return actions
+		}
+		if floorTime != lot.ResetTime(floor) {
+			changedFloors[floor] = floorTime
+		}
+	}
+
+	// NOTE: schedules are read on every change so that the ones of a
+	// reloaded config are used
+	schedules := m.data.Schedules()
+	errs := []error{schedules.ValidateParkingReset(defaultTime)}
+	for _, floorTime := range changedFloors {
+		errs = append(errs, schedules.ValidateParkingReset(floorTime))
+	}
+	err = errors.Join(errs...)
+	if err != nil {
+		errTxt := fmt.Sprintf("Reset times were not changed:\n%v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	slog.Info(
+		"Updating parking reset times",
+		"requestor", data.UserName,
+		"default", defaultTime,
+		"floors", changedFloors,
+	)
+	lot.ChangeDefaultResetTime(defaultTime, schedules.ParkingReset)
+	for floor, floorTime := range changedFloors {
+		lot.SetFloorResetTime(floor, floorTime)
+	}
+	lot.SynchronizeToFile()
+
+	err = m.scheduler.SetDaily(parking_spaces.ResetParking, lot.AllResetTimes())
+	if err != nil {
+		errTxt := fmt.Sprintf("Failed to schedule parking reset: %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+	}
+
+	return actions
+}
+
+func (m *Manager) handleChangeMapSubmission(
+	data *slackApi.ViewSubmission,
+) []event.ResponseAction {
+	var actions []event.ResponseAction
+
+	spaceKey := spaces.SpaceKey(data.IValueString(mapSpaceBlockId, mapSpaceActionId))
+	space, found := m.data.ParkingLot.UnitSpaces[spaceKey]
+	if !found {
+		errTxt := fmt.Sprintf("Map position was not changed - unknown space %q", spaceKey)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	xStr := strings.TrimSpace(data.IValueString(mapXBlockId, mapPositionActionId))
+	yStr := strings.TrimSpace(data.IValueString(mapYBlockId, mapPositionActionId))
+
+	var position *spaces.MapPosition
+	if xStr != "" || yStr != "" {
+		x, errX := strconv.Atoi(xStr)
+		y, errY := strconv.Atoi(yStr)
+		if errors.Join(errX, errY) != nil ||
+			x < 0 || y < 0 || x >= floor_map.MaxGridSize || y >= floor_map.MaxGridSize {
+			errTxt := fmt.Sprintf(
+				"Map position of %s was not changed - column & row have to be numbers between 0 & %d",
+				spaceKey,
+				floor_map.MaxGridSize-1,
+			)
+			actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+			return actions
+		}
+		position = &spaces.MapPosition{X: x, Y: y}
+	}
+
+	if position != nil {
+		for _, other := range m.data.ParkingLot.UnitSpaces {
+			if other != space && other.Floor == space.Floor &&
+				other.MapPosition != nil && *other.MapPosition == *position {
+				errTxt := fmt.Sprintf(
+					"Map position of %s was not changed - %s is already at column %d, row %d",
+					spaceKey,
+					other.Key(),
+					position.X,
+					position.Y,
+				)
+				actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+				return actions
+			}
+		}
+	}
+
+	slog.Info(
+		"Changing map position",
+		"requestor", data.UserName,
+		"space", spaceKey,
+		"old", space.MapPosition,
+		"new", position,
+	)
+	space.MapPosition = position
+	m.data.ParkingLot.SynchronizeToFile()
+
+	return actions
+}
+
 func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Response {
 	var actions []event.ResponseAction
 
@@ -298,6 +469,13 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 	// Reset selected action
 	m.selectedEditOption.ResetSelectionForUser(data.UserId)
 
+	if selectedAction != notSelectedOption &&
+		!m.data.UserManager.HasPermission(data.UserId, user.PermEditParkingSpaces) {
+		errTxt := "Nothing was changed - you don't have permission to change these settings"
+		action := common.NewPostAction(data.UserId, errTxt, false)
+		return common.NewResponseEvent(data.UserName, action)
+	}
+
 	switch selectedAction {
 	case removeSpaceOption:
 		actions = append(actions, m.handleRemoveSpaceSubmission(data)...)
@@ -305,6 +483,10 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 		actions = append(actions, m.handleAddSpaceSubmission(data)...)
 	case changePlansOption:
 		actions = append(actions, m.handleChangePlanSubmission(data)...)
+	case changeResetOption:
+		actions = append(actions, m.handleChangeResetSubmission(data)...)
+	case changeMapOption:
+		actions = append(actions, m.handleChangeMapSubmission(data)...)
 	case notSelectedOption:
 		return nil // do nothing
 	default:
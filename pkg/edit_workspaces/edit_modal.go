@@ -4,39 +4,78 @@ import (
 	"fmt"
 	"log"
 	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/event"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	"github.com/slack-go/slack"
 )
 
 const (
-	selectEditOptionId  = "selectEditOptionId"
-	selectSpaceOptionId = "selectSpaceOptionId"
-	addFloorActionId    = "addFloorActionId"
-	addFloorBlockId     = "addFloorBlockId"
-	addSpaceActionId    = "addSpaceActionId"
-	addSpaceBlockId     = "addSpaceBlockId"
-	addDescActionId     = "addDescActionId"
-	addDescBlockId      = "addDescBlockId"
-	changePlanBlockId   = "changePlanBlockId"
-	changePlanActionId  = "changePlanActionId"
+	selectEditOptionId    = "selectEditOptionId"
+	selectSpaceOptionId   = "selectSpaceOptionId"
+	addFloorActionId      = "addFloorActionId"
+	addFloorBlockId       = "addFloorBlockId"
+	addSpaceActionId      = "addSpaceActionId"
+	addSpaceBlockId       = "addSpaceBlockId"
+	addAttributesBlockId  = "addAttributesBlockId"
+	addAttributesActionId = "addAttributesActionId"
+	addGroupsBlockId      = "addGroupsBlockId"
+	addGroupsActionId     = "addGroupsActionId"
+	addDescActionId       = "addDescActionId"
+	addDescBlockId        = "addDescBlockId"
+	changePlanBlockId     = "changePlanBlockId"
+	changePlanActionId    = "changePlanActionId"
+	changeResetBlockId    = "changeResetBlockId"
+	changeResetActionId   = "changeResetActionId"
+	// defaultResetFloor Used instead of floor name in block/action IDs of the
+	// lot's default reset time input
+	defaultResetFloor = "default"
+
+	channelSelectBlockId        = "channelSelectBlockId"
+	channelSelectActionId       = "channelSelectActionId"
+	channelFloorsBlockId        = "channelFloorsBlockId"
+	channelFloorsActionId       = "channelFloorsActionId"
+	channelDefaultFloorBlockId  = "channelDefaultFloorBlockId"
+	channelDefaultFloorActionId = "channelDefaultFloorActionId"
+	channelTeamBlockId          = "channelTeamBlockId"
+	channelTeamActionId         = "channelTeamActionId"
+	channelRemoveBlockId        = "channelRemoveBlockId"
+	channelRemoveActionId       = "channelRemoveActionId"
+	channelRemoveOption         = "remove"
+
+	neighbourhoodNameBlockId    = "neighbourhoodNameBlockId"
+	neighbourhoodNameActionId   = "neighbourhoodNameActionId"
+	neighbourhoodSpacesBlockId  = "neighbourhoodSpacesBlockId"
+	neighbourhoodSpacesActionId = "neighbourhoodSpacesActionId"
+	neighbourhoodRemoveBlockId  = "neighbourhoodRemoveBlockId"
+	neighbourhoodRemoveActionId = "neighbourhoodRemoveActionId"
+	neighbourhoodRemoveOption   = "remove"
 )
 
 type editOption string
 
 const (
-	notSelectedOption editOption = "Not Selected"
-	addSpaceOption    editOption = "Add Space"
-	removeSpaceOption editOption = "Remove Space/s"
-	changePlansOption editOption = "Change Plan/s"
+	notSelectedOption    editOption = "Not Selected"
+	addSpaceOption       editOption = "Add Space"
+	removeSpaceOption    editOption = "Remove Space/s"
+	changePlansOption    editOption = "Change Plan/s"
+	changeResetOption    editOption = "Change Reset Times"
+	channelsOption       editOption = "Change Channels"
+	neighbourhoodsOption editOption = "Change Neighbourhoods"
 )
 
 var editOptions = []editOption{
 	addSpaceOption,
 	removeSpaceOption,
 	changePlansOption,
+	changeResetOption,
+	channelsOption,
+	neighbourhoodsOption,
 }
 
 var workSpaceManagementTitle = Identifier
@@ -65,6 +104,12 @@ func (m *Manager) generateAddRemoveBlocks(userId string) []slack.Block {
 		allBlocks = append(allBlocks, m.generateRemoveSpaceBlocks()...)
 	case changePlansOption:
 		allBlocks = append(allBlocks, m.generateChangePlansBlocks()...)
+	case changeResetOption:
+		allBlocks = append(allBlocks, m.generateChangeResetBlocks()...)
+	case channelsOption:
+		allBlocks = append(allBlocks, m.generateChannelsBlocks()...)
+	case neighbourhoodsOption:
+		allBlocks = append(allBlocks, m.generateNeighbourhoodsBlocks()...)
 	case notSelectedOption:
 		// do nothing
 	default:
@@ -212,9 +257,61 @@ func (m *Manager) generateAddSpaceBlocks() []slack.Block {
 	)
 	allBlocks = append(allBlocks, descInputBlock)
 
+	allBlocks = append(allBlocks, generateAttributesInput(spaces.WorkspaceAttributes))
+	allBlocks = append(allBlocks, m.generateGroupsInput())
+
 	return allBlocks
 }
 
+func generateAttributesInput(attributes []spaces.Attribute) *slack.InputBlock {
+	var options []*slack.OptionBlockObject
+	for _, attr := range attributes {
+		options = append(options, slack.NewOptionBlockObject(
+			string(attr),
+			slack.NewTextBlockObject("plain_text", spaces.AttributeLabel(attr), true, false),
+			nil,
+		))
+	}
+
+	attributesInput := slack.NewCheckboxGroupsBlockElement(addAttributesActionId, options...)
+	attributesLabel := slack.NewTextBlockObject("plain_text", "Attributes", false, false)
+	attributesHint := slack.NewTextBlockObject(
+		"plain_text",
+		"Attributes of existing workspaces can be changed by importing them with /bulk-data",
+		false,
+		false,
+	)
+
+	return common.NewInputBlock(
+		addAttributesBlockId,
+		attributesLabel,
+		attributesHint,
+		attributesInput,
+		true,
+	)
+}
+
+func (m *Manager) generateGroupsInput() *slack.InputBlock {
+	groupsPlaceholder := slack.NewTextBlockObject("plain_text", "management, visitors", false, false)
+	groupsInput := slack.NewPlainTextInputBlockElement(groupsPlaceholder, addGroupsActionId)
+	groupsLabel := slack.NewTextBlockObject("plain_text", "Restricted to groups", false, false)
+
+	hint := "Comma separated user groups that can reserve the space. Leave empty for everyone. " +
+		"Groups of existing workspaces can be changed by importing them with /bulk-data"
+	if groups := m.data.UserManager.AllGroups(); len(groups) > 0 {
+		hint += fmt.Sprintf(". Existing groups: %s", strings.Join(groups, ", "))
+	}
+	groupsHint := slack.NewTextBlockObject("plain_text", hint, false, false)
+
+	return common.NewInputBlock(
+		addGroupsBlockId,
+		groupsLabel,
+		groupsHint,
+		groupsInput,
+		true,
+	)
+}
+
 func (m *Manager) generateChangePlansBlocks() []slack.Block {
 	var allBlocks []slack.Block
 
@@ -259,6 +356,278 @@ func (m *Manager) generateFloorPlanInput(floor, link string) *slack.InputBlock {
 	)
 }
 
+func (m *Manager) generateChangeResetBlocks() []slack.Block {
+	allBlocks := []slack.Block{
+		m.generateResetTimeInput(
+			defaultResetFloor,
+			"All floors",
+			"Floors without their own reset time are reset at this time",
+			m.data.WorkspacesLot.ResetTimes.Default,
+		),
+	}
+
+	for _, floor := range m.data.WorkspacesLot.GetAllFloors() {
+		allBlocks = append(allBlocks, m.generateResetTimeInput(
+			floor,
+			floor,
+			"Set it to the same time as 'All floors' to use the default",
+			m.data.WorkspacesLot.ResetTime(floor),
+		))
+	}
+
+	return allBlocks
+}
+
+func (m *Manager) generateResetTimeInput(
+	floor, label, hint string,
+	resetTime calendar.TimeOfDay,
+) *slack.InputBlock {
+	timePicker := slack.NewTimePickerBlockElement(floor + changeResetActionId)
+	timePicker.InitialTime = resetTime.String()
+
+	return common.NewInputBlock(
+		floor+changeResetBlockId,
+		slack.NewTextBlockObject(
+			slack.PlainTextType,
+			fmt.Sprintf("%s reset time", label),
+			false,
+			false,
+		),
+		slack.NewTextBlockObject(slack.PlainTextType, hint, false, false),
+		timePicker,
+		false,
+	)
+}
+
+func (m *Manager) generateChannelsBlocks() []slack.Block {
+	lot := m.data.WorkspacesLot
+
+	var channelLines []string
+	for _, channelId := range lot.ChannelIds() {
+		channel, _ := lot.Channel(channelId)
+
+		var floors []string
+		for _, floor := range channel.Floors {
+			floors = append(floors, spaces.MakeFloorStr(floor))
+		}
+
+		line := fmt.Sprintf(
+			"• <#%s>: %s (default %s)",
+			channelId,
+			strings.Join(floors, ", "),
+			spaces.MakeFloorStr(channel.DefaultFloor),
+		)
+		if channel.Company != user.UnknownCompany {
+			line += fmt.Sprintf(" - team %s", user.CompanyNameMap[channel.Company])
+		}
+		channelLines = append(channelLines, line)
+	}
+
+	channelsText := "_No channels configured_"
+	if len(channelLines) > 0 {
+		channelsText = "*Configured channels*\n" + strings.Join(channelLines, "\n")
+	}
+
+	var floorOptions []*slack.OptionBlockObject
+	for _, floor := range lot.GetAllFloorNumbers() {
+		floorOptions = append(floorOptions, slack.NewOptionBlockObject(
+			strconv.Itoa(floor),
+			slack.NewTextBlockObject(slack.PlainTextType, spaces.MakeFloorStr(floor), false, false),
+			nil,
+		))
+	}
+
+	var teamOptions []*slack.OptionBlockObject
+	for _, company := range []user.Company{user.Qdev, user.Quad} {
+		teamOptions = append(teamOptions, slack.NewOptionBlockObject(
+			string(company),
+			slack.NewTextBlockObject(slack.PlainTextType, user.CompanyNameMap[company], false, false),
+			nil,
+		))
+	}
+
+	channelSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeConversations,
+		slack.NewTextBlockObject(slack.PlainTextType, "Select a channel", false, false),
+		channelSelectActionId,
+	)
+	channelSelect.Filter = &slack.SelectBlockElementFilter{
+		Include:         []string{"public", "private"},
+		ExcludeBotUsers: true,
+	}
+
+	removeOption := slack.NewOptionBlockObject(
+		channelRemoveOption,
+		slack.NewTextBlockObject(slack.PlainTextType, "Remove channel", false, false),
+		nil,
+	)
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, channelsText, false, false),
+			nil,
+			nil,
+		),
+		common.NewInputBlock(
+			channelSelectBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Channel", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Existing channel settings are replaced",
+				false,
+				false,
+			),
+			channelSelect,
+			false,
+		),
+		common.NewInputBlock(
+			channelFloorsBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Floors", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Workspace floors that can be booked from the channel",
+				false,
+				false,
+			),
+			slack.NewOptionsMultiSelectBlockElement(
+				slack.MultiOptTypeStatic,
+				slack.NewTextBlockObject(slack.PlainTextType, "Select floors", false, false),
+				channelFloorsActionId,
+				floorOptions...,
+			),
+			true,
+		),
+		common.NewInputBlock(
+			channelDefaultFloorBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Default floor", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Floor shown when the booking modal is opened. First floor is used if empty.",
+				false,
+				false,
+			),
+			slack.NewOptionsSelectBlockElement(
+				slack.OptTypeStatic,
+				slack.NewTextBlockObject(slack.PlainTextType, "Select floor", false, false),
+				channelDefaultFloorActionId,
+				floorOptions...,
+			),
+			true,
+		),
+		common.NewInputBlock(
+			channelTeamBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Team", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Team members that use the command in a direct message get this channel's floors",
+				false,
+				false,
+			),
+			slack.NewOptionsSelectBlockElement(
+				slack.OptTypeStatic,
+				slack.NewTextBlockObject(slack.PlainTextType, "Select team", false, false),
+				channelTeamActionId,
+				teamOptions...,
+			),
+			true,
+		),
+		common.NewInputBlock(
+			channelRemoveBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Remove", false, false),
+			nil,
+			slack.NewCheckboxGroupsBlockElement(channelRemoveActionId, removeOption),
+			true,
+		),
+	}
+}
+
+func (m *Manager) generateNeighbourhoodsBlocks() []slack.Block {
+	lot := m.data.WorkspacesLot
+
+	var lines []string
+	for _, name := range lot.NeighbourhoodNames(nil) {
+		neighbourhood := lot.Neighbourhoods[name]
+		var numbers []string
+		for _, spaceKey := range neighbourhood.Spaces {
+			if space, found := lot.UnitSpaces[spaceKey]; found {
+				numbers = append(numbers, strconv.Itoa(space.Number))
+			}
+		}
+		lines = append(lines, fmt.Sprintf(
+			"• *%s* (%s): %s",
+			name,
+			spaces.MakeFloorStr(neighbourhood.Floor),
+			strings.Join(numbers, ", "),
+		))
+	}
+
+	neighbourhoodsText := "_No neighbourhoods configured_"
+	if len(lines) > 0 {
+		neighbourhoodsText = "*Configured neighbourhoods*\n" + strings.Join(lines, "\n")
+	}
+
+	var optionGroups []*slack.OptionGroupBlockObject
+	for _, floor := range lot.GetAllFloors() {
+		optionGroup := m.generateSpaceOptionsByFloor(floor)
+		if len(optionGroup.Options) > 0 {
+			optionGroups = append(optionGroups, optionGroup)
+		}
+	}
+
+	removeOption := slack.NewOptionBlockObject(
+		neighbourhoodRemoveOption,
+		slack.NewTextBlockObject(slack.PlainTextType, "Remove neighbourhood", false, false),
+		nil,
+	)
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, neighbourhoodsText, false, false),
+			nil,
+			nil,
+		),
+		common.NewInputBlock(
+			neighbourhoodNameBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Name", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Existing neighbourhood with the same name is replaced",
+				false,
+				false,
+			),
+			slack.NewPlainTextInputBlockElement(
+				slack.NewTextBlockObject(slack.PlainTextType, "Window row", false, false),
+				neighbourhoodNameActionId,
+			),
+			false,
+		),
+		common.NewInputBlock(
+			neighbourhoodSpacesBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Workspaces", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Adjacent workspaces of one floor. Workspaces with neighbouring numbers are booked together.",
+				false,
+				false,
+			),
+			slack.NewOptionsGroupMultiSelectBlockElement(
+				slack.MultiOptTypeStatic,
+				slack.NewTextBlockObject(slack.PlainTextType, "Select workspaces", false, false),
+				neighbourhoodSpacesActionId,
+				optionGroups...,
+			),
+			true,
+		),
+		common.NewInputBlock(
+			neighbourhoodRemoveBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Remove", false, false),
+			nil,
+			slack.NewCheckboxGroupsBlockElement(neighbourhoodRemoveActionId, removeOption),
+			true,
+		),
+	}
+}
+
 func (m *Manager) generateRemoveSpaceBlocks() []slack.Block {
 	return m.generateSelectSpaceOptions()
 }
@@ -1,6 +1,8 @@
 package edit_workspaces
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -8,12 +10,15 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
 	"github.com/AngelVI13/slack-bot/pkg/model"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/AngelVI13/slack-bot/pkg/workspaces"
 	"github.com/slack-go/slack"
 )
 
@@ -50,6 +55,7 @@ type Manager struct {
 	eventManager       *event.EventManager
 	data               *model.Data
 	slackClient        *slack.Client
+	scheduler          *event.Scheduler
 	selectedEditOption selectedEditOptionMap
 	testingActive      bool
 }
@@ -58,6 +64,7 @@ func NewManager(
 	eventManager *event.EventManager,
 	data *model.Data,
 	conf *config.Config,
+	scheduler *event.Scheduler,
 ) *Manager {
 	workSpaceManagementTitle = common.MakeTitle(
 		workSpaceManagementTitle,
@@ -66,12 +73,17 @@ func NewManager(
 	return &Manager{
 		eventManager:       eventManager,
 		data:               data,
+		scheduler:          scheduler,
 		selectedEditOption: selectedEditOptionMap{},
 		testingActive:      conf.TestingActive,
 	}
 }
 
 func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
 	switch e.Type() {
 	case event.SlashCmdEvent:
 		data := e.(*slackApi.Slash)
@@ -116,8 +128,21 @@ func (m *Manager) Context() string {
 	return Identifier
 }
 
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - workspaces are written to file by model.Data
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
 func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
-	if !m.data.UserManager.IsAdminId(data.UserId) {
+	if !m.data.UserManager.HasAnyPermission(
+		data.UserId,
+		user.PermEditWorkspaces,
+		user.PermAudit,
+	) {
 		errTxt := fmt.Sprintf(
 			"You don't have permission to execute '%s' command",
 			data.Command,
@@ -188,6 +213,7 @@ func (m *Manager) handleRemoveSpaceSubmission(
 	for _, space := range selectedSpaces {
 		spaceKey := spaces.SpaceKey(space)
 		m.data.WorkspacesLot.ToBeReleased.RemoveAllReleases(spaceKey)
+		m.data.WorkspacesLot.RemoveFromNeighbourhoods(spaceKey)
 		delete(m.data.WorkspacesLot.UnitSpaces, spaceKey)
 	}
 
@@ -229,8 +255,16 @@ func (m *Manager) handleAddSpaceSubmission(
 		return actions
 	}
 
+	attributes, err := spaces.ParseAttributes(data.IValue("", addAttributesActionId))
+	if err != nil {
+		errTxt := fmt.Sprintf("Workspace was not added - %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
 	description = strings.Trim(description, " ")
-	space := spaces.NewSpace(spaceNumber, floor, description)
+	space := spaces.NewSpace(spaceNumber, floor, description, attributes...)
+	space.Groups = user.ParseGroups(data.IValueString(addGroupsBlockId, addGroupsActionId))
 	spaceKey := space.Key()
 
 	_, found := m.data.WorkspacesLot.UnitSpaces[spaceKey]
@@ -287,6 +321,185 @@ func (m *Manager) handleChangePlanSubmission(
 	return actions
 }
 
+func (m *Manager) handleChangeResetSubmission(
+	data *slackApi.ViewSubmission,
+) []event.ResponseAction {
+	var actions []event.ResponseAction
+	lot := m.data.WorkspacesLot
+
+	selectedTime := func(floor string) (calendar.TimeOfDay, error) {
+		return calendar.ParseTimeOfDay(
+			data.IValueString(floor+changeResetBlockId, floor+changeResetActionId),
+		)
+	}
+
+	defaultTime, err := selectedTime(defaultResetFloor)
+	if err != nil {
+		errTxt := fmt.Sprintf("Reset times were not changed - %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	// NOTE: only floors where the selected time differs from the current
+	// one are changed. Otherwise changing the default time would turn all
+	// floors which use the default into floors with their own reset time.
+	changedFloors := map[string]calendar.TimeOfDay{}
+	for _, floor := range lot.GetAllFloors() {
+		floorTime, err := selectedTime(floor)
+		if err != nil {
+			errTxt := fmt.Sprintf("Reset times were not changed - %s: %v", floor, err)
+			actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+			return actions
+		}
+		if floorTime != lot.ResetTime(floor) {
+			changedFloors[floor] = floorTime
+		}
+	}
+
+	// NOTE: schedules are read on every change so that the ones of a
+	// reloaded config are used
+	schedules := m.data.Schedules()
+	errs := []error{schedules.ValidateWorkspacesReset(defaultTime)}
+	for _, floorTime := range changedFloors {
+		errs = append(errs, schedules.ValidateWorkspacesReset(floorTime))
+	}
+	err = errors.Join(errs...)
+	if err != nil {
+		errTxt := fmt.Sprintf("Reset times were not changed:\n%v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	slog.Info(
+		"Updating workspaces reset times",
+		"requestor", data.UserName,
+		"default", defaultTime,
+		"floors", changedFloors,
+	)
+	lot.ChangeDefaultResetTime(defaultTime, schedules.WorkspacesReset)
+	for floor, floorTime := range changedFloors {
+		lot.SetFloorResetTime(floor, floorTime)
+	}
+	lot.SynchronizeToFile()
+
+	err = m.scheduler.SetDaily(workspaces.ResetWorkspaces, lot.AllResetTimes())
+	if err != nil {
+		errTxt := fmt.Sprintf("Failed to schedule workspaces reset: %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+	}
+
+	return actions
+}
+
+func (m *Manager) handleChannelsSubmission(
+	data *slackApi.ViewSubmission,
+) []event.ResponseAction {
+	var actions []event.ResponseAction
+	lot := m.data.WorkspacesLot
+
+	channelId := data.IValueString(channelSelectBlockId, channelSelectActionId)
+	if channelId == "" {
+		errTxt := "Channels were not changed - no channel selected"
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	if len(data.IValue(channelRemoveBlockId, channelRemoveActionId)) > 0 {
+		slog.Info("Removing workspace channel", "requestor", data.UserName, "channelId", channelId)
+		lot.RemoveChannel(channelId)
+		lot.SynchronizeToFile()
+		return actions
+	}
+
+	var floors []int
+	for _, floorStr := range data.IValue(channelFloorsBlockId, channelFloorsActionId) {
+		floor, err := strconv.Atoi(floorStr)
+		if err != nil {
+			errTxt := fmt.Sprintf("Channels were not changed - invalid floor %q: %v", floorStr, err)
+			actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+			return actions
+		}
+		floors = append(floors, floor)
+	}
+	if len(floors) == 0 {
+		errTxt := "Channels were not changed - no floors selected"
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	defaultFloor := floors[0]
+	defaultFloorStr := data.IValueString(channelDefaultFloorBlockId, channelDefaultFloorActionId)
+	if defaultFloorStr != "" {
+		floor, err := strconv.Atoi(defaultFloorStr)
+		if err != nil || !slices.Contains(floors, floor) {
+			errTxt := fmt.Sprintf(
+				"Channels were not changed - default floor %q is not one of the selected floors",
+				defaultFloorStr,
+			)
+			actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+			return actions
+		}
+		defaultFloor = floor
+	}
+
+	channel := spaces.ChannelFloors{
+		Floors:       floors,
+		DefaultFloor: defaultFloor,
+		Company:      user.Company(data.IValueString(channelTeamBlockId, channelTeamActionId)),
+	}
+	// NOTE: channel name is only known once the command is used in the channel
+	if existing, found := lot.Channel(channelId); found {
+		channel.Name = existing.Name
+	}
+
+	slog.Info(
+		"Updating workspace channel",
+		"requestor", data.UserName,
+		"channelId", channelId,
+		"channel", channel,
+	)
+	lot.SetChannel(channelId, channel)
+	lot.SynchronizeToFile()
+
+	return actions
+}
+
+func (m *Manager) handleNeighbourhoodsSubmission(
+	data *slackApi.ViewSubmission,
+) []event.ResponseAction {
+	var actions []event.ResponseAction
+	lot := m.data.WorkspacesLot
+
+	name := strings.TrimSpace(data.IValueString(neighbourhoodNameBlockId, neighbourhoodNameActionId))
+	if len(data.IValue(neighbourhoodRemoveBlockId, neighbourhoodRemoveActionId)) > 0 {
+		slog.Info("Removing neighbourhood", "requestor", data.UserName, "name", name)
+		lot.RemoveNeighbourhood(name)
+		lot.SynchronizeToFile()
+		return actions
+	}
+
+	var spaceKeys []spaces.SpaceKey
+	for _, space := range data.IValue(neighbourhoodSpacesBlockId, neighbourhoodSpacesActionId) {
+		spaceKeys = append(spaceKeys, spaces.SpaceKey(space))
+	}
+
+	err := lot.SetNeighbourhood(name, spaceKeys)
+	if err != nil {
+		errTxt := fmt.Sprintf("Neighbourhoods were not changed - %v", err)
+		actions = append(actions, m.errorMessageAction(&data.BaseEvent, errTxt))
+		return actions
+	}
+
+	slog.Info(
+		"Updating neighbourhood",
+		"requestor", data.UserName,
+		"name", name,
+		"spaces", spaceKeys,
+	)
+	lot.SynchronizeToFile()
+	return actions
+}
+
 func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Response {
 	var actions []event.ResponseAction
 
@@ -294,6 +507,13 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 	// Reset selected action
 	m.selectedEditOption.ResetSelectionForUser(data.UserId)
 
+	if selectedAction != notSelectedOption &&
+		!m.data.UserManager.HasPermission(data.UserId, user.PermEditWorkspaces) {
+		errTxt := "Nothing was changed - you don't have permission to change these settings"
+		action := common.NewPostAction(data.UserId, errTxt, false)
+		return common.NewResponseEvent(data.UserName, action)
+	}
+
 	switch selectedAction {
 	case removeSpaceOption:
 		actions = append(actions, m.handleRemoveSpaceSubmission(data)...)
@@ -301,6 +521,12 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 		actions = append(actions, m.handleAddSpaceSubmission(data)...)
 	case changePlansOption:
 		actions = append(actions, m.handleChangePlanSubmission(data)...)
+	case changeResetOption:
+		actions = append(actions, m.handleChangeResetSubmission(data)...)
+	case channelsOption:
+		actions = append(actions, m.handleChannelsSubmission(data)...)
+	case neighbourhoodsOption:
+		actions = append(actions, m.handleNeighbourhoodsSubmission(data)...)
 	case notSelectedOption:
 		return nil // do nothing
 	default:
@@ -0,0 +1,234 @@
+package event
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+)
+
+// cronField Set of allowed values for one field of a cron expression
+type cronField struct {
+	values map[int]bool
+	// any is true if the field was specified as `*`
+	any bool
+}
+
+func (f cronField) matches(v int) bool {
+	return f.values[v]
+}
+
+// CronSchedule Parsed standard 5 field cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Each field supports `*`, single values (`5`), ranges (`1-5`), lists
+// (`0,30`) and steps (`*/15`, `8-18/2`). Day of week is 0-6 starting from
+// Sunday (7 is also accepted as Sunday). For example `0 17 * * 1-5` means
+// every working day (Mon-Fri) at 17:00.
+type CronSchedule struct {
+	Spec   string
+	minute cronField
+	hour   cronField
+	dom    cronField
+	month  cronField
+	dow    cronField
+}
+
+func ParseCron(spec string) (*CronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"cron expression %q must have 5 fields (min hour dom month dow) but has %d",
+			spec,
+			len(fields),
+		)
+	}
+
+	limits := []struct {
+		name     string
+		min, max int
+	}{
+		{"minute", 0, 59},
+		{"hour", 0, 23},
+		{"day of month", 1, 31},
+		{"month", 1, 12},
+		{"day of week", 0, 7},
+	}
+
+	parsed := make([]cronField, 5)
+	for i, field := range fields {
+		f, err := parseCronField(field, limits[i].min, limits[i].max)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid %s field in cron expression %q: %w",
+				limits[i].name,
+				spec,
+				err,
+			)
+		}
+		parsed[i] = f
+	}
+
+	// Sunday can be written as either 0 or 7
+	if parsed[4].values[7] {
+		parsed[4].values[0] = true
+	}
+
+	return &CronSchedule{
+		Spec:   spec,
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+	}, nil
+}
+
+// ParseSchedule Parses either time of day in "HH:MM" format (daily job) or a
+// cron expression (i.e. `0 17 * * 1-5` for working days only)
+func ParseSchedule(spec string) (*CronSchedule, error) {
+	if !strings.Contains(spec, ":") {
+		return ParseCron(spec)
+	}
+
+	timeOfDay, err := calendar.ParseTimeOfDay(spec)
+	if err != nil {
+		return nil, err
+	}
+	return DailySchedule(timeOfDay), nil
+}
+
+// DailySchedule Schedule which matches every day at the given time
+func DailySchedule(t calendar.TimeOfDay) *CronSchedule {
+	schedule, err := ParseCron(fmt.Sprintf("%d %d * * *", t.Min, t.Hour))
+	if err != nil {
+		// NOTE: This should never happen
+		panic(err)
+	}
+	return schedule
+}
+
+func parseCronField(field string, min, max int) (cronField, error) {
+	out := cronField{values: map[int]bool{}, any: field == "*"}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepStr, found := strings.Cut(part, "/"); found {
+			s, err := strconv.Atoi(stepStr)
+			if err != nil || s <= 0 {
+				return out, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = s
+			part = rangePart
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+			// full range
+		case strings.Contains(part, "-"):
+			startStr, endStr, _ := strings.Cut(part, "-")
+			var err error
+			start, err = strconv.Atoi(startStr)
+			if err != nil {
+				return out, fmt.Errorf("invalid range start %q", startStr)
+			}
+			end, err = strconv.Atoi(endStr)
+			if err != nil {
+				return out, fmt.Errorf("invalid range end %q", endStr)
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return out, fmt.Errorf("invalid value %q", part)
+			}
+			start, end = v, v
+		}
+
+		if start < min || end > max || start > end {
+			return out, fmt.Errorf("value out of range [%d, %d]: %q", min, max, part)
+		}
+
+		for v := start; v <= end; v += step {
+			out.values[v] = true
+		}
+	}
+	return out, nil
+}
+
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := c.dom.matches(t.Day())
+	dowMatch := c.dow.matches(int(t.Weekday()))
+
+	// NOTE: standard cron behaviour - if both day of month & day of week are
+	// restricted then a day matches if either of them matches.
+	if !c.dom.any && !c.dow.any {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Next Returns the first time strictly after `after` which matches the
+// schedule. The returned time is in the location of `after`.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	loc := t.Location()
+
+	// NOTE: 5 years is more than enough to find a match for any valid
+	// expression (i.e. 29th of Feb on a Monday)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !c.month.matches(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.hour.matches(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.minute.matches(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// TimesOfDay Returns all times of day at which the schedule matches (on the
+// days it matches) sorted from the earliest
+func (c *CronSchedule) TimesOfDay() []calendar.TimeOfDay {
+	var times []calendar.TimeOfDay
+	for hour := range c.hour.values {
+		for min := range c.minute.values {
+			times = append(times, calendar.TimeOfDay{Hour: hour, Min: min})
+		}
+	}
+	slices.SortFunc(times, func(a, b calendar.TimeOfDay) int {
+		if a.Before(b) {
+			return -1
+		} else if b.Before(a) {
+			return 1
+		}
+		return 0
+	})
+	return times
+}
+
+func (c *CronSchedule) String() string {
+	return c.Spec
+}
@@ -17,6 +17,9 @@ const (
 	UpdateView
 	PostEphemeral
 	Post
+	UploadFile
+	UpdateMessage
+	PublishHome
 )
 
 var ResponseActionNames = map[ResponseActionType]string{
@@ -25,6 +28,9 @@ var ResponseActionNames = map[ResponseActionType]string{
 	UpdateView:    "UpdateView",
 	PostEphemeral: "PostEphemeral",
 	Post:          "Post",
+	UploadFile:    "UploadFile",
+	UpdateMessage: "UpdateMessage",
+	PublishHome:   "PublishHome",
 }
 
 type ResponseAction interface {
@@ -47,6 +53,7 @@ const (
 	BlockActionEvent
 	TimerEvent
 	ResponseEvent
+	AppHomeOpenedEvent
 	AnyEvent
 )
 
@@ -60,6 +67,7 @@ var EventNames = map[EventType]string{
 	BlockActionEvent:    "BlockAction",
 	TimerEvent:          "TimerEvent",
 	ResponseEvent:       "ResponseEvent",
+	AppHomeOpenedEvent:  "AppHomeOpened",
 	AnyEvent:            "AnyEvent",
 }
 
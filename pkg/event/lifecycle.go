@@ -0,0 +1,65 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Component A long running part of the bot (slack listener, timers, managers
+// etc.). Start is called once before any events are processed and Stop is
+// called once on shutdown. Stop should flush any state owned by the component
+// (i.e. write data to file) and return once it is done or ctx expires.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+type namedComponent struct {
+	name      string
+	component Component
+}
+
+// Lifecycle Starts components in the order they were added and stops them in
+// reverse order.
+type Lifecycle struct {
+	components []namedComponent
+}
+
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Add(name string, component Component) {
+	l.components = append(l.components, namedComponent{
+		name:      name,
+		component: component,
+	})
+}
+
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, c := range l.components {
+		slog.Info("LIFECYCLE: starting", "component", c.name)
+		err := c.component.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start %s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// Stop Stops all components in reverse order. All components are stopped even
+// if some of them fail, the returned error contains all failures.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(l.components) - 1; i >= 0; i-- {
+		c := l.components[i]
+		slog.Info("LIFECYCLE: stopping", "component", c.name)
+		err := c.component.Stop(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
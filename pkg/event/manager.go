@@ -1,8 +1,11 @@
 package event
 
 import (
+	"context"
 	"log"
+	"log/slog"
 	"slices"
+	"sync"
 )
 
 type Consumer interface {
@@ -27,12 +30,24 @@ func (c *ConsumerNoContext) Context() string {
 type EventManager struct {
 	events      chan Event
 	subscribers map[EventType][]ConsumerWithContext
+
+	// done is closed once the event loop has exited. After that events
+	// are no longer dispatched.
+	done chan struct{}
+	// stopLoop Cancels the event loop. The loop is not stopped by the ctx
+	// passed to Start so that responses of in-flight events are still
+	// delivered during shutdown.
+	stopLoop context.CancelFunc
+	// inFlight tracks published events that are not dispatched yet &
+	// consumers that are currently processing an event
+	inFlight sync.WaitGroup
 }
 
 func NewEventManager() *EventManager {
 	return &EventManager{
 		events:      make(chan Event),
 		subscribers: map[EventType][]ConsumerWithContext{},
+		done:        make(chan struct{}),
 	}
 }
 
@@ -67,13 +82,69 @@ func (em *EventManager) SubscribeWithContext(
 	em.subscribe(consumer, eventTypes...)
 }
 
+// Publish Sends event to the event loop. If the event loop is already stopped
+// the event is dropped.
 func (em *EventManager) Publish(event Event) {
-	em.events <- event
+	// NOTE: the event is in-flight until it is dispatched so that events
+	// published by in-flight consumers (i.e. responses) are waited for too
+	em.inFlight.Add(1)
+	select {
+	case em.events <- event:
+	case <-em.done:
+		em.inFlight.Done()
+		slog.Warn("Event manager stopped. Dropping event", "evt", EventName(event))
+	}
+}
+
+// Start Starts the event loop. It runs until Stop is called regardless of
+// ctx.
+func (em *EventManager) Start(ctx context.Context) error {
+	loopCtx, stopLoop := context.WithCancel(context.Background())
+	em.stopLoop = stopLoop
+	go em.ManageEvents(loopCtx)
+	return nil
+}
+
+// Stop Waits for all in-flight events (including the ones they publish) to
+// be processed by their consumers & stops the event loop afterwards. It
+// should be called once event producers (slack client, scheduler) stopped.
+func (em *EventManager) Stop(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		em.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if em.stopLoop != nil {
+		em.stopLoop()
+	}
+	select {
+	case <-em.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (em *EventManager) ManageEvents() {
+// ManageEvents Dispatches events to subscribers until ctx is cancelled
+func (em *EventManager) ManageEvents(ctx context.Context) {
+	defer close(em.done)
+
 	for {
-		event := <-em.events
+		var event Event
+		select {
+		case event = <-em.events:
+		case <-ctx.Done():
+			slog.Info("Event manager stopped")
+			return
+		}
+
 		// Send events to subscribers that listen to a specific event
 		eventType := event.Type()
 		subs, ok := em.subscribers[eventType]
@@ -83,7 +154,7 @@ func (em *EventManager) ManageEvents() {
 				// this context and only then forward it to the subscriber
 				// if subscribed without context -> forward to subscriber
 				if MatchesContext(event, sub) {
-					go sub.Consume(event)
+					em.consume(sub, event)
 				}
 			}
 		}
@@ -93,13 +164,24 @@ func (em *EventManager) ManageEvents() {
 		if ok {
 			for _, sub := range subs {
 				if MatchesContext(event, sub) {
-					go sub.Consume(event)
+					em.consume(sub, event)
 				}
 			}
 		}
+
+		// NOTE: consumers are already counted as in-flight
+		em.inFlight.Done()
 	}
 }
 
+func (em *EventManager) consume(sub ConsumerWithContext, event Event) {
+	em.inFlight.Add(1)
+	go func() {
+		defer em.inFlight.Done()
+		sub.Consume(event)
+	}()
+}
+
 func MatchesContext(event Event, sub ConsumerWithContext) bool {
 	return (sub.Context() != "" && event.HasContext(sub.Context())) || sub.Context() == ""
 }
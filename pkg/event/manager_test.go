@@ -0,0 +1,79 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	eventType EventType
+}
+
+func (e *testEvent) Type() EventType                { return e.eventType }
+func (e *testEvent) User() string                   { return "" }
+func (e *testEvent) Info() map[string]any           { return nil }
+func (e *testEvent) HasContext(context string) bool { return false }
+
+func TestStopDeliversResponsesOfInFlightEvents(t *testing.T) {
+	em := NewEventManager()
+
+	handling := make(chan struct{})
+	finish := make(chan struct{})
+	em.Subscribe(consumerFunc(func(e Event) {
+		close(handling)
+		<-finish
+		em.Publish(&testEvent{ResponseEvent})
+	}), SlashCmdEvent)
+
+	delivered := make(chan struct{}, 1)
+	em.Subscribe(consumerFunc(func(e Event) {
+		// NOTE: slow response consumer must be waited for as well
+		time.Sleep(10 * time.Millisecond)
+		delivered <- struct{}{}
+	}), ResponseEvent)
+
+	em.Start(context.Background())
+	em.Publish(&testEvent{SlashCmdEvent})
+	<-handling
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- em.Stop(context.Background())
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("expected stop to wait for the in-flight event")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(finish)
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-delivered:
+	default:
+		t.Fatal("expected response of the in-flight event to be delivered before stop returned")
+	}
+
+	// events published after stop are dropped
+	em.Publish(&testEvent{ResponseEvent})
+}
+
+func TestStopRespectsDeadline(t *testing.T) {
+	em := NewEventManager()
+	finish := make(chan struct{})
+	t.Cleanup(func() { close(finish) })
+	em.Subscribe(consumerFunc(func(e Event) { <-finish }), SlashCmdEvent)
+
+	em.Start(context.Background())
+	em.Publish(&testEvent{SlashCmdEvent})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := em.Stop(ctx); err == nil {
+		t.Fatal("expected error when in-flight event doesn't finish before the deadline")
+	}
+}
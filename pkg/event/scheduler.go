@@ -0,0 +1,330 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
+)
+
+// tickInterval How often the scheduler checks for due jobs
+const tickInterval = 20 * time.Second
+
+type TimerDone struct {
+	Label string
+	// Time The time at which the event was published
+	Time time.Time
+	// Scheduled The time at which the job was supposed to run. It is
+	// different from Time if the run was missed (i.e. bot was down) and
+	// is now being caught up.
+	Scheduled time.Time
+	CatchUp   bool
+}
+
+func (t *TimerDone) Type() EventType {
+	return TimerEvent
+}
+
+func (t *TimerDone) Info() map[string]any {
+	return map[string]any{
+		"label":     t.Label,
+		"scheduled": t.Scheduled.Format("2006-01-02 15:04"),
+		"catchUp":   t.CatchUp,
+	}
+}
+
+func (t *TimerDone) User() string {
+	return ""
+}
+
+func (t *TimerDone) HasContext(c string) bool {
+	return true
+}
+
+type job struct {
+	label    string
+	schedule *CronSchedule
+}
+
+// key Uniquely identifies a job. The same label can be scheduled multiple
+// times per day (i.e. HCM checks) so label alone is not enough.
+func (j job) key() string {
+	return fmt.Sprintf("%s @ %s", j.label, j.schedule.Spec)
+}
+
+// Scheduler Publishes TimerDone events according to cron schedules.
+// Last run time of every job is persisted to file so that runs missed while
+// the bot was down (or stalled) are caught up on the next tick.
+type Scheduler struct {
+	eventManager  *EventManager
+	clock         clock.Clock
+	location      *time.Location
+	stateFilename string
+
+	mu      sync.Mutex
+	jobs    []job
+	lastRun map[string]time.Time
+
+	wg sync.WaitGroup
+}
+
+func NewScheduler(
+	eventManager *EventManager,
+	clk clock.Clock,
+	location *time.Location,
+	stateFilename string,
+) *Scheduler {
+	if location == nil {
+		location = time.Local
+	}
+	return &Scheduler{
+		eventManager:  eventManager,
+		clock:         clk,
+		location:      location,
+		stateFilename: stateFilename,
+		lastRun:       map[string]time.Time{},
+	}
+}
+
+// SetDaily Replaces all jobs with the given label by daily jobs at the given
+// times (see SetCron)
+func (s *Scheduler) SetDaily(label string, times []calendar.TimeOfDay) error {
+	var schedules []*CronSchedule
+	for _, t := range times {
+		schedules = append(schedules, DailySchedule(t))
+	}
+	return s.SetCron(label, schedules)
+}
+
+// SetCron Replaces all jobs with the given label by jobs with the given
+// schedules. It is safe to call while the scheduler is running (i.e. when an
+// admin changes reset times). Newly added schedules are not caught up.
+// NOTE: handlers skip non-working days themselves (see calendar.Calendar)
+// which also covers public holidays. Cron expressions are only needed to
+// restrict jobs further (i.e. weekdays only).
+func (s *Scheduler) SetCron(label string, schedules []*CronSchedule) error {
+	var newJobs []job
+	for _, schedule := range schedules {
+		if schedule == nil {
+			return fmt.Errorf("missing schedule of %s", label)
+		}
+		newJobs = append(newJobs, job{label: label, schedule: schedule})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// NOTE: jobs that are scheduled at the same time as before keep their
+	// last run so that a run which is due is not skipped
+	previousRuns := map[string]time.Time{}
+	s.jobs = slices.DeleteFunc(s.jobs, func(j job) bool {
+		if j.label != label {
+			return false
+		}
+		previousRuns[j.key()] = s.lastRun[j.key()]
+		delete(s.lastRun, j.key())
+		return true
+	})
+
+	now := s.clock.Now()
+	for _, j := range newJobs {
+		lastRun, found := previousRuns[j.key()]
+		if !found || lastRun.IsZero() {
+			lastRun = now
+		}
+		s.lastRun[j.key()] = lastRun
+	}
+	s.jobs = append(s.jobs, newJobs...)
+
+	slog.Info("SCHEDULER: jobs updated", "label", label, "schedules", schedules)
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) error {
+	err := s.loadState()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	now := s.clock.Now()
+	jobKeys := map[string]bool{}
+	for _, j := range s.jobs {
+		jobKeys[j.key()] = true
+		// NOTE: jobs that never ran before start counting from now, otherwise
+		// a brand new job would be caught up immediately
+		if _, found := s.lastRun[j.key()]; !found {
+			s.lastRun[j.key()] = now
+		}
+	}
+	// NOTE: forget jobs which are no longer scheduled (i.e. reset time was
+	// changed) so they are not caught up if they get scheduled again later
+	for key := range s.lastRun {
+		if !jobKeys[key] {
+			delete(s.lastRun, key)
+		}
+	}
+	s.mu.Unlock()
+
+	// Catch up anything that was missed while the bot was down
+	s.Tick()
+
+	s.wg.Add(1)
+	go s.run(ctx)
+	return nil
+}
+
+func (s *Scheduler) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return s.SynchronizeToFile()
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Tick()
+		}
+	}
+}
+
+// Tick Publishes events for all jobs that are due at the current clock time.
+// If a job missed several runs (i.e. bot was down for days) it is only
+// published once. Due jobs are published in the order of their scheduled time.
+func (s *Scheduler) Tick() {
+	s.mu.Lock()
+	now := s.clock.Now().In(s.location)
+
+	var due []*TimerDone
+	for _, j := range s.jobs {
+		lastRun := s.lastRun[j.key()].In(s.location)
+		next := j.schedule.Next(lastRun)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		// Find the latest missed run so that the event refers to it
+		scheduled := next
+		for {
+			n := j.schedule.Next(scheduled)
+			if n.IsZero() || n.After(now) {
+				break
+			}
+			scheduled = n
+		}
+
+		catchUp := now.Sub(scheduled) >= time.Minute
+		if catchUp {
+			slog.Warn(
+				"SCHEDULER: catching up missed run",
+				"label", j.label,
+				"schedule", j.schedule.Spec,
+				"scheduled", scheduled,
+				"lastRun", lastRun,
+			)
+		}
+
+		due = append(due, &TimerDone{
+			Label:     j.label,
+			Time:      now,
+			Scheduled: scheduled,
+			CatchUp:   catchUp,
+		})
+		s.lastRun[j.key()] = now
+	}
+	s.mu.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(due, func(a, b *TimerDone) int {
+		return a.Scheduled.Compare(b.Scheduled)
+	})
+
+	err := s.SynchronizeToFile()
+	if err != nil {
+		slog.Error("SCHEDULER: failed to persist state", "err", err)
+	}
+
+	for _, e := range due {
+		s.eventManager.Publish(e)
+	}
+}
+
+func (s *Scheduler) loadState() error {
+	if s.stateFilename == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(s.stateFilename)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Info("SCHEDULER: no state file found", "file", s.stateFilename)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read scheduler state file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = json.Unmarshal(b, &s.lastRun)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to parse scheduler state file %q: %w",
+			s.stateFilename,
+			err,
+		)
+	}
+	slog.Info("SCHEDULER: loaded state", "file", s.stateFilename, "jobs", len(s.lastRun))
+	return nil
+}
+
+// SynchronizeToFile Writes last run time of every job to file
+func (s *Scheduler) SynchronizeToFile() error {
+	if s.stateFilename == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.lastRun, "", "\t")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduler state: %w", err)
+	}
+
+	err = os.WriteFile(s.stateFilename, data, 0o666)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to write scheduler state file (%s): %w",
+			s.stateFilename,
+			err,
+		)
+	}
+	return nil
+}
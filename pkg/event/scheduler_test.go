@@ -0,0 +1,203 @@
+package event
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
+)
+
+func TestCronNext(t *testing.T) {
+	loc := time.UTC
+	// 2024-03-01 is a Friday
+	friday := time.Date(2024, 3, 1, 16, 0, 0, 0, loc)
+
+	tests := []struct {
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		{"0 17 * * *", friday, time.Date(2024, 3, 1, 17, 0, 0, 0, loc)},
+		{"0 17 * * *", friday.Add(time.Hour), time.Date(2024, 3, 2, 17, 0, 0, 0, loc)},
+		{"0 17 * * 1-5", friday.Add(time.Hour), time.Date(2024, 3, 4, 17, 0, 0, 0, loc)},
+		{"*/15 9 * * *", friday, time.Date(2024, 3, 2, 9, 0, 0, 0, loc)},
+		{"30 6,8 * * *", friday, time.Date(2024, 3, 2, 6, 30, 0, 0, loc)},
+		{"0 0 29 2 *", friday, time.Date(2028, 2, 29, 0, 0, 0, 0, loc)},
+		{"0 12 * * 7", friday, time.Date(2024, 3, 3, 12, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.spec, err)
+		}
+		got := schedule.Next(tt.after)
+		if !got.Equal(tt.want) {
+			t.Errorf("Next(%q, %v) = %v; want %v", tt.spec, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{"", "0 17 * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) expected error", spec)
+		}
+	}
+}
+
+// collect Starts the event loop and returns a function that returns all
+// timer events published since the last call.
+func collect(t *testing.T, em *EventManager) func() []*TimerDone {
+	t.Helper()
+	events := make(chan *TimerDone, 100)
+	em.Subscribe(consumerFunc(func(e Event) {
+		events <- e.(*TimerDone)
+	}), TimerEvent)
+
+	em.Start(context.Background())
+	t.Cleanup(func() { em.Stop(context.Background()) })
+
+	return func() []*TimerDone {
+		var out []*TimerDone
+		for {
+			select {
+			case e := <-events:
+				out = append(out, e)
+			case <-time.After(50 * time.Millisecond):
+				return out
+			}
+		}
+	}
+}
+
+type consumerFunc func(Event)
+
+func (f consumerFunc) Consume(e Event) {
+	f(e)
+}
+
+func TestSchedulerFastForward(t *testing.T) {
+	loc := time.UTC
+	clk := clock.NewFake(time.Date(2024, 3, 1, 8, 0, 0, 0, loc)) // Friday
+	em := NewEventManager()
+	published := collect(t, em)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	s := NewScheduler(em, clk, loc, "")
+	s.SetDaily("daily", []calendar.TimeOfDay{{Hour: 17}})
+	s.SetDaily("twice", []calendar.TimeOfDay{{Hour: 8}, {Hour: 16, Min: 45}})
+	weekdays, err := ParseSchedule("0 17 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetCron("weekdays", []*CronSchedule{weekdays})
+	s.Start(ctx)
+
+	var daily, twice, weekdaysRuns int
+	for range 7 * 24 * 60 {
+		clk.Advance(time.Minute)
+		s.Tick()
+	}
+	for _, e := range published() {
+		if e.CatchUp {
+			t.Errorf("unexpected catch up: %v", e.Info())
+		}
+		switch e.Label {
+		case "daily":
+			daily++
+		case "twice":
+			twice++
+		case "weekdays":
+			if day := e.Scheduled.Weekday(); day == time.Saturday || day == time.Sunday {
+				t.Errorf("weekdays job ran on %v", day)
+			}
+			weekdaysRuns++
+		}
+	}
+
+	if daily != 7 {
+		t.Errorf("daily ran %d times; want 7", daily)
+	}
+	if twice != 14 {
+		t.Errorf("twice ran %d times; want 14", twice)
+	}
+	if weekdaysRuns != 5 {
+		t.Errorf("weekdays ran %d times; want 5", weekdaysRuns)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	daily, err := ParseSchedule("9:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: same spec as before cron expressions could be configured so that
+	// persisted last runs are still found
+	if daily.Spec != "30 9 * * *" {
+		t.Errorf("got spec %q; want daily spec", daily.Spec)
+	}
+
+	cron, err := ParseSchedule("0,30 8-9 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []calendar.TimeOfDay{{Hour: 8}, {Hour: 8, Min: 30}, {Hour: 9}, {Hour: 9, Min: 30}}
+	if got := cron.TimesOfDay(); !slices.Equal(got, want) {
+		t.Errorf("got times %v; want %v", got, want)
+	}
+
+	for _, spec := range []string{"25:00", "0 17 * *"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestSchedulerCatchUpAfterRestart(t *testing.T) {
+	loc := time.UTC
+	stateFile := filepath.Join(t.TempDir(), "scheduler.json")
+	clk := clock.NewFake(time.Date(2024, 3, 1, 16, 0, 0, 0, loc))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	em := NewEventManager()
+	s := NewScheduler(em, clk, loc, stateFile)
+	s.SetDaily("reset", []calendar.TimeOfDay{{Hour: 17}})
+	s.Start(ctx)
+	cancel()
+	s.Stop(context.Background())
+
+	// Bot was down over 17:00 for 2 days
+	clk.Set(time.Date(2024, 3, 3, 9, 0, 0, 0, loc))
+
+	em = NewEventManager()
+	published := collect(t, em)
+	ctx, cancel = context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s = NewScheduler(em, clk, loc, stateFile)
+	s.SetDaily("reset", []calendar.TimeOfDay{{Hour: 17}})
+	s.Start(ctx)
+
+	events := published()
+	if len(events) != 1 {
+		t.Fatalf("expected exactly 1 catch up event but got %d", len(events))
+	}
+	e := events[0]
+	wantScheduled := time.Date(2024, 3, 2, 17, 0, 0, 0, loc)
+	if !e.CatchUp || !e.Scheduled.Equal(wantScheduled) {
+		t.Errorf("got catchUp=%v scheduled=%v; want catch up of %v", e.CatchUp, e.Scheduled, wantScheduled)
+	}
+
+	// Nothing else is due until the next 17:00
+	clk.Set(time.Date(2024, 3, 3, 16, 59, 0, 0, loc))
+	s.Tick()
+	if events := published(); len(events) != 0 {
+		t.Errorf("expected no events but got %d", len(events))
+	}
+}
@@ -0,0 +1,127 @@
+package floor_map
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+const (
+	cellWidth  = 48
+	cellHeight = 32
+	cellGap    = 6
+	margin     = 10
+
+	// digitScale Size of a font pixel in image pixels
+	digitScale   = 3
+	digitWidth   = 3
+	digitHeight  = 5
+	digitSpacing = 1
+)
+
+var (
+	backgroundColor = color.RGBA{0xf2, 0xf2, 0xf2, 0xff}
+	textColor       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	// NOTE: colors match the status emojis shown in the modals
+	stateColors = map[spaces.MapSpaceState]color.RGBA{
+		spaces.MapSpaceFree:  {0x2e, 0xb6, 0x7d, 0xff},
+		spaces.MapSpaceTaken: {0xf4, 0x90, 0x0c, 0xff},
+		spaces.MapSpaceMine:  {0x1d, 0x6f, 0xd8, 0xff},
+	}
+)
+
+// digitFont 3x5 bitmap font of digits. Each row is a bitmask where the
+// highest of the 3 bits is the leftmost pixel.
+var digitFont = [10][digitHeight]uint8{
+	{0b111, 0b101, 0b101, 0b101, 0b111},
+	{0b010, 0b110, 0b010, 0b010, 0b111},
+	{0b111, 0b001, 0b111, 0b100, 0b111},
+	{0b111, 0b001, 0b111, 0b001, 0b111},
+	{0b101, 0b101, 0b111, 0b001, 0b001},
+	{0b111, 0b100, 0b111, 0b001, 0b111},
+	{0b111, 0b100, 0b111, 0b101, 0b111},
+	{0b111, 0b001, 0b010, 0b010, 0b010},
+	{0b111, 0b101, 0b111, 0b101, 0b111},
+	{0b111, 0b101, 0b111, 0b001, 0b111},
+}
+
+// cellRect Returns the area of the grid cell in the image
+func cellRect(position spaces.MapPosition) image.Rectangle {
+	x := margin + position.X*(cellWidth+cellGap)
+	y := margin + position.Y*(cellHeight+cellGap)
+	return image.Rect(x, y, x+cellWidth, y+cellHeight)
+}
+
+// Render Draws spaces as colored cells of a grid labeled with space numbers
+// & returns the image encoded as PNG
+func Render(mapSpaces spaces.MapSpaces) ([]byte, error) {
+	columns, rows := 1, 1
+	for _, space := range mapSpaces {
+		if space.Position.X < 0 || space.Position.Y < 0 {
+			return nil, fmt.Errorf("space %d has negative map position", space.Number)
+		}
+		columns = max(columns, space.Position.X+1)
+		rows = max(rows, space.Position.Y+1)
+	}
+
+	img := image.NewRGBA(image.Rect(
+		0,
+		0,
+		2*margin+columns*cellWidth+(columns-1)*cellGap,
+		2*margin+rows*cellHeight+(rows-1)*cellGap,
+	))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	for _, space := range mapSpaces {
+		cell := cellRect(space.Position)
+		draw.Draw(img, cell, image.NewUniform(stateColors[space.State]), image.Point{}, draw.Src)
+		drawNumber(img, cell, space.Number)
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode floor map: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawNumber Draws the number centered in the cell. Characters other than
+// digits (i.e. minus sign) are skipped.
+func drawNumber(img *image.RGBA, cell image.Rectangle, number int) {
+	var digits []int
+	for _, char := range strconv.Itoa(number) {
+		if '0' <= char && char <= '9' {
+			digits = append(digits, int(char-'0'))
+		}
+	}
+
+	width := (len(digits)*(digitWidth+digitSpacing) - digitSpacing) * digitScale
+	height := digitHeight * digitScale
+	x := cell.Min.X + (cell.Dx()-width)/2
+	y := cell.Min.Y + (cell.Dy()-height)/2
+
+	for _, digit := range digits {
+		for row, bits := range digitFont[digit] {
+			for col := 0; col < digitWidth; col++ {
+				if bits&(1<<(digitWidth-1-col)) == 0 {
+					continue
+				}
+				pixel := image.Rect(
+					x+col*digitScale,
+					y+row*digitScale,
+					x+(col+1)*digitScale,
+					y+(row+1)*digitScale,
+				)
+				draw.Draw(img, pixel, image.NewUniform(textColor), image.Point{}, draw.Src)
+			}
+		}
+		x += (digitWidth + digitSpacing) * digitScale
+	}
+}
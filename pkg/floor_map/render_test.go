@@ -0,0 +1,51 @@
+package floor_map
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+func TestRender(t *testing.T) {
+	mapSpaces := spaces.MapSpaces{
+		{Number: 101, Position: spaces.MapPosition{X: 0, Y: 0}, State: spaces.MapSpaceFree},
+		{Number: 102, Position: spaces.MapPosition{X: 2, Y: 0}, State: spaces.MapSpaceTaken},
+		{Number: 103, Position: spaces.MapPosition{X: 1, Y: 1}, State: spaces.MapSpaceMine},
+	}
+
+	data, err := Render(mapSpaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected valid png: %v", err)
+	}
+
+	bounds := img.Bounds()
+	expectedWidth := 2*margin + 3*cellWidth + 2*cellGap
+	expectedHeight := 2*margin + 2*cellHeight + cellGap
+	if bounds.Dx() != expectedWidth || bounds.Dy() != expectedHeight {
+		t.Fatalf(
+			"expected %dx%d image, got %dx%d",
+			expectedWidth, expectedHeight, bounds.Dx(), bounds.Dy(),
+		)
+	}
+
+	for _, space := range mapSpaces {
+		// NOTE: corner of the cell is never covered by the number
+		corner := cellRect(space.Position).Min
+		got := color.RGBAModel.Convert(img.At(corner.X, corner.Y))
+		if got != stateColors[space.State] {
+			t.Fatalf("space %d: expected color %v, got %v", space.Number, stateColors[space.State], got)
+		}
+	}
+
+	emptyCell := cellRect(spaces.MapPosition{X: 0, Y: 1}).Min
+	if got := color.RGBAModel.Convert(img.At(emptyCell.X, emptyCell.Y)); got != backgroundColor {
+		t.Fatalf("expected background in empty cell, got %v", got)
+	}
+}
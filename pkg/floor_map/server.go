@@ -0,0 +1,202 @@
+package floor_map
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+const (
+	// MaxGridSize Max number of columns & rows of a floor map
+	MaxGridSize = 50
+
+	parkingPath = "/maps/parking/"
+	// maxCachedMaps Rendered maps are dropped once there are more of them
+	// (old versions are not requested anymore)
+	maxCachedMaps = 200
+)
+
+// Server Serves rendered floor maps over HTTP so that slack can show them
+// in image blocks. Maps are rendered on request & cached by their version,
+// therefore a map is only rendered again once the state of its spaces
+// changes.
+type Server struct {
+	data   *model.Data
+	listen string
+	secret string
+	server *http.Server
+
+	mu    sync.Mutex
+	cache map[string][]byte
+}
+
+func NewServer(data *model.Data, conf *config.Config) *Server {
+	return &Server{
+		data:   data,
+		listen: conf.FloorMapListen,
+		secret: conf.FloorMapSecret,
+		cache:  map[string][]byte{},
+	}
+}
+
+// Links Creates URLs of floor maps. The zero value means floor maps are
+// disabled.
+type Links struct {
+	publicUrl string
+	secret    string
+}
+
+func NewLinks(conf *config.Config) Links {
+	return Links{publicUrl: conf.FloorMapUrl, secret: conf.FloorMapSecret}
+}
+
+// ParkingMap Returns URL of the parking floor map as seen by the user or
+// empty string if floor maps are disabled or no space of the floor has a map
+// position. The URL contains the map version so that slack fetches the map
+// again whenever it changes.
+// NOTE: maps are served to the internet & show the space of the user,
+// therefore the URL is signed so that it can't be made up for other users
+func (l Links) ParkingMap(lot *spaces.SpacesLot, floor, userId string) string {
+	if l.publicUrl == "" || !lot.HasFloorMap(floor) {
+		return ""
+	}
+
+	version := lot.FloorMap(userId, floor).Version()
+	query := url.Values{
+		"user": {userId},
+		"v":    {version},
+		"sig":  {sign(l.secret, floor, userId, version)},
+	}
+	return fmt.Sprintf(
+		"%s%s%s.png?%s",
+		strings.TrimSuffix(l.publicUrl, "/"),
+		parkingPath,
+		url.PathEscape(floor),
+		query.Encode(),
+	)
+}
+
+// sign Returns HMAC of the map URL parameters
+func sign(secret, floor, userId, version string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	// NOTE: parameters are separated so that they can't be shifted between
+	// each other (i.e. floor "1" & user "2" vs floor "12" & no user)
+	fmt.Fprintf(mac, "%s\n%s\n%s", floor, userId, version)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validSignature Checks that the map URL was created by Links with the same
+// secret
+func validSignature(secret, floor, userId, version, signature string) bool {
+	expected := sign(secret, floor, userId, version)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	if s.listen == "" {
+		slog.Info("Floor map server is disabled")
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.listen, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+parkingPath+"{file}", s.handleParkingMap)
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		err := s.server.Serve(listener)
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Floor map server failed", "err", err)
+		}
+	}()
+	slog.Info("Floor map server started", "address", listener.Addr())
+	return nil
+}
+
+// Stop Waits for in-flight requests to finish
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleParkingMap(w http.ResponseWriter, r *http.Request) {
+	floor, found := strings.CutSuffix(r.PathValue("file"), ".png")
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.URL.Query()
+	userId := query.Get("user")
+	if !validSignature(s.secret, floor, userId, query.Get("v"), query.Get("sig")) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	s.data.Lock()
+	mapSpaces := s.data.ParkingLot.FloorMap(userId, floor)
+	s.data.Unlock()
+
+	if len(mapSpaces) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	img, err := s.render(mapSpaces)
+	if err != nil {
+		slog.Error("Failed to render floor map", "floor", floor, "err", err)
+		http.Error(w, "failed to render floor map", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	// NOTE: map is requested with its version in the URL, therefore a
+	// cached map is never outdated for long
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Write(img)
+}
+
+// render Returns the cached map of the same version or renders a new one
+func (s *Server) render(mapSpaces spaces.MapSpaces) ([]byte, error) {
+	version := mapSpaces.Version()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if img, found := s.cache[version]; found {
+		return img, nil
+	}
+
+	img, err := Render(mapSpaces)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.cache) >= maxCachedMaps {
+		clear(s.cache)
+	}
+	s.cache[version] = img
+	return img, nil
+}
@@ -0,0 +1,40 @@
+package floor_map
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	sig := sign("secret", "1", "U_OWNER", "v1")
+	if !validSignature("secret", "1", "U_OWNER", "v1", sig) {
+		t.Fatal("expected signature to be valid")
+	}
+
+	for _, tt := range []struct {
+		name                           string
+		secret, floor, userId, version string
+	}{
+		{"other secret", "other", "1", "U_OWNER", "v1"},
+		{"other floor", "secret", "2", "U_OWNER", "v1"},
+		{"other user", "secret", "1", "U_OTHER", "v1"},
+		{"other version", "secret", "1", "U_OWNER", "v2"},
+	} {
+		if validSignature(tt.secret, tt.floor, tt.userId, tt.version, sig) {
+			t.Errorf("%s: expected signature to be invalid", tt.name)
+		}
+	}
+}
+
+func TestUnsignedMapIsRejected(t *testing.T) {
+	s := &Server{secret: "secret"}
+	r := httptest.NewRequest(http.MethodGet, "/maps/parking/1.png?user=U_OWNER&v=v1", nil)
+	r.SetPathValue("file", "1.png")
+	w := httptest.NewRecorder()
+
+	s.handleParkingMap(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
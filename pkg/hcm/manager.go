@@ -1,6 +1,7 @@
 package hcm
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -20,7 +21,9 @@ import (
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
 	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
 	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces"
 )
 
 const (
@@ -28,6 +31,10 @@ const (
 	ListEmployeesEndpoint = "/ext/api/v1/employees"
 	VacationsEndpoint     = "/ext/api/v1/employees/periods?includeRemoteWork=true"
 	BusinessTripsEndpoint = "/ext/api/v1/employees/businesstrips"
+
+	// requestTimeout Max duration of an HCM request (including reading the
+	// response) so that a hung HCM doesn't stall the sync forever
+	requestTimeout = time.Minute
 )
 
 type HcmEmployee struct {
@@ -69,6 +76,9 @@ type Manager struct {
 	reportPersonId  string
 	hcmHashFilename string
 	vacationsHash   common.VacationsHash
+	// notificationThrottle Min time between free space notifications sent
+	// to the same user
+	notificationThrottle time.Duration
 }
 
 func NewManager(
@@ -77,15 +87,16 @@ func NewManager(
 	conf *config.Config,
 ) *Manager {
 	return &Manager{
-		eventManager:    eventManager,
-		data:            data,
-		hcmQdevUrl:      conf.HcmQdevUrl,
-		hcmQuadUrl:      conf.HcmQuadUrl,
-		hcmApiToken:     conf.HcmApiToken,
-		debug:           conf.Debug,
-		reportPersonId:  conf.ReportPersonId,
-		hcmHashFilename: conf.HcmVacationsHashFilename,
-		vacationsHash:   common.LoadVacationsHash(conf.HcmVacationsHashFilename),
+		eventManager:         eventManager,
+		data:                 data,
+		hcmQdevUrl:           conf.HcmQdevUrl,
+		hcmQuadUrl:           conf.HcmQuadUrl,
+		hcmApiToken:          conf.HcmApiToken,
+		debug:                conf.Debug,
+		reportPersonId:       conf.ReportPersonId,
+		hcmHashFilename:      conf.HcmVacationsHashFilename,
+		vacationsHash:        common.LoadVacationsHash(conf.HcmVacationsHashFilename),
+		notificationThrottle: conf.NotificationThrottle,
 	}
 }
 
@@ -110,18 +121,53 @@ func (m *Manager) Context() string {
 	return HandleHcm
 }
 
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Writes processed vacations hashes to file
+func (m *Manager) Stop(ctx context.Context) error {
+	return m.SynchronizeToFile()
+}
+
 func (m *Manager) handleHcm(eventTime time.Time) *common.Response {
 	var actions []event.ResponseAction
 
-	usersWithoutHcmId := m.data.UserManager.UsersWithoutHcmId()
-	if len(usersWithoutHcmId) > 0 {
-		err := m.updateAllEmployeesInfo()
+	// NOTE: HCM is requested without the data lock so that a slow HCM
+	// doesn't block slack interactions. The lock is only held while fetched
+	// data is applied so that users & spaces are not reloaded in the middle
+	// of it.
+	m.data.Lock()
+	employeesNeeded := len(m.data.UserManager.UsersWithoutHcmId()) > 0
+	m.data.Unlock()
+
+	var employees companyEmployees
+	var employeesErr error
+	if employeesNeeded {
+		employees, employeesErr = m.fetchAllEmployees()
+	}
+
+	/* TODO: when we are adding user to the users.json we are taking the username from
+	   slack but that does not always correlate with HCM (like the examples below)
+	   * One solution is to correct the users.json later
+	   * Second is to get email from slack and take the names before @ from There
+	   * Third is to add the username field in the `/users` modal so that admins
+	   can change it later??
+	*/
+	qdevInfo, qdevErr := m.fetchVacations(m.hcmQdevUrl)
+	quadInfo, quadErr := m.fetchVacations(m.hcmQuadUrl)
+
+	m.data.Lock()
+	defer m.data.Unlock()
+
+	if employeesNeeded {
+		err := errors.Join(employeesErr, m.updateAllEmployeesInfo(employees))
 		if err != nil {
 			errTxt := fmt.Sprintf("Error while trying to obtain employee Ids: %v", err)
 			actions = append(actions, m.reportErrorAction(errTxt))
 		}
 
-		usersWithoutHcmId = m.data.UserManager.UsersWithoutHcmId()
+		usersWithoutHcmId := m.data.UserManager.UsersWithoutHcmId()
 		if len(usersWithoutHcmId) > 0 {
 			actions = append(
 				actions,
@@ -135,14 +181,7 @@ func (m *Manager) handleHcm(eventTime time.Time) *common.Response {
 		}
 	}
 
-	/* TODO: when we are adding user to the users.json we are taking the username from
-	   slack but that does not always correlate with HCM (like the examples below)
-	   * One solution is to correct the users.json later
-	   * Second is to get email from slack and take the names before @ from There
-	   * Third is to add the username field in the `/users` modal so that admins
-	   can change it later??
-	*/
-	vacationInfo, err := m.vacationsInfo(m.hcmQdevUrl, user.Qdev)
+	vacationInfo, err := m.vacationsInfo(qdevInfo, qdevErr, user.Qdev)
 	if err != nil {
 		errTxt := fmt.Sprintf(
 			"Error while trying to obtain vacation periods or businesss trips for qdev: %v",
@@ -151,7 +190,7 @@ func (m *Manager) handleHcm(eventTime time.Time) *common.Response {
 		actions = append(actions, m.reportErrorAction(errTxt))
 		return common.NewResponseEvent("HCM", actions...)
 	}
-	quadVacationInfo, err := m.vacationsInfo(m.hcmQuadUrl, user.Quad)
+	quadVacationInfo, err := m.vacationsInfo(quadInfo, quadErr, user.Quad)
 	if err != nil {
 		errTxt := fmt.Sprintf(
 			"Error while trying to obtain vacation periods or business trips for quadigi: %v",
@@ -182,7 +221,8 @@ func (m *Manager) addVacationReleases(
 ) []event.ResponseAction {
 	var actions []event.ResponseAction
 
-	todayDate := common.TodayDate()
+	now := m.data.Clock.Now()
+	todayDate := common.DateOf(now)
 
 	for hcmKey, vacations := range vacationInfo {
 		employee := NewHcmEmployeeFromKey(hcmKey)
@@ -204,8 +244,9 @@ func (m *Manager) addVacationReleases(
 			release := m.data.ParkingLot.ToBeReleased.Add(
 				fmt.Sprintf("hcmViewId_%s_%d", hcmKey, i),
 				"ParkingBot",
-				"ParkingBotId",
+				spaces.BotReleaserId,
 				space,
+				m.data.Clock.Now(),
 			)
 			slog.Info(
 				"processing vacation",
@@ -215,16 +256,29 @@ func (m *Manager) addVacationReleases(
 				vacation,
 			)
 
-			release.StartDate = &vacation.StartDay
-			if release.StartDate.Before(todayDate) {
+			startDate := vacation.StartDay
+			if startDate.Before(todayDate) {
 				// NOTE: we only create requests for the future. so
 				// if a vacation period started 5 days ago and it continues for
 				// 3 more days then here we create the release from today
 				// till the end of the vacation.
-				release.StartDate = &todayDate
+				startDate = todayDate
 			}
+			// Releases only matter on working days so start from the first one
+			startDate = m.data.Calendar.WorkingDayOnOrAfter(startDate)
+			release.StartDate = &startDate
 			release.EndDate = &vacation.EndDay
 
+			if release.EndDate.Before(*release.StartDate) {
+				slog.Info("vacation has no working days", "vacation", vacation)
+				m.vacationsHash[vacation.Key] = true
+				err := m.data.ParkingLot.ToBeReleased.Remove(release)
+				if err != nil {
+					actions = append(actions, m.reportErrorAction(err.Error()))
+				}
+				continue
+			}
+
 			overlaps := m.data.ParkingLot.ToBeReleased.CheckOverlap(release)
 			if len(overlaps) > 0 {
 				slog.Info("vacation overlaps", "overlaps", overlaps, "vacation", vacation)
@@ -236,12 +290,18 @@ func (m *Manager) addVacationReleases(
 			}
 
 			m.vacationsHash[vacation.Key] = true
-			release.MarkSubmitted("HCM")
+			release.MarkSubmitted("HCM", m.data.Clock.Now())
 
-			if common.EqualDate(*release.StartDate, todayDate) {
-				// Directly release space if release start from today
-				space.Reserved = false
-				release.MarkActive()
+			bookingDate := m.data.Calendar.BookingDate(
+				now,
+				m.data.ParkingLot.SpaceResetTime(space.Key()),
+			)
+			if !release.StartDate.After(bookingDate) {
+				// Directly release space if release starts on the day for which
+				// reservations are currently made (today or next working day
+				// if reset already happened)
+				m.data.ParkingLot.FreeSpace(space)
+				release.MarkActive(now)
 			}
 			m.data.ParkingLot.ToBeReleased.Update(release)
 
@@ -273,6 +333,12 @@ func (m *Manager) addVacationReleases(
 
 	m.data.ParkingLot.SynchronizeToFile()
 
+	// NOTE: spaces of releases that start immediately are free now
+	actions = append(
+		actions,
+		parking_spaces.FreeSpaceNotifications(m.data, m.notificationThrottle)...,
+	)
+
 	return actions
 }
 
@@ -330,12 +396,8 @@ func (m *Manager) fetchBusinessTrips(hcmUrl string) (*VacationInfo, error) {
 	return NewVacationInfoFromBTripInfo(&info), nil
 }
 
-// vacationsInfo Fetches employee vacation information (only current and future
-// ones)
-func (m *Manager) vacationsInfo(
-	hcmUrl string,
-	hcmCompany user.Company,
-) (VacationData, error) {
+// fetchVacations Fetches vacation periods & business trips of all employees
+func (m *Manager) fetchVacations(hcmUrl string) (*VacationInfo, error) {
 	info, err := m.fetchVacationsInfo(hcmUrl)
 	if err != nil {
 		return nil, err
@@ -348,9 +410,22 @@ func (m *Manager) vacationsInfo(
 
 	// Merge business trips into all vacation items
 	info.Items = append(info.Items, btripInfo.Items...)
+	return info, nil
+}
+
+// vacationsInfo Returns employee vacation information (only current and
+// future ones) from the fetched info or the error of the fetch
+func (m *Manager) vacationsInfo(
+	info *VacationInfo,
+	fetchErr error,
+	hcmCompany user.Company,
+) (VacationData, error) {
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
 
 	// filter only current and future vacations
-	today := common.TodayDate()
+	today := common.DateOf(m.data.Clock.Now())
 	location := today.Location()
 	vacationData := VacationData{}
 	for _, employee := range info.Items {
@@ -411,41 +486,62 @@ func (m *Manager) vacationsInfo(
 		}
 	}
 
-	err = m.SynchronizeToFile()
+	err := m.SynchronizeToFile()
 	return vacationData, err
 }
 
-func (m *Manager) updateAllEmployeesInfo() error {
+// companyEmployees Fetched employees of each company (companies whose
+// employees couldn't be fetched are missing)
+type companyEmployees map[user.Company]*EmployeeInfo
+
+// fetchAllEmployees Fetches employees of all companies. Employees of the
+// companies that were fetched successfully are returned even on error.
+func (m *Manager) fetchAllEmployees() (companyEmployees, error) {
 	var errs []error
-	err := m.updateEmployeesInfo(m.hcmQdevUrl, user.Qdev)
-	if err != nil {
-		errs = append(errs, fmt.Errorf("error updating Qdev employees info: %w", err))
+	employees := companyEmployees{}
+	for _, company := range []struct {
+		name    string
+		hcmUrl  string
+		company user.Company
+	}{
+		{"Qdev", m.hcmQdevUrl, user.Qdev},
+		{"Quadigi", m.hcmQuadUrl, user.Quad},
+	} {
+		info, err := fetchEmployees(company.hcmUrl, m.hcmApiToken)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error updating %s employees info: %w", company.name, err))
+			continue
+		}
+		employees[company.company] = info
 	}
+	return employees, errors.Join(errs...)
+}
 
-	err = m.updateEmployeesInfo(m.hcmQuadUrl, user.Quad)
-	if err != nil {
-		errs = append(errs, fmt.Errorf("error updating Quadigi employees info: %w", err))
+func (m *Manager) updateAllEmployeesInfo(employees companyEmployees) error {
+	var errs []error
+	for company, info := range employees {
+		errs = append(errs, m.updateEmployeesInfo(info, company))
 	}
-
 	return errors.Join(errs...)
 }
 
-func (m *Manager) updateEmployeesInfo(hcmUrl string, hcmCompany user.Company) error {
-	var errs []error
-
+func fetchEmployees(hcmUrl, token string) (*EmployeeInfo, error) {
 	url := hcmUrl + ListEmployeesEndpoint
-	b, err := makeHcmRequest(url, m.hcmApiToken)
+	b, err := makeHcmRequest(url, token)
 	if err != nil {
-		errs = append(errs, fmt.Errorf("failed to make hcm request: %v", err))
-		return errors.Join(errs...)
+		return nil, fmt.Errorf("failed to make hcm request: %v", err)
 	}
 
 	var info EmployeeInfo
 	err = xml.Unmarshal(b, &info)
 	if err != nil {
-		errs = append(errs, fmt.Errorf("failed to unmarshal employees info: %v", err))
-		return errors.Join(errs...)
+		return nil, fmt.Errorf("failed to unmarshal employees info: %v", err)
 	}
+	return &info, nil
+}
+
+func (m *Manager) updateEmployeesInfo(info *EmployeeInfo, hcmCompany user.Company) error {
+	var errs []error
 
 	users := m.data.UserManager.AllUserNames()
 	for _, employee := range info.Items {
@@ -563,7 +659,7 @@ func NewVacationInfoFromBTripInfo(btripInfo *BTripInfo) *VacationInfo {
 }
 
 func makeHcmRequest(url, token string) ([]byte, error) {
-	client := &http.Client{}
+	client := &http.Client{Timeout: requestTimeout}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create hcm request for url=%q: %v", url, err)
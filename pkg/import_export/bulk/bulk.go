@@ -0,0 +1,310 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+type Dataset string
+
+const (
+	ParkingSpaces  Dataset = "parking-spaces"
+	Workspaces     Dataset = "workspaces"
+	ParkingPlans   Dataset = "parking-plans"
+	WorkspacePlans Dataset = "workspace-plans"
+	Users          Dataset = "users"
+)
+
+var AllDatasets = []Dataset{
+	ParkingSpaces,
+	Workspaces,
+	ParkingPlans,
+	WorkspacePlans,
+	Users,
+}
+
+var DatasetNameMap = map[Dataset]string{
+	ParkingSpaces:  "Parking spaces",
+	Workspaces:     "Workspaces",
+	ParkingPlans:   "Parking floor plans",
+	WorkspacePlans: "Workspace floor plans",
+	Users:          "Users",
+}
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+)
+
+var AllFormats = []Format{CSV, JSON}
+
+// maxPreviewKeys Max number of added/updated keys listed in preview text
+const maxPreviewKeys = 20
+
+func ParseDataset(name string) (Dataset, error) {
+	dataset := Dataset(strings.ToLower(strings.TrimSpace(name)))
+	if _, found := DatasetNameMap[dataset]; !found {
+		return "", fmt.Errorf("unknown dataset %q, expected one of: %v", name, AllDatasets)
+	}
+	return dataset, nil
+}
+
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(name)))
+	if format != CSV && format != JSON {
+		return "", fmt.Errorf("unknown format %q, expected one of: %v", name, AllFormats)
+	}
+	return format, nil
+}
+
+// FormatFromFilename Detects format from file extension
+func FormatFromFilename(filename string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(filename), ".")
+	format, err := ParseFormat(ext)
+	if err != nil {
+		return "", fmt.Errorf("can't detect format of %q: expected .csv or .json file", filename)
+	}
+	return format, nil
+}
+
+// Filename Default name of an exported file
+func Filename(dataset Dataset, format Format) string {
+	return fmt.Sprintf("%s.%s", dataset, format)
+}
+
+// importErrors Collects all validation errors of an imported file so that
+// they can be fixed at once
+type importErrors []string
+
+// add Adds error of the n-th entry (1-based, not counting csv header)
+func (e *importErrors) add(entry int, format string, args ...any) {
+	*e = append(*e, fmt.Sprintf("entry %d: %s", entry, fmt.Sprintf(format, args...)))
+}
+
+func (e importErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"found %d import error(s):\n  - %s",
+		len(e),
+		strings.Join(e, "\n  - "),
+	)
+}
+
+func lotOf(data *model.Data, dataset Dataset) *spaces.SpacesLot {
+	switch dataset {
+	case ParkingSpaces, ParkingPlans:
+		return data.ParkingLot
+	case Workspaces, WorkspacePlans:
+		return data.WorkspacesLot
+	}
+	return nil
+}
+
+// Export Serializes the dataset in the given format
+func Export(data *model.Data, dataset Dataset, format Format) ([]byte, error) {
+	var table records
+	switch dataset {
+	case ParkingSpaces, Workspaces:
+		table = exportSpaces(lotOf(data, dataset))
+	case ParkingPlans, WorkspacePlans:
+		table = exportPlans(lotOf(data, dataset))
+	case Users:
+		table = exportUsers(data.UserManager)
+	default:
+		return nil, fmt.Errorf("unknown dataset %q", dataset)
+	}
+
+	switch format {
+	case JSON:
+		return json.MarshalIndent(table, "", "\t")
+	case CSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		err := w.Write(table.header())
+		if err != nil {
+			return nil, err
+		}
+		err = w.WriteAll(table.rows())
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Import Parsed & validated content of an imported file. Import only adds
+// new entries & updates existing ones - entries missing from the file are
+// kept as they are.
+type Import struct {
+	Dataset Dataset
+	table   records
+}
+
+// Parse Parses & validates content of an imported file. All validation
+// errors are returned together.
+func Parse(dataset Dataset, format Format, content []byte) (*Import, error) {
+	var table records
+	switch dataset {
+	case ParkingSpaces, Workspaces:
+		table = &spaceRecords{}
+	case ParkingPlans, WorkspacePlans:
+		table = &planRecords{}
+	case Users:
+		table = &userRecords{}
+	default:
+		return nil, fmt.Errorf("unknown dataset %q", dataset)
+	}
+
+	var errs importErrors
+	switch format {
+	case JSON:
+		err := json.Unmarshal(content, table)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse json: %w", err)
+		}
+	case CSV:
+		err := parseCsv(table, content, &errs)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	if table.len() == 0 {
+		return nil, errors.New("no entries found")
+	}
+
+	table.validate(&errs)
+	err := errs.err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Import{Dataset: dataset, table: table}, nil
+}
+
+// parseCsv Parses csv rows into table. Errors of individual entries are
+// added to errs so that they are reported together with validation errors.
+func parseCsv(table records, content []byte, errs *importErrors) error {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("could not parse csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return errors.New("no entries found")
+	}
+
+	header := table.header()
+	// NOTE: excel likes to add a BOM to utf-8 files
+	rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	if strings.Join(rows[0], ",") != strings.Join(header, ",") {
+		return fmt.Errorf(
+			"unexpected csv header %q, expected %q",
+			strings.Join(rows[0], ","),
+			strings.Join(header, ","),
+		)
+	}
+
+	for i, row := range rows[1:] {
+		table.addRow(i+1, row, errs)
+	}
+	return nil
+}
+
+// Preview Changes that would be made by applying the import
+type Preview struct {
+	Dataset   Dataset
+	Added     []string
+	Updated   []string
+	Unchanged int
+}
+
+func (p Preview) HasChanges() bool {
+	return len(p.Added) > 0 || len(p.Updated) > 0
+}
+
+func (p Preview) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"%s: %d to add, %d to update, %d unchanged",
+		DatasetNameMap[p.Dataset],
+		len(p.Added),
+		len(p.Updated),
+		p.Unchanged,
+	)
+
+	list := func(title string, keys []string) {
+		if len(keys) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:", title)
+		for i, key := range keys {
+			if i == maxPreviewKeys {
+				fmt.Fprintf(&b, "\n  ... and %d more", len(keys)-maxPreviewKeys)
+				break
+			}
+			fmt.Fprintf(&b, "\n  - %s", key)
+		}
+	}
+	list("Add", p.Added)
+	list("Update", p.Updated)
+	return b.String()
+}
+
+// Preview Compares the import against current data. Returns an error if
+// the import conflicts with current data (i.e. a user name is taken by
+// another user).
+func (i *Import) Preview(data *model.Data) (Preview, error) {
+	preview := Preview{Dataset: i.Dataset}
+	changes, err := i.table.changes(data, i.Dataset)
+	if err != nil {
+		return preview, err
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.added:
+			preview.Added = append(preview.Added, change.key)
+		case change.updated:
+			preview.Updated = append(preview.Updated, change.key)
+		default:
+			preview.Unchanged++
+		}
+	}
+	return preview, nil
+}
+
+// Apply Adds/updates entries of the import & writes changed data to file.
+// Nothing is changed if the import conflicts with current data.
+func (i *Import) Apply(data *model.Data) (Preview, error) {
+	preview, err := i.Preview(data)
+	if err != nil || !preview.HasChanges() {
+		return preview, err
+	}
+
+	i.table.apply(data, i.Dataset)
+
+	if i.Dataset == Users {
+		data.UserManager.SynchronizeToFile()
+	} else {
+		lotOf(data, i.Dataset).SynchronizeToFile()
+	}
+	return preview, nil
+}
@@ -0,0 +1,221 @@
+package bulk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AngelVI13/slack-bot/pkg/clock"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+const (
+	testUsers = `{
+	"owner": {"Id": "U_OWNER", "Roles": ["admin"], "has_parking": true,
+		"HcmInfo": [{"Id": 1, "Company": "Qdev"}], "BssInfo": []},
+	"other": {"Id": "U_OTHER", "HcmInfo": [], "BssInfo": []}
+}`
+	testSpaces = `{
+	"UnitSpaces": {
+		"1st floor 1": {"Number": 1, "Floor": 1, "Description": "near door"}
+	},
+	"FloorPlans": {"1st floor": "https://example.com/1"}
+}`
+)
+
+func newTestData(t *testing.T) *model.Data {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.json":      testUsers,
+		"parking.json":    testSpaces,
+		"workspaces.json": testSpaces,
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &config.Config{
+		UsersFilename:      filepath.Join(dir, "users.json"),
+		ParkingFilename:    filepath.Join(dir, "parking.json"),
+		WorkspacesFilename: filepath.Join(dir, "workspaces.json"),
+	}
+	return model.NewData(conf, clock.Real{})
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	data := newTestData(t)
+
+	for _, dataset := range AllDatasets {
+		for _, format := range AllFormats {
+			content, err := Export(data, dataset, format)
+			if err != nil {
+				t.Fatalf("%s %s: export failed: %v", dataset, format, err)
+			}
+
+			imported, err := Parse(dataset, format, content)
+			if err != nil {
+				t.Fatalf("%s %s: parse failed: %v\n%s", dataset, format, err, content)
+			}
+
+			preview, err := imported.Preview(data)
+			if err != nil {
+				t.Fatalf("%s %s: preview failed: %v", dataset, format, err)
+			}
+			if preview.HasChanges() {
+				t.Fatalf("%s %s: exported data should be unchanged: %s", dataset, format, preview.Text())
+			}
+		}
+	}
+}
+
+func TestImportSpaces(t *testing.T) {
+	data := newTestData(t)
+
+	content := "number,floor,description,attributes,groups\n1,1,window,ev_charger,Management\n2,1,,,\n3,-1,,,"
+	imported, err := Parse(ParkingSpaces, CSV, []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := imported.Apply(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied.Added) != 2 || len(applied.Updated) != 1 {
+		t.Fatalf("expected 2 added & 1 updated, got: %s", applied.Text())
+	}
+
+	space := data.ParkingLot.GetSpace(spaces.MakeSpaceKey(1, 1))
+	if space.Description != "window" ||
+		!space.HasAttributes([]spaces.Attribute{spaces.AttrEvCharger}) ||
+		!space.IsEligible([]string{"management"}) || space.IsEligible(nil) {
+		t.Fatalf("space not updated: %+v", space)
+	}
+	if len(data.ParkingLot.UnitSpaces) != 3 {
+		t.Fatalf("expected 3 parking spaces, got %d", len(data.ParkingLot.UnitSpaces))
+	}
+	if len(data.WorkspacesLot.UnitSpaces) != 1 {
+		t.Fatal("workspaces should not be changed by parking import")
+	}
+}
+
+func TestImportValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		dataset Dataset
+		format  Format
+		content string
+		errs    []string
+	}{
+		{
+			"invalid spaces",
+			ParkingSpaces,
+			CSV,
+			"number,floor,description,attributes,groups\nx,1,,,\n1,0,,,\n2,1,,,\n2,1,,sauna,",
+			[]string{
+				`entry 1: number "x" is not a number`,
+				"entry 2: invalid floor 0",
+				`entry 4: "1st floor 2" is a duplicate of entry 3`,
+				`entry 4: unknown attribute "sauna"`,
+			},
+		},
+		{
+			"wrong header",
+			ParkingSpaces,
+			CSV,
+			"floor,number,description,attributes,groups\n1,1,,,",
+			[]string{"unexpected csv header"},
+		},
+		{
+			"invalid plan link",
+			WorkspacePlans,
+			JSON,
+			`[{"floor": "4th", "link": "example.com"}]`,
+			[]string{`entry 1: invalid link "example.com"`},
+		},
+		{
+			"invalid users",
+			Users,
+			CSV,
+			"name,id,has_parking,hcm,bss,plates\n,U1,yes,Qdev:1,,ABC 1\nb,U1,true,Qdev:1;Foo:2,Quad:x;Quad:y,abc-1",
+			[]string{
+				"entry 1: name is empty",
+				`entry 1: has_parking "yes" must be true or false`,
+				`entry 2: id "U1" is a duplicate of entry 1`,
+				"entry 2: hcm id Qdev:1 is also used by entry 1",
+				`entry 2: unknown hcm company "Foo"`,
+				"entry 2: user can only have one bss id per company (Quad)",
+				"entry 2: car plate ABC1 is also used by entry 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.dataset, tt.format, []byte(tt.content))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, expected := range tt.errs {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error to contain %q, got:\n%v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestImportUsers(t *testing.T) {
+	data := newTestData(t)
+
+	content := "name,id,has_parking,hcm,bss,plates\n" +
+		"owner_renamed,U_OWNER,false,Qdev:1,Quad:abc,abc 123;XYZ-987\n" +
+		"new,U_NEW,true,Quad:5,,"
+	imported, err := Parse(Users, CSV, []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := imported.Apply(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied.Added) != 1 || len(applied.Updated) != 1 {
+		t.Fatalf("expected 1 added & 1 updated, got: %s", applied.Text())
+	}
+
+	users := data.UserManager.AllUsers()
+	owner, found := users["owner_renamed"]
+	if !found || owner.Id != "U_OWNER" {
+		t.Fatalf("owner was not renamed: %v", users)
+	}
+	if owner.HasPermanentParking || len(owner.BssInfo) != 1 {
+		t.Fatalf("owner was not updated: %+v", owner)
+	}
+	if len(data.UserManager.FindPlates("xyz")) != 1 || len(owner.Plates) != 2 {
+		t.Fatalf("owner plates were not imported: %v", owner.Plates)
+	}
+	if !data.UserManager.IsAdminId("U_OWNER") {
+		t.Fatal("roles should be kept on import")
+	}
+	if data.UserManager.GetUserIdFromHcmId(5, "Quad") != "U_NEW" {
+		t.Fatal("new user was not added")
+	}
+
+	// HCM ID of a user that is not part of the import can't be reused
+	conflict, err := Parse(Users, CSV, []byte("name,id,has_parking,hcm,bss,plates\nthird,U_3,false,Quad:5,,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conflict.Apply(data)
+	if err == nil || !strings.Contains(err.Error(), "hcm id Quad:5 is used by new") {
+		t.Fatalf("expected hcm conflict, got: %v", err)
+	}
+}
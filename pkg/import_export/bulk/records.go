@@ -0,0 +1,598 @@
+package bulk
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+)
+
+// records Entries of one dataset
+type records interface {
+	header() []string
+	rows() [][]string
+	// addRow Parses csv row & adds it as an entry
+	addRow(entry int, fields []string, errs *importErrors)
+	len() int
+	validate(errs *importErrors)
+	changes(data *model.Data, dataset Dataset) ([]change, error)
+	apply(data *model.Data, dataset Dataset)
+}
+
+type change struct {
+	key     string
+	added   bool
+	updated bool
+}
+
+func parseInt(
+	entry int,
+	name, value string,
+	errs *importErrors,
+) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		errs.add(entry, "%s %q is not a number", name, value)
+	}
+	return n
+}
+
+type SpaceRecord struct {
+	Number      int                `json:"number"`
+	Floor       int                `json:"floor"`
+	Description string             `json:"description"`
+	Attributes  []spaces.Attribute `json:"attributes"`
+	Groups      []string           `json:"groups"`
+}
+
+type spaceRecords []SpaceRecord
+
+func exportSpaces(lot *spaces.SpacesLot) *spaceRecords {
+	all := make([]*spaces.Space, 0, len(lot.UnitSpaces))
+	for _, space := range lot.UnitSpaces {
+		all = append(all, space)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Smaller(all[j])
+	})
+
+	table := spaceRecords{}
+	for _, space := range all {
+		table = append(table, SpaceRecord{
+			Number:      space.Number,
+			Floor:       space.Floor,
+			Description: space.Description,
+			Attributes:  append([]spaces.Attribute{}, space.Attributes...),
+			Groups:      append([]string{}, space.Groups...),
+		})
+	}
+	return &table
+}
+
+func (r *spaceRecords) header() []string {
+	return []string{"number", "floor", "description", "attributes", "groups"}
+}
+
+func (r *spaceRecords) rows() [][]string {
+	var rows [][]string
+	for _, space := range *r {
+		rows = append(rows, []string{
+			strconv.Itoa(space.Number),
+			strconv.Itoa(space.Floor),
+			space.Description,
+			formatAttributes(space.Attributes),
+			strings.Join(space.Groups, ";"),
+		})
+	}
+	return rows
+}
+
+func (r *spaceRecords) addRow(entry int, fields []string, errs *importErrors) {
+	*r = append(*r, SpaceRecord{
+		Number:      parseInt(entry, "number", fields[0], errs),
+		Floor:       parseInt(entry, "floor", fields[1], errs),
+		Description: strings.TrimSpace(fields[2]),
+		Attributes:  parseAttributes(fields[3]),
+		Groups:      user.ParseGroups(strings.ReplaceAll(fields[4], ";", ",")),
+	})
+}
+
+// formatAttributes Formats attributes separated by `;`
+func formatAttributes(attrs []spaces.Attribute) string {
+	var parts []string
+	for _, attr := range attrs {
+		parts = append(parts, string(attr))
+	}
+	return strings.Join(parts, ";")
+}
+
+// parseAttributes Parses attributes separated by `;`. Attributes are
+// validated together with other fields.
+func parseAttributes(value string) []spaces.Attribute {
+	attrs := []spaces.Attribute{}
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			attrs = append(attrs, spaces.Attribute(part))
+		}
+	}
+	return attrs
+}
+
+func sameAttributes(a, b []spaces.Attribute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, attr := range a {
+		if !slices.Contains(b, attr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *spaceRecords) len() int {
+	return len(*r)
+}
+
+func (r *spaceRecords) validate(errs *importErrors) {
+	seen := map[spaces.SpaceKey]int{}
+	for i, space := range *r {
+		entry := i + 1
+		if space.Number <= 0 {
+			errs.add(entry, "invalid number %d, must be positive", space.Number)
+		}
+		if space.Floor == 0 {
+			errs.add(entry, "invalid floor 0")
+		}
+		for _, attr := range space.Attributes {
+			if !slices.Contains(spaces.AllAttributes, attr) {
+				errs.add(entry, "unknown attribute %q", attr)
+			}
+		}
+		// NOTE: group names in json files are not normalized by parsing
+		(*r)[i].Groups = user.ParseGroups(strings.Join(space.Groups, ","))
+
+		key := spaces.MakeSpaceKey(space.Number, space.Floor)
+		if other, found := seen[key]; found {
+			errs.add(entry, "%q is a duplicate of entry %d", key, other)
+		}
+		seen[key] = entry
+	}
+}
+
+func (r *spaceRecords) changes(data *model.Data, dataset Dataset) ([]change, error) {
+	lot := lotOf(data, dataset)
+
+	var changes []change
+	for _, record := range *r {
+		key := spaces.MakeSpaceKey(record.Number, record.Floor)
+		space, found := lot.UnitSpaces[key]
+		changes = append(changes, change{
+			key:   string(key),
+			added: !found,
+			updated: found && (space.Description != record.Description ||
+				!sameAttributes(space.Attributes, record.Attributes) ||
+				!slices.Equal(space.Groups, record.Groups)),
+		})
+	}
+	return changes, nil
+}
+
+func (r *spaceRecords) apply(data *model.Data, dataset Dataset) {
+	lot := lotOf(data, dataset)
+
+	for _, record := range *r {
+		key := spaces.MakeSpaceKey(record.Number, record.Floor)
+		space, found := lot.UnitSpaces[key]
+		if found {
+			space.Description = record.Description
+			space.Attributes = record.Attributes
+			space.Groups = record.Groups
+			continue
+		}
+		space = spaces.NewSpace(
+			record.Number,
+			record.Floor,
+			record.Description,
+			record.Attributes...,
+		)
+		space.Groups = record.Groups
+		lot.UnitSpaces[key] = space
+	}
+}
+
+// FloorPlanRecord Floor is the key of FloorPlansMap as it is stored in the
+// data file
+type FloorPlanRecord struct {
+	Floor string `json:"floor"`
+	Link  string `json:"link"`
+}
+
+type planRecords []FloorPlanRecord
+
+func exportPlans(lot *spaces.SpacesLot) *planRecords {
+	var floors []string
+	for floor := range lot.FloorPlans {
+		floors = append(floors, floor)
+	}
+	slices.Sort(floors)
+
+	table := planRecords{}
+	for _, floor := range floors {
+		table = append(table, FloorPlanRecord{Floor: floor, Link: lot.FloorPlans[floor]})
+	}
+	return &table
+}
+
+func (r *planRecords) header() []string {
+	return []string{"floor", "link"}
+}
+
+func (r *planRecords) rows() [][]string {
+	var rows [][]string
+	for _, plan := range *r {
+		rows = append(rows, []string{plan.Floor, plan.Link})
+	}
+	return rows
+}
+
+func (r *planRecords) addRow(entry int, fields []string, errs *importErrors) {
+	*r = append(*r, FloorPlanRecord{
+		Floor: strings.TrimSpace(fields[0]),
+		Link:  strings.TrimSpace(fields[1]),
+	})
+}
+
+func (r *planRecords) len() int {
+	return len(*r)
+}
+
+func (r *planRecords) validate(errs *importErrors) {
+	seen := map[string]int{}
+	for i, plan := range *r {
+		entry := i + 1
+		if plan.Floor == "" {
+			errs.add(entry, "floor is empty")
+		}
+		if !strings.HasPrefix(plan.Link, "http://") &&
+			!strings.HasPrefix(plan.Link, "https://") {
+			errs.add(entry, "invalid link %q, must start with http(s)://", plan.Link)
+		}
+
+		if other, found := seen[plan.Floor]; found {
+			errs.add(entry, "floor %q is a duplicate of entry %d", plan.Floor, other)
+		}
+		seen[plan.Floor] = entry
+	}
+}
+
+func (r *planRecords) changes(data *model.Data, dataset Dataset) ([]change, error) {
+	lot := lotOf(data, dataset)
+
+	var changes []change
+	for _, record := range *r {
+		link, found := lot.FloorPlans[record.Floor]
+		changes = append(changes, change{
+			key:     record.Floor,
+			added:   !found,
+			updated: found && link != record.Link,
+		})
+	}
+	return changes, nil
+}
+
+func (r *planRecords) apply(data *model.Data, dataset Dataset) {
+	lot := lotOf(data, dataset)
+
+	for _, record := range *r {
+		lot.FloorPlans[record.Floor] = record.Link
+	}
+}
+
+type UserRecord struct {
+	Name       string                     `json:"name"`
+	Id         string                     `json:"id"`
+	HasParking bool                       `json:"has_parking"`
+	Hcm        []user.CompanyInfo[int]    `json:"hcm"`
+	Bss        []user.CompanyInfo[string] `json:"bss"`
+	Plates     []string                   `json:"plates"`
+}
+
+type userRecords []UserRecord
+
+func exportUsers(userManager *user.Manager) *userRecords {
+	users := userManager.AllUsers()
+
+	var names []string
+	for name := range users {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	table := userRecords{}
+	for _, name := range names {
+		u := users[name]
+
+		var bssInfo []user.CompanyInfo[string]
+		for _, bss := range u.BssInfo {
+			// NOTE: empty BSS IDs are not exported as they are not valid
+			if bss.Id != "" {
+				bssInfo = append(bssInfo, bss)
+			}
+		}
+
+		table = append(table, UserRecord{
+			Name:       name,
+			Id:         u.Id,
+			HasParking: u.HasPermanentParking,
+			Hcm:        append([]user.CompanyInfo[int]{}, u.HcmInfo...),
+			Bss:        append([]user.CompanyInfo[string]{}, bssInfo...),
+			Plates:     append([]string{}, u.Plates...),
+		})
+	}
+	return &table
+}
+
+func (r *userRecords) header() []string {
+	return []string{"name", "id", "has_parking", "hcm", "bss", "plates"}
+}
+
+// formatCompanyIds Formats company IDs as `Company:Id` separated by `;`
+func formatCompanyIds[T user.CompanyId](infos []user.CompanyInfo[T]) string {
+	var parts []string
+	for _, info := range infos {
+		parts = append(parts, fmt.Sprintf("%s:%v", info.Company, info.Id))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (r *userRecords) rows() [][]string {
+	var rows [][]string
+	for _, u := range *r {
+		rows = append(rows, []string{
+			u.Name,
+			u.Id,
+			strconv.FormatBool(u.HasParking),
+			formatCompanyIds(u.Hcm),
+			formatCompanyIds(u.Bss),
+			strings.Join(u.Plates, ";"),
+		})
+	}
+	return rows
+}
+
+// parseCompanyIds Parses `Company:Id` pairs separated by `;`
+func parseCompanyIds(
+	entry int,
+	name, value string,
+	errs *importErrors,
+) (companies []user.Company, ids []string) {
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		company, id, found := strings.Cut(part, ":")
+		if !found {
+			errs.add(entry, "%s %q must have format Company:Id", name, part)
+			continue
+		}
+		companies = append(companies, user.Company(strings.TrimSpace(company)))
+		ids = append(ids, strings.TrimSpace(id))
+	}
+	return companies, ids
+}
+
+func (r *userRecords) addRow(entry int, fields []string, errs *importErrors) {
+	record := UserRecord{
+		Name:   strings.TrimSpace(fields[0]),
+		Id:     strings.TrimSpace(fields[1]),
+		Hcm:    []user.CompanyInfo[int]{},
+		Bss:    []user.CompanyInfo[string]{},
+		Plates: user.ParsePlates(strings.ReplaceAll(fields[5], ";", ",")),
+	}
+
+	hasParking := strings.TrimSpace(fields[2])
+	if hasParking != "" {
+		var err error
+		record.HasParking, err = strconv.ParseBool(hasParking)
+		if err != nil {
+			errs.add(entry, "has_parking %q must be true or false", hasParking)
+		}
+	}
+
+	companies, ids := parseCompanyIds(entry, "hcm", fields[3], errs)
+	for i, company := range companies {
+		record.Hcm = append(record.Hcm, user.CompanyInfo[int]{
+			Id:      parseInt(entry, "hcm id", ids[i], errs),
+			Company: company,
+		})
+	}
+
+	companies, ids = parseCompanyIds(entry, "bss", fields[4], errs)
+	for i, company := range companies {
+		record.Bss = append(record.Bss, user.CompanyInfo[string]{
+			Id:      ids[i],
+			Company: company,
+		})
+	}
+
+	*r = append(*r, record)
+}
+
+func (r *userRecords) len() int {
+	return len(*r)
+}
+
+func validCompany(company user.Company) bool {
+	_, found := user.CompanyNameMap[company]
+	return found
+}
+
+func (r *userRecords) validate(errs *importErrors) {
+	seenNames := map[string]int{}
+	seenIds := map[string]int{}
+	seenHcm := map[user.CompanyInfo[int]]int{}
+	seenBss := map[user.CompanyInfo[string]]int{}
+	seenPlates := map[string]int{}
+
+	for i, u := range *r {
+		entry := i + 1
+		// NOTE: plates in json files are not normalized by parsing
+		(*r)[i].Plates = user.ParsePlates(strings.Join(u.Plates, ","))
+		for _, plate := range (*r)[i].Plates {
+			if other, found := seenPlates[plate]; found {
+				errs.add(entry, "car plate %s is also used by entry %d", plate, other)
+			}
+			seenPlates[plate] = entry
+		}
+
+		if u.Name == "" {
+			errs.add(entry, "name is empty")
+		}
+		if u.Id == "" || strings.ContainsAny(u.Id, " \t") {
+			errs.add(entry, "invalid slack user id %q", u.Id)
+		}
+
+		if other, found := seenNames[u.Name]; found {
+			errs.add(entry, "name %q is a duplicate of entry %d", u.Name, other)
+		}
+		seenNames[u.Name] = entry
+		if other, found := seenIds[u.Id]; found {
+			errs.add(entry, "id %q is a duplicate of entry %d", u.Id, other)
+		}
+		seenIds[u.Id] = entry
+
+		for _, hcm := range u.Hcm {
+			if !validCompany(hcm.Company) {
+				errs.add(entry, "unknown hcm company %q", hcm.Company)
+			}
+			if hcm.Id <= 0 {
+				errs.add(entry, "invalid hcm id %d", hcm.Id)
+			}
+			if other, found := seenHcm[hcm]; found {
+				errs.add(entry, "hcm id %s:%d is also used by entry %d", hcm.Company, hcm.Id, other)
+			}
+			seenHcm[hcm] = entry
+		}
+
+		var bssCompanies []user.Company
+		for _, bss := range u.Bss {
+			if !validCompany(bss.Company) {
+				errs.add(entry, "unknown bss company %q", bss.Company)
+			}
+			if bss.Id == "" {
+				errs.add(entry, "bss id of %s is empty", bss.Company)
+			}
+			if slices.Contains(bssCompanies, bss.Company) {
+				errs.add(entry, "user can only have one bss id per company (%s)", bss.Company)
+			}
+			bssCompanies = append(bssCompanies, bss.Company)
+			if other, found := seenBss[bss]; found {
+				errs.add(entry, "bss id %s:%s is also used by entry %d", bss.Company, bss.Id, other)
+			}
+			seenBss[bss] = entry
+		}
+	}
+}
+
+func sameCompanyIds[T user.CompanyId](a, b []user.CompanyInfo[T]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, info := range a {
+		if !slices.Contains(b, info) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *userRecords) changes(data *model.Data, dataset Dataset) ([]change, error) {
+	users := data.UserManager.AllUsers()
+	imported := map[string]bool{}
+	for _, record := range *r {
+		imported[record.Id] = true
+	}
+
+	var errs importErrors
+	var changes []change
+	for i, record := range *r {
+		entry := i + 1
+
+		// NOTE: HCM & BSS IDs identify users during sync so they must stay
+		// unique among users that are not part of the import
+		for name, u := range users {
+			if imported[u.Id] {
+				continue
+			}
+			if name == record.Name {
+				errs.add(entry, "name %q is used by another user (%s)", name, u.Id)
+			}
+			for _, hcm := range record.Hcm {
+				if slices.Contains(u.HcmInfo, hcm) {
+					errs.add(entry, "hcm id %s:%d is used by %s", hcm.Company, hcm.Id, name)
+				}
+			}
+			for _, bss := range record.Bss {
+				if slices.Contains(u.BssInfo, bss) {
+					errs.add(entry, "bss id %s:%s is used by %s", bss.Company, bss.Id, name)
+				}
+			}
+			for _, plate := range record.Plates {
+				if slices.Contains(u.Plates, plate) {
+					errs.add(entry, "car plate %s is used by %s", plate, name)
+				}
+			}
+		}
+
+		name := data.UserManager.GetNameFromId(record.Id)
+		existing, found := users[name]
+		if !found {
+			changes = append(changes, change{key: record.Name, added: true})
+			continue
+		}
+
+		var currentBss []user.CompanyInfo[string]
+		for _, bss := range existing.BssInfo {
+			if bss.Id != "" {
+				currentBss = append(currentBss, bss)
+			}
+		}
+
+		key := record.Name
+		if name != record.Name {
+			key = fmt.Sprintf("%s (renamed from %s)", record.Name, name)
+		}
+		changes = append(changes, change{
+			key: key,
+			updated: name != record.Name ||
+				existing.HasPermanentParking != record.HasParking ||
+				!sameCompanyIds(existing.HcmInfo, record.Hcm) ||
+				!sameCompanyIds(currentBss, record.Bss) ||
+				!slices.Equal(existing.Plates, record.Plates),
+		})
+	}
+	return changes, errs.err()
+}
+
+func (r *userRecords) apply(data *model.Data, dataset Dataset) {
+	imported := user.UsersMap{}
+	for _, record := range *r {
+		imported[record.Name] = &user.User{
+			Id:                  record.Id,
+			HasPermanentParking: record.HasParking,
+			HcmInfo:             record.Hcm,
+			BssInfo:             record.Bss,
+			Plates:              record.Plates,
+		}
+	}
+	data.UserManager.ImportUsers(imported)
+}
@@ -0,0 +1,131 @@
+package import_export
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AngelVI13/slack-bot/pkg/import_export/bulk"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+)
+
+const (
+	ExportCmd = "export"
+	ImportCmd = "import"
+)
+
+// IsCliCmd Checks if command line arguments are an import/export subcommand
+func IsCliCmd(args []string) bool {
+	return len(args) > 0 && (args[0] == ExportCmd || args[0] == ImportCmd)
+}
+
+// RunCli Runs import/export subcommand on data files. Import only shows a
+// preview unless -apply is given. Usage:
+//
+//	export -data users [-format csv] [-out users.csv]
+//	import -data users -file users.csv [-apply]
+func RunCli(args []string, data *model.Data, out io.Writer) error {
+	if !IsCliCmd(args) {
+		return fmt.Errorf("expected %q or %q subcommand", ExportCmd, ImportCmd)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+	datasetName := flags.String("data", "", fmt.Sprintf("data to %s, one of: %v", args[0], bulk.AllDatasets))
+	formatName := flags.String("format", string(bulk.CSV), "export format: csv or json")
+	outFile := flags.String("out", "", "export file (default: <data>.<format>)")
+	inFile := flags.String("file", "", "file to import (.csv or .json)")
+	apply := flags.Bool("apply", false, "apply import (default: only preview changes)")
+
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	dataset, err := bulk.ParseDataset(*datasetName)
+	if err != nil {
+		return err
+	}
+
+	if args[0] == ExportCmd {
+		format, err := bulk.ParseFormat(*formatName)
+		if err != nil {
+			return err
+		}
+		return runExport(data, dataset, format, *outFile, out)
+	}
+
+	if *inFile == "" {
+		return errors.New("-file is required for import")
+	}
+	return runImport(data, dataset, *inFile, *apply, out)
+}
+
+func runExport(
+	data *model.Data,
+	dataset bulk.Dataset,
+	format bulk.Format,
+	filename string,
+	out io.Writer,
+) error {
+	if filename == "" {
+		filename = bulk.Filename(dataset, format)
+	}
+
+	content, err := bulk.Export(data, dataset, format)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filename, content, 0o666)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Exported %s to %s\n", bulk.DatasetNameMap[dataset], filename)
+	return nil
+}
+
+func runImport(
+	data *model.Data,
+	dataset bulk.Dataset,
+	filename string,
+	apply bool,
+	out io.Writer,
+) error {
+	format, err := bulk.FormatFromFilename(filename)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	imported, err := bulk.Parse(dataset, format, content)
+	if err != nil {
+		return err
+	}
+
+	if !apply {
+		preview, err := imported.Preview(data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, preview.Text())
+		if preview.HasChanges() {
+			fmt.Fprintln(out, "Run again with -apply to apply the changes")
+		}
+		return nil
+	}
+
+	applied, err := imported.Apply(data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Imported %s\n%s\n", filename, applied.Text())
+	return nil
+}
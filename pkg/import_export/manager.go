@@ -0,0 +1,312 @@
+package import_export
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/import_export/bulk"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+)
+
+const (
+	Identifier   = "Bulk Data: "
+	SlashCmd     = "/bulk-data"
+	TestSlashCmd = "/test-bulk-data"
+)
+
+// pendingImport Import waiting for confirmation in preview modal
+type pendingImport struct {
+	*bulk.Import
+	filename string
+}
+
+type Manager struct {
+	eventManager   *event.EventManager
+	data           *model.Data
+	pendingImports map[string]*pendingImport
+	testingActive  bool
+}
+
+func NewManager(
+	eventManager *event.EventManager,
+	data *model.Data,
+	conf *config.Config,
+) *Manager {
+	bulkDataTitle = common.MakeTitle(bulkDataTitle, conf.TestingActive)
+	importPreviewTitle = common.MakeTitle(importPreviewTitle, conf.TestingActive)
+	return &Manager{
+		eventManager:   eventManager,
+		data:           data,
+		pendingImports: map[string]*pendingImport{},
+		testingActive:  conf.TestingActive,
+	}
+}
+
+func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
+	switch e.Type() {
+	case event.SlashCmdEvent:
+		data := e.(*slackApi.Slash)
+		if !common.ShouldProcessSlash(
+			data.Command,
+			SlashCmd,
+			TestSlashCmd,
+			m.testingActive,
+		) {
+			return
+		}
+
+		response := m.handleSlashCmd(data)
+
+		m.eventManager.Publish(response)
+	case event.ViewSubmissionEvent:
+		data := e.(*slackApi.ViewSubmission)
+
+		var response *common.Response
+		switch data.Title {
+		case bulkDataTitle:
+			response = m.handleBulkDataSubmission(data)
+		case importPreviewTitle:
+			response = m.handlePreviewSubmission(data)
+		}
+		if response == nil {
+			return
+		}
+
+		m.eventManager.Publish(response)
+	case event.ViewClosedEvent:
+		data := e.(*slackApi.ViewClosed)
+		if data.Title == importPreviewTitle {
+			delete(m.pendingImports, data.UserId)
+		}
+	}
+}
+
+func (m *Manager) Context() string {
+	return Identifier
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - imported data is written to file when applied
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
+	if !m.data.UserManager.HasAnyPermission(
+		data.UserId,
+		user.PermEditParkingSpaces,
+		user.PermEditWorkspaces,
+		user.PermManageUsers,
+		user.PermManageHr,
+		user.PermAudit,
+	) {
+		errTxt := fmt.Sprintf(
+			"You don't have permission to execute '%s' command",
+			data.Command,
+		)
+		action := common.NewPostAction(data.UserId, errTxt, false)
+		return common.NewResponseEvent(data.UserName, action)
+	}
+
+	modal := m.generateBulkDataModalRequest()
+
+	action := common.NewOpenViewAction(data.TriggerId, modal)
+	response := common.NewResponseEvent(data.UserName, action)
+	return response
+}
+
+// canExport Users that can change or audit the data can export it
+func (m *Manager) canExport(userId string, dataset bulk.Dataset) bool {
+	switch dataset {
+	case bulk.ParkingSpaces, bulk.ParkingPlans:
+		return m.data.UserManager.HasAnyPermission(
+			userId,
+			user.PermEditParkingSpaces,
+			user.PermAudit,
+		)
+	case bulk.Workspaces, bulk.WorkspacePlans:
+		return m.data.UserManager.HasAnyPermission(
+			userId,
+			user.PermEditWorkspaces,
+			user.PermAudit,
+		)
+	case bulk.Users:
+		return m.data.UserManager.HasAnyPermission(
+			userId,
+			user.PermManageUsers,
+			user.PermManageHr,
+			user.PermAudit,
+		)
+	}
+	return false
+}
+
+// canImport Users import changes both parking rights & HCM/BSS info so
+// both permissions are needed
+func (m *Manager) canImport(userId string, dataset bulk.Dataset) bool {
+	switch dataset {
+	case bulk.ParkingSpaces, bulk.ParkingPlans:
+		return m.data.UserManager.HasPermission(userId, user.PermEditParkingSpaces)
+	case bulk.Workspaces, bulk.WorkspacePlans:
+		return m.data.UserManager.HasPermission(userId, user.PermEditWorkspaces)
+	case bulk.Users:
+		return m.data.UserManager.HasPermission(userId, user.PermManageUsers) &&
+			m.data.UserManager.HasPermission(userId, user.PermManageHr)
+	}
+	return false
+}
+
+func (m *Manager) errorResponse(data *slackApi.BaseEvent, errTxt string) *common.Response {
+	slog.Error(errTxt, "requestor", data.UserName)
+	action := common.NewPostAction(data.UserId, errTxt, false)
+	return common.NewResponseEvent(data.UserName, action)
+}
+
+func (m *Manager) handleBulkDataSubmission(data *slackApi.ViewSubmission) *common.Response {
+	op := operation(data.IValueSingle(operationBlockId, operationOptionId))
+	dataset, err := bulk.ParseDataset(data.IValueSingle(datasetBlockId, datasetOptionId))
+	if err != nil {
+		return m.errorResponse(&data.BaseEvent, err.Error())
+	}
+
+	if op == exportOperation {
+		return m.handleExport(data, dataset)
+	}
+	return m.handleImport(data, dataset)
+}
+
+func (m *Manager) handleExport(
+	data *slackApi.ViewSubmission,
+	dataset bulk.Dataset,
+) *common.Response {
+	if !m.canExport(data.UserId, dataset) {
+		errTxt := fmt.Sprintf(
+			"You don't have permission to export %s",
+			bulk.DatasetNameMap[dataset],
+		)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	format := bulk.CSV
+	formatValues := data.IValue(formatBlockId, formatOptionId)
+	if len(formatValues) > 0 {
+		var err error
+		format, err = bulk.ParseFormat(formatValues[0])
+		if err != nil {
+			return m.errorResponse(&data.BaseEvent, err.Error())
+		}
+	}
+
+	content, err := bulk.Export(m.data, dataset, format)
+	if err != nil {
+		errTxt := fmt.Sprintf("Failed to export %s: %v", bulk.DatasetNameMap[dataset], err)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	slog.Info("Exporting data", "requestor", data.UserName, "dataset", dataset, "format", format)
+	action := common.NewUploadFileAction(
+		data.UserId,
+		bulk.Filename(dataset, format),
+		content,
+		fmt.Sprintf("Export of %s", bulk.DatasetNameMap[dataset]),
+	)
+	return common.NewResponseEvent(data.UserName, action)
+}
+
+func (m *Manager) handleImport(
+	data *slackApi.ViewSubmission,
+	dataset bulk.Dataset,
+) *common.Response {
+	if !m.canImport(data.UserId, dataset) {
+		errTxt := fmt.Sprintf(
+			"You don't have permission to import %s",
+			bulk.DatasetNameMap[dataset],
+		)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	if data.FilesErr != nil {
+		errTxt := fmt.Sprintf("Nothing was imported: %v", data.FilesErr)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	file, found := data.File(fileActionId)
+	if !found {
+		return m.errorResponse(&data.BaseEvent, "Nothing was imported: no file was uploaded")
+	}
+
+	format, err := bulk.FormatFromFilename(file.Name)
+	if err != nil {
+		errTxt := fmt.Sprintf("Nothing was imported: %v", err)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	imported, err := bulk.Parse(dataset, format, file.Content)
+	if err != nil {
+		errTxt := fmt.Sprintf("Nothing was imported from `%s`:\n```%v```", file.Name, err)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	preview, err := imported.Preview(m.data)
+	if err != nil {
+		errTxt := fmt.Sprintf("Nothing was imported from `%s`:\n```%v```", file.Name, err)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	if preview.HasChanges() {
+		m.pendingImports[data.UserId] = &pendingImport{Import: imported, filename: file.Name}
+	}
+
+	// NOTE: bulk data modal is already closed so preview is opened as a new view
+	modal := m.generateImportPreviewModalRequest(preview, file.Name)
+	action := common.NewOpenViewAction(data.TriggerId, modal)
+	return common.NewResponseEvent(data.UserName, action)
+}
+
+func (m *Manager) handlePreviewSubmission(data *slackApi.ViewSubmission) *common.Response {
+	pending, found := m.pendingImports[data.UserId]
+	delete(m.pendingImports, data.UserId)
+	if !found {
+		return m.errorResponse(&data.BaseEvent, "Nothing was imported: import expired, please upload the file again")
+	}
+
+	// NOTE: permissions could have changed since the preview was shown
+	if !m.canImport(data.UserId, pending.Dataset) {
+		errTxt := fmt.Sprintf(
+			"You don't have permission to import %s",
+			bulk.DatasetNameMap[pending.Dataset],
+		)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	applied, err := pending.Apply(m.data)
+	if err != nil {
+		errTxt := fmt.Sprintf("Nothing was imported from `%s`:\n```%v```", pending.filename, err)
+		return m.errorResponse(&data.BaseEvent, errTxt)
+	}
+
+	slog.Info(
+		"Imported data",
+		"requestor", data.UserName,
+		"file", pending.filename,
+		"dataset", pending.Dataset,
+		"added", len(applied.Added),
+		"updated", len(applied.Updated),
+	)
+	txt := fmt.Sprintf("Imported `%s`:\n```%s```", pending.filename, applied.Text())
+	action := common.NewPostAction(data.UserId, txt, false)
+	return common.NewResponseEvent(data.UserName, action)
+}
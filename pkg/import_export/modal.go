@@ -0,0 +1,157 @@
+package import_export
+
+import (
+	"fmt"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/import_export/bulk"
+	"github.com/slack-go/slack"
+)
+
+const (
+	operationBlockId  = "bulkOperationBlockId"
+	operationOptionId = "bulkOperationOptionId"
+	datasetBlockId    = "bulkDatasetBlockId"
+	datasetOptionId   = "bulkDatasetOptionId"
+	formatBlockId     = "bulkFormatBlockId"
+	formatOptionId    = "bulkFormatOptionId"
+	fileBlockId       = "bulkFileBlockId"
+	fileActionId      = "bulkFileActionId"
+)
+
+type operation string
+
+const (
+	exportOperation operation = "Export"
+	importOperation operation = "Import"
+)
+
+var operations = []operation{exportOperation, importOperation}
+
+var (
+	bulkDataTitle      = Identifier
+	importPreviewTitle = Identifier + "Preview"
+)
+
+func (m *Manager) generateBulkDataModalRequest() slack.ModalViewRequest {
+	return common.GenerateModalRequest(bulkDataTitle, m.generateBulkDataBlocks())
+}
+
+func (m *Manager) generateBulkDataBlocks() []slack.Block {
+	description := "*Export* sends you a file with the selected data.\n" +
+		"*Import* adds new & updates existing entries from an uploaded " +
+		"`.csv` or `.json` file (entries missing from the file are kept). " +
+		"You will see a preview of the changes before they are applied.\n" +
+		"Tip: export the data first to get the expected file layout."
+	allBlocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", description, false, false),
+			nil,
+			nil,
+		),
+	}
+
+	var operationOptions []*slack.OptionBlockObject
+	for _, op := range operations {
+		operationOptions = append(operationOptions, slack.NewOptionBlockObject(
+			string(op),
+			slack.NewTextBlockObject(slack.PlainTextType, string(op), false, false),
+			nil,
+		))
+	}
+	operationRadio := slack.NewRadioButtonsBlockElement(operationOptionId, operationOptions...)
+	operationRadio.InitialOption = operationOptions[0]
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		operationBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Operation", false, false),
+		nil,
+		operationRadio,
+	))
+
+	var datasetOptions []*slack.OptionBlockObject
+	for _, dataset := range bulk.AllDatasets {
+		datasetOptions = append(datasetOptions, slack.NewOptionBlockObject(
+			string(dataset),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				bulk.DatasetNameMap[dataset],
+				false,
+				false,
+			),
+			nil,
+		))
+	}
+	datasetSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Select data", false, false),
+		datasetOptionId,
+		datasetOptions...,
+	)
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		datasetBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Data", false, false),
+		nil,
+		datasetSelect,
+	))
+
+	var formatOptions []*slack.OptionBlockObject
+	for _, format := range bulk.AllFormats {
+		formatOptions = append(formatOptions, slack.NewOptionBlockObject(
+			string(format),
+			slack.NewTextBlockObject(slack.PlainTextType, string(format), false, false),
+			nil,
+		))
+	}
+	formatRadio := slack.NewRadioButtonsBlockElement(formatOptionId, formatOptions...)
+	formatRadio.InitialOption = formatOptions[0]
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		formatBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Export format", false, false),
+		nil,
+		formatRadio,
+		true,
+	))
+
+	fileInput := slack.NewFileInputBlockElement(fileActionId).
+		WithFileTypes(string(bulk.CSV), string(bulk.JSON)).
+		WithMaxFiles(1)
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		fileBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "File to import", false, false),
+		slack.NewTextBlockObject(
+			slack.PlainTextType,
+			"Format is detected from the file extension",
+			false,
+			false,
+		),
+		fileInput,
+		true,
+	))
+
+	return allBlocks
+}
+
+func (m *Manager) generateImportPreviewModalRequest(
+	preview bulk.Preview,
+	filename string,
+) slack.ModalViewRequest {
+	text := fmt.Sprintf("Preview of importing `%s`:\n```%s```", filename, preview.Text())
+	if !preview.HasChanges() {
+		text += "\nNothing to import - all entries are unchanged."
+	} else {
+		text += "\nSubmit to apply the changes."
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", text, false, false),
+			nil,
+			nil,
+		),
+	}
+
+	if !preview.HasChanges() {
+		return common.GenerateInfoModalRequest(importPreviewTitle, blocks)
+	}
+	return common.GenerateModalRequest(importPreviewTitle, blocks)
+}
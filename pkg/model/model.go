@@ -1,7 +1,14 @@
 package model
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
 	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/datafile"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
 	"github.com/AngelVI13/slack-bot/pkg/model/user"
 )
@@ -10,15 +17,94 @@ type Data struct {
 	ParkingLot    *spaces.SpacesLot
 	WorkspacesLot *spaces.SpacesLot
 	UserManager   *user.Manager
+	Calendar      *calendar.Calendar
+	// Clock Source of current time for all managers. Use it instead of
+	// time.Now so that time dependent logic can be tested.
+	Clock clock.Clock
+
+	// mu Serializes access to data between event consumers & reloads
+	mu sync.Mutex
+	// schedules Times of daily timer events of the current config (updated
+	// on config reload)
+	schedules config.SchedulesConfig
+	// holidaysFilename & holidaysVersion are used to detect changes to the
+	// holidays file
+	holidaysFilename string
+	holidaysVersion  datafile.Version
 }
 
-func NewData(config *config.Config) *Data {
+func NewData(config *config.Config, clk clock.Clock) *Data {
 	userManager := user.NewManager(config.UsersFilename)
+	userManager.SetConfigAdmins(config.Admins)
+	userManager.SetPlateLookupRoles(config.PlateLookupRoles)
+	userManager.SetDefaultQuota(config.Quota)
 	parkingLot := spaces.GetSpacesLot(config.ParkingFilename)
 	worspacesLot := spaces.GetSpacesLot(config.WorkspacesFilename)
+	parkingLot.SetClock(clk)
+	worspacesLot.SetClock(clk)
+	parkingLot.SetDefaultResetTime(config.Schedules.ParkingReset)
+	worspacesLot.SetDefaultResetTime(config.Schedules.WorkspacesReset)
+	parkingLot.SetRestrictedOpenTime(config.Schedules.ParkingRestrictedOpen)
+	worspacesLot.SetRestrictedOpenTime(config.Schedules.WorkspacesRestrictedOpen)
+	worspacesLot.SetTimeSlots(config.WorkspaceSlots)
+	parkingLot.SetCheckInWindow(config.Schedules.ParkingCheckIn)
+	worspacesLot.SetCheckInWindow(config.Schedules.WorkspacesCheckIn)
 	return &Data{
-		UserManager:   userManager,
-		ParkingLot:    &parkingLot,
-		WorkspacesLot: &worspacesLot,
+		UserManager:      userManager,
+		ParkingLot:       &parkingLot,
+		WorkspacesLot:    &worspacesLot,
+		Calendar:         calendar.LoadCalendar(config.HolidaysFilename),
+		Clock:            clk,
+		schedules:        config.Schedules,
+		holidaysFilename: config.HolidaysFilename,
+		holidaysVersion:  datafile.Stat(config.HolidaysFilename),
+	}
+}
+
+// Lock Has to be held while data is accessed (i.e. for the duration of an
+// event handler) so that reloads don't swap data in the middle of it.
+func (d *Data) Lock() {
+	d.mu.Lock()
+}
+
+func (d *Data) Unlock() {
+	d.mu.Unlock()
+}
+
+// Schedules Returns schedules of the current config. Use it instead of
+// config.Config.Schedules so that changes of a reloaded config are seen.
+func (d *Data) Schedules() config.SchedulesConfig {
+	return d.schedules
+}
+
+// SynchronizeToFile Writes all data stores to their files
+func (d *Data) SynchronizeToFile() {
+	d.ParkingLot.SynchronizeToFile()
+	d.WorkspacesLot.SynchronizeToFile()
+	d.UserManager.SynchronizeToFile()
+}
+
+func (d *Data) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Flushes all data stores. This should be the last component to be
+// stopped so that changes made by in-flight events are not lost. Gives up
+// if the lock is not released (i.e. by a stuck event) before ctx expires.
+func (d *Data) Stop(ctx context.Context) error {
+	flushed := make(chan struct{})
+	go func() {
+		d.Lock()
+		defer d.Unlock()
+
+		d.SynchronizeToFile()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("data was not written to file: %w", ctx.Err())
 	}
 }
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/datafile"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+// ReloadChanged Reloads data files that were changed on disk (i.e. edited by
+// hand) since they were last read or written by the bot. Each file is
+// validated before it replaces the in-memory data, invalid files are
+// reported & the current data is kept. Has to be called with the lock held.
+func (d *Data) ReloadChanged() error {
+	var errs []error
+
+	if d.ParkingLot.ChangedOnDisk() {
+		lot, err := d.reloadLot(d.ParkingLot)
+		errs = append(errs, err)
+		if err == nil {
+			d.ParkingLot = lot
+		}
+	}
+
+	if d.WorkspacesLot.ChangedOnDisk() {
+		lot, err := d.reloadLot(d.WorkspacesLot)
+		errs = append(errs, err)
+		if err == nil {
+			d.WorkspacesLot = lot
+		}
+	}
+
+	if d.UserManager.ChangedOnDisk() {
+		err := d.UserManager.Reload()
+		errs = append(errs, err)
+		if err == nil {
+			slog.Info("Reloaded users file")
+		}
+	}
+
+	if datafile.Changed(d.holidaysFilename, d.holidaysVersion) {
+		errs = append(errs, d.reloadCalendar(d.holidaysFilename))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *Data) reloadLot(current *spaces.SpacesLot) (*spaces.SpacesLot, error) {
+	lot, err := spaces.ReadSpacesLot(current.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	lot.SetClock(d.Clock)
+	// NOTE: if reset times are missing in the file, keep the current ones
+	if lot.ResetTimes == nil {
+		lot.ResetTimes = current.ResetTimes
+	}
+	lot.SetDefaultResetTime(current.ResetTimes.Default)
+	lot.SetRestrictedOpenTime(current.RestrictedOpenTime())
+	lot.SetTimeSlots(current.TimeSlots())
+	lot.SetCheckInWindow(current.CheckInWindow())
+
+	slog.Info("Reloaded spaces file", "file", lot.Filename, "spaces", len(lot.UnitSpaces))
+	return lot, nil
+}
+
+func (d *Data) reloadCalendar(filename string) error {
+	version := datafile.Stat(filename)
+	cal, err := calendar.ReadCalendar(filename)
+	if err != nil {
+		return err
+	}
+
+	d.Calendar = cal
+	d.holidaysFilename = filename
+	d.holidaysVersion = version
+	slog.Info("Reloaded holidays file", "file", filename)
+	return nil
+}
+
+// ApplyConfig Applies settings of a reloaded config that can be changed
+// without restart (admins, plate lookup roles, quotas, default reset times
+// not changed by admins, restricted spaces open times, workspace time slots,
+// check-in windows, schedules used to validate reset times & holidays). Has
+// to be called with the lock held.
+func (d *Data) ApplyConfig(conf *config.Config) error {
+	d.UserManager.SetConfigAdmins(conf.Admins)
+	d.UserManager.SetPlateLookupRoles(conf.PlateLookupRoles)
+	d.UserManager.SetDefaultQuota(conf.Quota)
+	d.ParkingLot.SetDefaultResetTime(conf.Schedules.ParkingReset)
+	d.WorkspacesLot.SetDefaultResetTime(conf.Schedules.WorkspacesReset)
+	d.ParkingLot.SetRestrictedOpenTime(conf.Schedules.ParkingRestrictedOpen)
+	d.WorkspacesLot.SetRestrictedOpenTime(conf.Schedules.WorkspacesRestrictedOpen)
+	d.WorkspacesLot.SetTimeSlots(conf.WorkspaceSlots)
+	d.ParkingLot.SetCheckInWindow(conf.Schedules.ParkingCheckIn)
+	d.WorkspacesLot.SetCheckInWindow(conf.Schedules.WorkspacesCheckIn)
+	d.schedules = conf.Schedules
+
+	if conf.HolidaysFilename != d.holidaysFilename {
+		return d.reloadCalendar(conf.HolidaysFilename)
+	}
+	return nil
+}
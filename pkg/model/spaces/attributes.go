@@ -0,0 +1,106 @@
+package spaces
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Attribute Typed feature of a space that users can filter by
+type Attribute string
+
+const (
+	AttrEvCharger    Attribute = "ev_charger"
+	AttrAccessible   Attribute = "accessible"
+	AttrCompact      Attribute = "compact"
+	AttrMonitor      Attribute = "monitor"
+	AttrStandingDesk Attribute = "standing_desk"
+)
+
+var AllAttributes = []Attribute{
+	AttrEvCharger,
+	AttrAccessible,
+	AttrCompact,
+	AttrMonitor,
+	AttrStandingDesk,
+}
+
+// ParkingAttributes Attributes that can be set for parking spaces
+var ParkingAttributes = []Attribute{AttrEvCharger, AttrAccessible, AttrCompact}
+
+// WorkspaceAttributes Attributes that can be set for workspaces
+var WorkspaceAttributes = []Attribute{AttrAccessible, AttrMonitor, AttrStandingDesk}
+
+var AttributeNameMap = map[Attribute]string{
+	AttrEvCharger:    "EV charger",
+	AttrAccessible:   "Accessible",
+	AttrCompact:      "Compact",
+	AttrMonitor:      "Monitor/docking station",
+	AttrStandingDesk: "Standing desk",
+}
+
+var attributeBadgeMap = map[Attribute]string{
+	AttrEvCharger:    ":electric_plug:",
+	AttrAccessible:   ":wheelchair:",
+	AttrCompact:      ":blue_car:",
+	AttrMonitor:      ":desktop_computer:",
+	AttrStandingDesk: ":standing_person:",
+}
+
+// AttributeLabel Badge & name of attribute to be used in selectors
+func AttributeLabel(attr Attribute) string {
+	return fmt.Sprintf("%s %s", attributeBadgeMap[attr], AttributeNameMap[attr])
+}
+
+// ParseAttributes Converts values of attribute selectors to attributes
+func ParseAttributes(values []string) ([]Attribute, error) {
+	var attrs []Attribute
+	for _, value := range values {
+		attr := Attribute(strings.TrimSpace(value))
+		if !slices.Contains(AllAttributes, attr) {
+			return nil, fmt.Errorf("unknown attribute %q, expected one of: %v", value, AllAttributes)
+		}
+		if !slices.Contains(attrs, attr) {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs, nil
+}
+
+// HasAttributes Checks if space has all of the attributes
+func (p *Space) HasAttributes(attrs []Attribute) bool {
+	for _, attr := range attrs {
+		if !slices.Contains(p.Attributes, attr) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetAttributesBadges Emoji badges of space attributes in the order of
+// AllAttributes
+func (p *Space) GetAttributesBadges() string {
+	var badges []string
+	for _, attr := range AllAttributes {
+		if slices.Contains(p.Attributes, attr) {
+			badges = append(badges, attributeBadgeMap[attr])
+		}
+	}
+	return strings.Join(badges, " ")
+}
+
+// WithAttributes Returns spaces that have all of the attributes. Spaces
+// reserved by the user are always kept so that they can be released.
+func (s SpacesInfo) WithAttributes(userId string, attrs []Attribute) SpacesInfo {
+	if len(attrs) == 0 {
+		return s
+	}
+
+	filtered := make(SpacesInfo, 0, len(s))
+	for _, space := range s {
+		if space.HasAttributes(attrs) || (space.Reserved && space.ReservedById == userId) {
+			filtered = append(filtered, space)
+		}
+	}
+	return filtered
+}
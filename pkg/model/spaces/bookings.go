@@ -0,0 +1,211 @@
+package spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+)
+
+// DayBooking Whole day reservation of a space for an upcoming date (only
+// used for workspaces). The space is reserved on the reset before the date.
+type DayBooking struct {
+	Id           int
+	SpaceKey     SpaceKey
+	Date         time.Time
+	ReservedBy   string
+	ReservedById string
+}
+
+func (b *DayBooking) String() string {
+	return fmt.Sprintf("%s on %s", b.SpaceKey, b.Date.Format("2006-01-02"))
+}
+
+type DayBookings []*DayBooking
+
+// SpaceBookingOn Returns booking of the space for the date or nil
+func (d *SpacesLot) SpaceBookingOn(spaceKey SpaceKey, date time.Time) *DayBooking {
+	for _, booking := range d.Bookings {
+		if booking.SpaceKey == spaceKey && common.EqualDate(booking.Date, date) {
+			return booking
+		}
+	}
+	return nil
+}
+
+// OwnerUsesOn Checks if the space has a permanent owner who didn't release
+// it for the date. A space handed over in a swap counts as used as well.
+func (d *SpacesLot) OwnerUsesOn(space *Space, date time.Time) bool {
+	owned := (space.Reserved && !space.AutoRelease) ||
+		d.ToBeReleased.HasActiveRelease(space.Key())
+	if !owned {
+		return false
+	}
+
+	for _, release := range d.ReleasedOn(date) {
+		if release.SpaceKey == space.Key() {
+			return release.ReservedForId != ""
+		}
+	}
+	return true
+}
+
+// UserBookingOn Returns booking of the user for the date or nil
+func (d *SpacesLot) UserBookingOn(userId string, date time.Time) *DayBooking {
+	for _, booking := range d.Bookings {
+		if booking.ReservedById == userId && common.EqualDate(booking.Date, date) {
+			return booking
+		}
+	}
+	return nil
+}
+
+// UserDayBookings Returns bookings of the user sorted by date
+func (d *SpacesLot) UserDayBookings(userId string) DayBookings {
+	var bookings DayBookings
+	for _, booking := range d.Bookings {
+		if booking.ReservedById == userId {
+			bookings = append(bookings, booking)
+		}
+	}
+
+	slices.SortFunc(bookings, func(a, b *DayBooking) int {
+		return a.Date.Compare(b.Date)
+	})
+	return bookings
+}
+
+// AddDayBookings Books the space for all dates. Nothing is booked if the
+// space or the user is already booked on any of the dates or if the owner of
+// the space uses it on any of the dates.
+func (d *SpacesLot) AddDayBookings(
+	spaceKey SpaceKey,
+	user, userId string,
+	userGroups []string,
+	dates []time.Time,
+) (DayBookings, error) {
+	space, found := d.UnitSpaces[spaceKey]
+	if !found {
+		return nil, fmt.Errorf("couldn't find the space %s", spaceKey)
+	}
+	if !space.IsEligible(userGroups) {
+		return nil, fmt.Errorf(
+			"%s is reserved for: %s",
+			spaceKey,
+			strings.Join(space.Groups, ", "),
+		)
+	}
+
+	var conflicts []string
+	for _, date := range dates {
+		dateStr := date.Format("Mon 2006-01-02")
+		if d.OwnerUsesOn(space, date) {
+			conflicts = append(
+				conflicts,
+				fmt.Sprintf("%s is used by its owner on %s", spaceKey, dateStr),
+			)
+		} else if booking := d.SpaceBookingOn(spaceKey, date); booking != nil {
+			conflicts = append(
+				conflicts,
+				fmt.Sprintf("%s is booked by %s on %s", spaceKey, booking.ReservedBy, dateStr),
+			)
+		} else if booking := d.UserBookingOn(userId, date); booking != nil {
+			conflicts = append(
+				conflicts,
+				fmt.Sprintf("you already booked %s on %s", booking.SpaceKey, dateStr),
+			)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(conflicts, "; "))
+	}
+
+	id := 1
+	for _, booking := range d.Bookings {
+		id = max(id, booking.Id+1)
+	}
+
+	var added DayBookings
+	for _, date := range dates {
+		booking := &DayBooking{
+			Id:           id,
+			SpaceKey:     spaceKey,
+			Date:         date,
+			ReservedBy:   user,
+			ReservedById: userId,
+		}
+		id++
+		added = append(added, booking)
+	}
+	slog.Info("SPACE_BOOK_DAYS", "user", user, "space", spaceKey, "days", len(added))
+
+	d.Bookings = append(d.Bookings, added...)
+	d.SynchronizeToFile()
+	return added, nil
+}
+
+// CancelDayBooking Removes booking of the user
+func (d *SpacesLot) CancelDayBooking(userId string, id int) (*DayBooking, error) {
+	idx := slices.IndexFunc(d.Bookings, func(booking *DayBooking) bool {
+		return booking.Id == id && booking.ReservedById == userId
+	})
+	if idx == -1 {
+		return nil, fmt.Errorf("couldn't find booking (id=%d)", id)
+	}
+
+	booking := d.Bookings[idx]
+	d.Bookings = slices.Delete(d.Bookings, idx, idx+1)
+	slog.Info("SPACE_CANCEL_DAY", "userId", userId, "booking", booking)
+
+	d.SynchronizeToFile()
+	return booking, nil
+}
+
+// ApplyDayBookings Reserves spaces for bookings on the given date. Only
+// floors which reset at resetTime are handled. Applied bookings, bookings
+// for which the space is not free & bookings of past dates are removed.
+func (d *SpacesLot) ApplyDayBookings(
+	date time.Time,
+	resetTime calendar.TimeOfDay,
+) (applied, failed DayBookings) {
+	var handled DayBookings
+	for _, booking := range d.Bookings {
+		space, found := d.UnitSpaces[booking.SpaceKey]
+		if found && d.ResetTime(MakeFloorStr(space.Floor)) != resetTime {
+			continue
+		}
+		if !found || booking.Date.Before(date) {
+			slog.Info("Removing stale booking", "booking", booking)
+			handled = append(handled, booking)
+			continue
+		}
+		if !common.EqualDate(booking.Date, date) {
+			continue
+		}
+
+		handled = append(handled, booking)
+		if space.Reserved || len(space.Slots) > 0 {
+			slog.Error("Booked space is not free", "booking", booking)
+			failed = append(failed, booking)
+			continue
+		}
+
+		slog.Info("BOOKING_RESERVE", "user", booking.ReservedBy, "space", space.Key(), "booking", booking)
+		space.Reserved = true
+		space.AutoRelease = true
+		space.ReservedBy = booking.ReservedBy
+		space.ReservedById = booking.ReservedById
+		space.ReservedTime = d.clock.Now()
+		applied = append(applied, booking)
+	}
+
+	d.Bookings = slices.DeleteFunc(d.Bookings, func(booking *DayBooking) bool {
+		return slices.Contains(handled, booking)
+	})
+	d.SynchronizeToFile()
+	return applied, failed
+}
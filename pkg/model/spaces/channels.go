@@ -0,0 +1,70 @@
+package spaces
+
+import (
+	"slices"
+
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+)
+
+// ChannelFloors Floors that can be booked from a slack channel
+type ChannelFloors struct {
+	// Name Channel name (only used for display)
+	Name         string
+	Floors       []int
+	DefaultFloor int
+	// Company Team of the channel. Users of this company that book from a
+	// direct message get the floors of this channel.
+	Company user.Company `json:",omitempty"`
+}
+
+// ChannelsMap channel ID -> floors that can be booked from the channel
+type ChannelsMap map[string]*ChannelFloors
+
+// Channel Returns floors mapping of a channel
+func (d *SpacesLot) Channel(channelId string) (*ChannelFloors, bool) {
+	channel, found := d.Channels[channelId]
+	return channel, found
+}
+
+// SetChannel Adds or replaces floors mapping of a channel. If the default
+// floor is not one of the channel floors the first floor is used instead.
+func (d *SpacesLot) SetChannel(channelId string, channel ChannelFloors) {
+	if !slices.Contains(channel.Floors, channel.DefaultFloor) && len(channel.Floors) > 0 {
+		channel.DefaultFloor = channel.Floors[0]
+	}
+	d.Channels[channelId] = &channel
+}
+
+func (d *SpacesLot) RemoveChannel(channelId string) {
+	delete(d.Channels, channelId)
+}
+
+// ChannelIds Returns IDs of all configured channels sorted by channel name
+func (d *SpacesLot) ChannelIds() []string {
+	var ids []string
+	for id := range d.Channels {
+		ids = append(ids, id)
+	}
+
+	slices.SortFunc(ids, func(a, b string) int {
+		if d.Channels[a].Name < d.Channels[b].Name {
+			return -1
+		} else if d.Channels[a].Name > d.Channels[b].Name {
+			return 1
+		}
+		return 0
+	})
+	return ids
+}
+
+// ChannelOfCompanies Finds the first channel which belongs to one of the
+// given companies (i.e. user's team)
+func (d *SpacesLot) ChannelOfCompanies(companies []user.Company) (string, bool) {
+	for _, id := range d.ChannelIds() {
+		company := d.Channels[id].Company
+		if company != user.UnknownCompany && slices.Contains(companies, company) {
+			return id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,208 @@
+package spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+)
+
+// PendingCheckIn Reservation which is released if the user does not check in
+// before the end of the check-in window
+type PendingCheckIn struct {
+	SpaceKey     SpaceKey
+	ReservedBy   string
+	ReservedById string
+	// ReservedTime Used to detect that the space was released & reserved
+	// again after the check-in was requested. For slots this is the time of
+	// the latest slot reservation.
+	ReservedTime time.Time
+	// Slots Slots of the space reserved by the user. Empty for whole day
+	// reservations.
+	Slots []calendar.TimeSlot `json:",omitempty"`
+}
+
+// PendingCheckIns Check-in requests by space & user (multiple users can
+// reserve slots of the same space)
+type PendingCheckIns map[string]*PendingCheckIn
+
+func checkInKey(spaceKey SpaceKey, userId string) string {
+	return fmt.Sprintf("%s/%s", spaceKey, userId)
+}
+
+// SetCheckInWindow Sets part of the day in which users have to check in to
+// their reservations. nil means check-in is not required.
+func (d *SpacesLot) SetCheckInWindow(window *calendar.TimeSlot) {
+	d.checkInWindow = window
+}
+
+// CheckInWindow Returns part of the day in which users have to check in or
+// nil if check-in is not required
+func (d *SpacesLot) CheckInWindow() *calendar.TimeSlot {
+	return d.checkInWindow
+}
+
+// RequireCheckIns Requests check-in for all current reservations that are
+// released automatically: reservations (also of temporarily released spaces),
+// reserved slots, day & team bookings. Permanent spaces of their owners,
+// spaces handed over in swaps of permanent spaces (the users are owners as
+// well) & guest reservations don't need a check-in. Previous requests are
+// dropped. Returns the requests sorted by user & space.
+func (d *SpacesLot) RequireCheckIns() []*PendingCheckIn {
+	d.CheckIns = PendingCheckIns{}
+	var pending []*PendingCheckIn
+	for _, space := range d.UnitSpaces {
+		if space.Reserved && space.AutoRelease && space.Guest == nil &&
+			!d.isSwapHandover(space) {
+			pending = append(pending, &PendingCheckIn{
+				SpaceKey:     space.Key(),
+				ReservedBy:   space.ReservedBy,
+				ReservedById: space.ReservedById,
+				ReservedTime: space.ReservedTime,
+			})
+		}
+		pending = append(pending, slotCheckIns(space)...)
+	}
+	for _, checkIn := range pending {
+		d.CheckIns[checkInKey(checkIn.SpaceKey, checkIn.ReservedById)] = checkIn
+	}
+	slog.Info("CHECK_IN_REQUIRED", "reservations", len(pending))
+
+	slices.SortFunc(pending, func(a, b *PendingCheckIn) int {
+		if c := strings.Compare(a.ReservedById, b.ReservedById); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.SpaceKey), string(b.SpaceKey))
+	})
+	d.SynchronizeToFile()
+	return pending
+}
+
+// isSwapHandover Checks if the space is reserved by the owner to whom it was
+// handed over in a swap of permanent spaces
+func (d *SpacesLot) isSwapHandover(space *Space) bool {
+	release, err := d.ToBeReleased.GetActive(space.Key())
+	return err == nil &&
+		release.ReservedForId != "" &&
+		release.ReservedForId == space.ReservedById
+}
+
+// slotCheckIns Check-in requests for the slots of the space (one per user)
+func slotCheckIns(space *Space) []*PendingCheckIn {
+	var checkIns []*PendingCheckIn
+	for _, reservation := range space.Slots {
+		idx := slices.IndexFunc(checkIns, func(c *PendingCheckIn) bool {
+			return c.ReservedById == reservation.ReservedById
+		})
+		if idx == -1 {
+			checkIns = append(checkIns, &PendingCheckIn{
+				SpaceKey:     space.Key(),
+				ReservedBy:   reservation.ReservedBy,
+				ReservedById: reservation.ReservedById,
+			})
+			idx = len(checkIns) - 1
+		}
+
+		checkIn := checkIns[idx]
+		checkIn.Slots = append(checkIn.Slots, reservation.Slot)
+		if reservation.ReservedTime.After(checkIn.ReservedTime) {
+			checkIn.ReservedTime = reservation.ReservedTime
+		}
+	}
+	return checkIns
+}
+
+// isPending Checks if the space is still reserved by the user with the
+// reservation for which the check-in was requested
+func (d *SpacesLot) isPending(checkIn *PendingCheckIn) bool {
+	space, found := d.UnitSpaces[checkIn.SpaceKey]
+	if !found {
+		return false
+	}
+	if len(checkIn.Slots) > 0 {
+		return len(pendingSlots(space, checkIn)) > 0
+	}
+	return space.Reserved &&
+		space.ReservedById == checkIn.ReservedById &&
+		space.ReservedTime.Equal(checkIn.ReservedTime)
+}
+
+// pendingSlots Slots of the check-in which the user still holds with the
+// reservation for which the check-in was requested
+func pendingSlots(space *Space, checkIn *PendingCheckIn) []calendar.TimeSlot {
+	var slots []calendar.TimeSlot
+	for _, reservation := range space.Slots {
+		if reservation.ReservedById == checkIn.ReservedById &&
+			slices.Contains(checkIn.Slots, reservation.Slot) &&
+			!reservation.ReservedTime.After(checkIn.ReservedTime) {
+			slots = append(slots, reservation.Slot)
+		}
+	}
+	return slots
+}
+
+// UserCheckIns Returns check-in requests of reservations the user still
+// holds sorted by space
+func (d *SpacesLot) UserCheckIns(userId string) []*PendingCheckIn {
+	var checkIns []*PendingCheckIn
+	for _, checkIn := range d.CheckIns {
+		if checkIn.ReservedById == userId && d.isPending(checkIn) {
+			checkIns = append(checkIns, checkIn)
+		}
+	}
+
+	slices.SortFunc(checkIns, func(a, b *PendingCheckIn) int {
+		return strings.Compare(string(a.SpaceKey), string(b.SpaceKey))
+	})
+	return checkIns
+}
+
+// CheckIn Confirms that the user uses the reserved space
+func (d *SpacesLot) CheckIn(userId string, spaceKey SpaceKey) error {
+	key := checkInKey(spaceKey, userId)
+	checkIn, found := d.CheckIns[key]
+	if !found || !d.isPending(checkIn) {
+		return fmt.Errorf("there is no reservation of %s waiting for your check-in", spaceKey)
+	}
+
+	slog.Info("CHECK_IN", "user", checkIn.ReservedBy, "space", spaceKey)
+	delete(d.CheckIns, key)
+	d.SynchronizeToFile()
+	return nil
+}
+
+// ReleaseNoShows Releases reservations for which the users did not check in
+// & drops all check-in requests. Returns the released reservations.
+func (d *SpacesLot) ReleaseNoShows() []*PendingCheckIn {
+	var noShows []*PendingCheckIn
+	for _, checkIn := range d.CheckIns {
+		if !d.isPending(checkIn) {
+			continue
+		}
+
+		space := d.UnitSpaces[checkIn.SpaceKey]
+		slog.Info("SPACE_NO_SHOW", "user", checkIn.ReservedBy, "space", checkIn.SpaceKey, "slots", checkIn.Slots)
+		if len(checkIn.Slots) > 0 {
+			slots := pendingSlots(space, checkIn)
+			space.Slots = slices.DeleteFunc(space.Slots, func(r SlotReservation) bool {
+				return r.ReservedById == checkIn.ReservedById && slices.Contains(slots, r.Slot)
+			})
+		} else {
+			d.FreeSpace(space)
+		}
+		noShows = append(noShows, checkIn)
+	}
+	d.CheckIns = nil
+
+	slices.SortFunc(noShows, func(a, b *PendingCheckIn) int {
+		if c := strings.Compare(string(a.SpaceKey), string(b.SpaceKey)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ReservedById, b.ReservedById)
+	})
+	d.SynchronizeToFile()
+	return noShows
+}
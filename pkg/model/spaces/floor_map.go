@@ -0,0 +1,84 @@
+package spaces
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+)
+
+// MapPosition Cell of a space on the floor map grid. X is the column & Y is
+// the row counted from the top left corner.
+type MapPosition struct {
+	X int
+	Y int
+}
+
+type MapSpaceState int
+
+const (
+	MapSpaceFree MapSpaceState = iota
+	MapSpaceTaken
+	// MapSpaceMine Space reserved by (or owned & used by) the user viewing
+	// the map
+	MapSpaceMine
+)
+
+// MapSpace Space as it is drawn on the floor map
+type MapSpace struct {
+	Number   int
+	Position MapPosition
+	State    MapSpaceState
+}
+
+type MapSpaces []MapSpace
+
+// FloorMap Returns spaces of the floor that have a map position & their
+// state as seen by the user. Spaces with some slots reserved are shown as
+// taken.
+func (d *SpacesLot) FloorMap(userId, floor string) MapSpaces {
+	var mapSpaces MapSpaces
+	for _, space := range d.UnitSpaces {
+		if space.MapPosition == nil || MakeFloorStr(space.Floor) != floor {
+			continue
+		}
+
+		state := MapSpaceFree
+		if userId != "" && d.isUsedBy(space, userId) {
+			state = MapSpaceMine
+		} else if space.Reserved || len(space.Slots) > 0 {
+			state = MapSpaceTaken
+		}
+
+		mapSpaces = append(mapSpaces, MapSpace{
+			Number:   space.Number,
+			Position: *space.MapPosition,
+			State:    state,
+		})
+	}
+
+	// NOTE: sorted so that the version doesn't depend on map iteration order
+	slices.SortFunc(mapSpaces, func(a, b MapSpace) int {
+		return a.Number - b.Number
+	})
+	return mapSpaces
+}
+
+// HasFloorMap Checks if any space of the floor has a map position
+func (d *SpacesLot) HasFloorMap(floor string) bool {
+	for _, space := range d.UnitSpaces {
+		if space.MapPosition != nil && MakeFloorStr(space.Floor) == floor {
+			return true
+		}
+	}
+	return false
+}
+
+// Version Returns hash of the map state. It changes whenever a space is
+// moved, added, removed or changes its state.
+func (s MapSpaces) Version() string {
+	hash := fnv.New64a()
+	for _, space := range s {
+		fmt.Fprintf(hash, "%d:%d:%d:%d;", space.Number, space.Position.X, space.Position.Y, space.State)
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
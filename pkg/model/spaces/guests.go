@@ -0,0 +1,195 @@
+package spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+)
+
+// Guest External visitor for whom a space is reserved by a host
+type Guest struct {
+	Name     string
+	Plate    string
+	HostId   string
+	HostName string
+}
+
+// GuestBooking Guest reservation for a given date. The space is assigned on
+// the reset before the date (or immediately if reservations for the date
+// are already being made).
+type GuestBooking struct {
+	Guest
+	Id    int
+	Floor int
+	// Date Day on which the guest arrives
+	Date time.Time
+	// SpaceKey Space assigned to the guest (empty until assigned)
+	SpaceKey SpaceKey `json:",omitempty"`
+}
+
+func (b *GuestBooking) String() string {
+	return fmt.Sprintf(
+		"%s (%s) on %s",
+		b.Name,
+		b.Plate,
+		b.Date.Format("2006-01-02"),
+	)
+}
+
+type GuestBookings []*GuestBooking
+
+// FloorPlanLink Returns link to the plan of the floor (i.e. -1)
+func (d *SpacesLot) FloorPlanLink(floor int) (string, bool) {
+	floorPrefix, _, _ := strings.Cut(MakeFloorStr(floor), " ")
+	link, found := d.FloorPlans[floorPrefix]
+	return link, found
+}
+
+// GuestBookingsInWeek Counts guest bookings of the host in the week of the
+// date
+func (d *SpacesLot) GuestBookingsInWeek(hostId string, date time.Time) int {
+	year, week := date.ISOWeek()
+
+	count := 0
+	for _, booking := range d.Guests {
+		bookingYear, bookingWeek := booking.Date.ISOWeek()
+		if booking.HostId == hostId && bookingYear == year && bookingWeek == week {
+			count++
+		}
+	}
+	return count
+}
+
+// HostGuestBookings Returns guest bookings of the host sorted by date
+func (d *SpacesLot) HostGuestBookings(hostId string) GuestBookings {
+	var bookings GuestBookings
+	for _, booking := range d.Guests {
+		if booking.HostId == hostId {
+			bookings = append(bookings, booking)
+		}
+	}
+
+	slices.SortFunc(bookings, func(a, b *GuestBooking) int {
+		return a.Date.Compare(b.Date)
+	})
+	return bookings
+}
+
+// AddGuestBooking Adds a guest booking without assigning a space
+func (d *SpacesLot) AddGuestBooking(guest Guest, floor int, date time.Time) *GuestBooking {
+	id := 1
+	for _, booking := range d.Guests {
+		id = max(id, booking.Id+1)
+	}
+
+	booking := &GuestBooking{
+		Guest: guest,
+		Id:    id,
+		Floor: floor,
+		Date:  date,
+	}
+	d.Guests = append(d.Guests, booking)
+	return booking
+}
+
+// ReserveForGuest Reserves a free space on the floor of the booking which
+// the host is allowed to reserve
+func (d *SpacesLot) ReserveForGuest(booking *GuestBooking, hostGroups []string) error {
+	var free SpacesInfo
+	for _, space := range d.UnitSpaces {
+		if space.Floor == booking.Floor && !space.Reserved && d.CanReserve(space, hostGroups) {
+			free = append(free, space)
+		}
+	}
+	if len(free) == 0 {
+		return fmt.Errorf("no free space on %s", MakeFloorStr(booking.Floor))
+	}
+
+	slices.SortFunc(free, func(a, b *Space) int {
+		return a.Number - b.Number
+	})
+
+	space := free[0]
+	slog.Info("GUEST_RESERVE", "host", booking.HostName, "space", space.Key(), "booking", booking)
+
+	guest := booking.Guest
+	space.Reserved = true
+	space.AutoRelease = true
+	space.ReservedBy = fmt.Sprintf("%s (guest of %s)", guest.Name, guest.HostName)
+	space.ReservedById = ""
+	space.ReservedTime = d.clock.Now()
+	space.Guest = &guest
+	booking.SpaceKey = space.Key()
+
+	d.SynchronizeToFile()
+	return nil
+}
+
+// AssignGuests Reserves spaces for guest bookings on the given date. Only
+// floors which reset at resetTime are handled. Bookings for which no space
+// could be found are removed.
+func (d *SpacesLot) AssignGuests(
+	date time.Time,
+	resetTime calendar.TimeOfDay,
+	hostGroups func(hostId string) []string,
+) (assigned, failed GuestBookings) {
+	for _, booking := range d.Guests {
+		if booking.SpaceKey != "" || !common.EqualDate(booking.Date, date) ||
+			d.ResetTime(MakeFloorStr(booking.Floor)) != resetTime {
+			continue
+		}
+
+		err := d.ReserveForGuest(booking, hostGroups(booking.HostId))
+		if err != nil {
+			slog.Error("Failed to assign space to guest", "booking", booking, "err", err)
+			failed = append(failed, booking)
+			continue
+		}
+		assigned = append(assigned, booking)
+	}
+
+	d.Guests = slices.DeleteFunc(d.Guests, func(booking *GuestBooking) bool {
+		return slices.Contains(failed, booking)
+	})
+	return assigned, failed
+}
+
+// CancelGuestBooking Removes guest booking of the host & releases the
+// assigned space
+func (d *SpacesLot) CancelGuestBooking(hostId string, id int) (*GuestBooking, error) {
+	idx := slices.IndexFunc(d.Guests, func(booking *GuestBooking) bool {
+		return booking.Id == id && booking.HostId == hostId
+	})
+	if idx == -1 {
+		return nil, fmt.Errorf("couldn't find guest booking (id=%d)", id)
+	}
+
+	booking := d.Guests[idx]
+	d.Guests = slices.Delete(d.Guests, idx, idx+1)
+
+	space, found := d.UnitSpaces[booking.SpaceKey]
+	if found && space.Guest != nil && space.Guest.HostId == hostId &&
+		space.Guest.Name == booking.Name {
+		slog.Info("GUEST_CANCEL", "host", booking.HostName, "space", space.Key(), "booking", booking)
+		d.FreeSpace(space)
+		space.Guest = nil
+	}
+
+	d.SynchronizeToFile()
+	return booking, nil
+}
+
+// pruneGuestBookings Removes guest bookings from previous weeks. Bookings of
+// the current week are kept because they count towards the weekly limit.
+func (d *SpacesLot) pruneGuestBookings(now time.Time) {
+	year, week := now.ISOWeek()
+	d.Guests = slices.DeleteFunc(d.Guests, func(booking *GuestBooking) bool {
+		bookingYear, bookingWeek := booking.Date.ISOWeek()
+		return bookingYear < year || (bookingYear == year && bookingWeek < week)
+	})
+}
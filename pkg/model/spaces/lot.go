@@ -12,7 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
 	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/datafile"
 	"github.com/AngelVI13/slack-bot/pkg/model/my_err"
 )
 
@@ -34,6 +37,43 @@ type SpacesLot struct {
 	Filename     string
 	ToBeReleased ReleaseMap
 	FloorPlans   FloorPlansMap
+	ResetTimes   *ResetTimes `json:",omitempty"`
+	// Channels Slack channels from which spaces can be booked (only used
+	// for workspaces)
+	Channels ChannelsMap `json:",omitempty"`
+	// Guests Guest bookings of the current & upcoming weeks (only used for
+	// parking)
+	Guests GuestBookings `json:",omitempty"`
+	// Swaps Swap requests waiting for the answer of the target user
+	Swaps []*SwapRequest `json:",omitempty"`
+	// Subscriptions Requests of users to be notified about free spaces (only
+	// used for parking)
+	Subscriptions Subscriptions `json:",omitempty"`
+	// Bookings Reservations of upcoming dates (only used for workspaces)
+	Bookings DayBookings `json:",omitempty"`
+	// Neighbourhoods Groups of adjacent spaces which teams can book together
+	// (only used for workspaces)
+	Neighbourhoods Neighbourhoods `json:",omitempty"`
+	// CheckIns Reservations of the current day waiting for the check-in of
+	// the user
+	CheckIns PendingCheckIns `json:",omitempty"`
+	// History Reservations of previous days which count towards quotas
+	History UsedReservations `json:",omitempty"`
+
+	clock clock.Clock
+	// restrictedOpenTime Time of day after which free restricted spaces can
+	// be reserved by everyone (nil means never)
+	restrictedOpenTime *calendar.TimeOfDay
+	// timeSlots Parts of the day which can be reserved separately (empty
+	// means only whole days)
+	timeSlots []calendar.TimeSlot
+	// checkInWindow Part of the day in which users have to check in (nil
+	// means check-in is not required)
+	checkInWindow *calendar.TimeSlot
+	// fileVersion Version of the file when it was last read or written
+	fileVersion datafile.Version
+	// freed Spaces that became free since subscribers were last notified
+	freed map[SpaceKey]bool
 }
 
 func NewSpacesLot() SpacesLot {
@@ -41,35 +81,57 @@ func NewSpacesLot() SpacesLot {
 		UnitSpaces:   make(UnitSpaces),
 		ToBeReleased: make(ReleaseMap),
 		FloorPlans:   make(FloorPlansMap),
+		Channels:     make(ChannelsMap),
+		clock:        clock.Real{},
 	}
 }
 
+// SetClock Sets the clock used for reservation timestamps
+func (d *SpacesLot) SetClock(clk clock.Clock) {
+	d.clock = clk
+}
+
 // NewSpacesLotFromJson Takes json data as input and returns a populated ParkingLot object
 func NewSpacesLotFromJson(data []byte, filename string) SpacesLot {
 	spacesLot := NewSpacesLot()
-	spacesLot.synchronizeFromFile(data)
+	err := spacesLot.synchronizeFromFile(data)
+	if err != nil {
+		log.Fatalf("Could not parse spaces file. Error: %+v", err)
+	}
 	spacesLot.Filename = filename
 	return spacesLot
 }
 
+// SynchronizeToFile Writes lot to file. If the file was changed on disk
+// since it was loaded (i.e. edited by hand) it is not overwritten - the
+// change is picked up by the next reload instead.
 func (d *SpacesLot) SynchronizeToFile() {
 	data, err := json.MarshalIndent(d, "", "\t")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.WriteFile(d.Filename, data, 0o666)
-	if err != nil {
+	d.fileVersion, err = datafile.Write(d.Filename, data, d.fileVersion)
+	if errors.Is(err, datafile.ErrChanged) {
+		slog.Warn("Spaces file was not overwritten", "err", err)
+		return
+	} else if err != nil {
 		log.Fatal(err)
 	}
 	slog.Info("Wrote spaces lot to file", "file", d.Filename)
 }
 
-func (d *SpacesLot) synchronizeFromFile(data []byte) {
+// ChangedOnDisk Checks if the file was changed since it was last read or
+// written by the bot
+func (d *SpacesLot) ChangedOnDisk() bool {
+	return datafile.Changed(d.Filename, d.fileVersion)
+}
+
+func (d *SpacesLot) synchronizeFromFile(data []byte) error {
 	// Unmarshal the provided data into the solid map
 	err := json.Unmarshal(data, d)
 	if err != nil {
-		log.Fatalf("Could not parse spaces file. Error: %+v", err)
+		return err
 	}
 
 	// Do not load any submitted items from to be released map
@@ -88,6 +150,7 @@ func (d *SpacesLot) synchronizeFromFile(data []byte) {
 			delete(d.ToBeReleased, space)
 		}
 	}
+	return nil
 }
 
 func (d *SpacesLot) HasSpace(userId string) bool {
@@ -101,6 +164,17 @@ func (d *SpacesLot) HasSpace(userId string) bool {
 	return userAlreadyReservedSpace
 }
 
+// GetReservedSpace Returns space currently reserved by the user (permanent
+// or temporary) or nil
+func (d *SpacesLot) GetReservedSpace(userId string) *Space {
+	for _, space := range d.UnitSpaces {
+		if space.Reserved && space.ReservedById == userId {
+			return space
+		}
+	}
+	return nil
+}
+
 func (d *SpacesLot) HasPermanentSpace(userId string) *Space {
 	for _, space := range d.UnitSpaces {
 		if space.Reserved && space.ReservedById == userId && !space.AutoRelease {
@@ -169,6 +243,40 @@ func (d *SpacesLot) HasTempRelease(userId string) *Space {
 	return nil
 }
 
+// ReleasedOn Returns submitted (not cancelled) releases covering the date
+// sorted by space
+func (d *SpacesLot) ReleasedOn(date time.Time) []ReleaseInfo {
+	date = common.DateOf(date)
+
+	var released []ReleaseInfo
+	for spaceKey := range d.ToBeReleased {
+		for _, release := range d.ToBeReleased.GetAll(spaceKey) {
+			if !release.Submitted || !release.DataPresent() || release.Cancelled {
+				continue
+			}
+			if date.Before(common.DateOf(*release.StartDate)) ||
+				common.DateOf(*release.EndDate).Before(date) {
+				continue
+			}
+			released = append(released, release)
+		}
+	}
+
+	slices.SortFunc(released, func(a, b ReleaseInfo) int {
+		spaceA, spaceB := d.UnitSpaces[a.SpaceKey], d.UnitSpaces[b.SpaceKey]
+		if spaceA == nil || spaceB == nil {
+			return strings.Compare(string(a.SpaceKey), string(b.SpaceKey))
+		}
+		if spaceA.Smaller(spaceB) {
+			return -1
+		} else if spaceB.Smaller(spaceA) {
+			return 1
+		}
+		return 0
+	})
+	return released
+}
+
 func (d *SpacesLot) GetSpacesByFloor(
 	userId, floor string,
 	spaceType SpaceType,
@@ -186,18 +294,19 @@ func (d *SpacesLot) GetSpacesByFloor(
 			continue
 		}
 
-		if space.Reserved && space.ReservedById == userId {
+		if d.isUsedBy(space, userId) {
 			floorSpaces = append(floorSpaces, space)
 			// its already added so skip it
 			continue
 		}
 
+		// NOTE: space with some of its slots reserved is both free & taken
 		addCondition := false
 		switch spaceType {
 		case SpaceFree:
-			addCondition = !space.Reserved
+			addCondition = !d.IsTaken(space)
 		case SpaceTaken:
-			addCondition = space.Reserved
+			addCondition = space.Reserved || len(space.Slots) > 0
 		case SpaceAny:
 			addCondition = true
 		default:
@@ -215,22 +324,22 @@ func (d *SpacesLot) GetSpacesInfo(userId string) SpacesInfo {
 	// The group that doesn't belong to user will be sorted by name and by status (reserved or not)
 	userSpaces := make(SpacesInfo, 0)
 	nonUserSpaces := make(SpacesInfo, 0)
-	for _, d := range d.UnitSpaces {
-		if d.Reserved && d.ReservedById == userId {
-			userSpaces = append(userSpaces, d)
+	for _, space := range d.UnitSpaces {
+		if d.isUsedBy(space, userId) {
+			userSpaces = append(userSpaces, space)
 		} else {
-			nonUserSpaces = append(nonUserSpaces, d)
+			nonUserSpaces = append(nonUserSpaces, space)
 		}
 	}
 
 	// NOTE: This sorts the spaces list starting from free spaces
 	sort.Slice(nonUserSpaces, func(i, j int) bool {
-		return !nonUserSpaces[i].Reserved
+		return !d.IsTaken(nonUserSpaces[i])
 	})
 
 	firstTaken := -1 // Index of first taken space
 	for i, space := range nonUserSpaces {
-		if space.Reserved {
+		if d.IsTaken(space) {
 			firstTaken = i
 			break
 		}
@@ -265,9 +374,18 @@ func (d *SpacesLot) GetSpacesInfo(userId string) SpacesInfo {
 
 // NOTE: the implementation looks stupid but can't think of better way
 func (d *SpacesLot) GetAllFloors() []string {
+	var floors []string
+	for _, floor := range d.GetAllFloorNumbers() {
+		floors = append(floors, MakeFloorStr(floor))
+	}
+
+	return floors
+}
+
+// GetAllFloorNumbers Returns sorted numbers of all floors that have spaces
+func (d *SpacesLot) GetAllFloorNumbers() []int {
 	floorMap := map[int]int{}
 	var floorsNum []int
-	var floors []string
 
 	for _, space := range d.UnitSpaces {
 		floorMap[space.Floor] = 1
@@ -278,11 +396,7 @@ func (d *SpacesLot) GetAllFloors() []string {
 	}
 	slices.Sort(floorsNum)
 
-	for _, floor := range floorsNum {
-		floors = append(floors, MakeFloorStr(floor))
-	}
-
-	return floors
+	return floorsNum
 }
 
 // GetExistingFloors Find all existing floors that are present in the allowed
@@ -313,9 +427,12 @@ func (d *SpacesLot) GetExistingFloors(allowedFloors []int) []string {
 	return floors
 }
 
+// Reserve Reserves the space for the user. userGroups are the groups of the
+// user which are checked against restrictions of the space.
 func (l *SpacesLot) Reserve(
 	unitSpace SpaceKey,
 	user, userId string,
+	userGroups []string,
 	autoRelease bool,
 ) (errMsg string) {
 	space := l.GetSpace(unitSpace)
@@ -337,6 +454,25 @@ func (l *SpacesLot) Reserve(
 			reservedTime,
 		)
 	}
+
+	for _, reservation := range space.Slots {
+		if reservation.ReservedById != userId {
+			return fmt.Sprintf(
+				"*Error*: Could not reserve *%s* for the whole day. *%s* has reserved it for %s",
+				unitSpace,
+				reservation.ReservedBy,
+				reservation.Slot,
+			)
+		}
+	}
+
+	if !space.Reserved && !l.CanReserve(space, userGroups) {
+		return fmt.Sprintf(
+			"*Error*: Could not reserve *%s*. It is reserved for: *%s*",
+			unitSpace,
+			strings.Join(space.Groups, ", "),
+		)
+	}
 	slog.Info(
 		"SPACE_RESERVE",
 		"user",
@@ -350,8 +486,10 @@ func (l *SpacesLot) Reserve(
 	space.Reserved = true
 	space.ReservedBy = user
 	space.ReservedById = userId
-	space.ReservedTime = time.Now()
+	space.ReservedTime = l.clock.Now()
 	space.AutoRelease = autoRelease
+	// NOTE: whole day reservation replaces own slot reservations
+	space.Slots = nil
 
 	l.SynchronizeToFile()
 	return ""
@@ -371,9 +509,24 @@ func (l *SpacesLot) Release(
 
 	slog.Info("SPACE_RELEASE", "user", userName, "space", unitSpace)
 
-	space.Reserved = false
+	l.FreeSpace(space)
+	guest := space.Guest
+	space.Guest = nil
 	l.SynchronizeToFile()
 
+	if guest != nil {
+		if guest.HostId == userId {
+			return "", ""
+		}
+		return guest.HostId,
+			fmt.Sprintf(
+				":warning: *%s* released the space (*%s*) of your guest *%s*",
+				userName,
+				unitSpace,
+				guest.Name,
+			)
+	}
+
 	if space.ReservedById != userId {
 		return space.ReservedById,
 			fmt.Sprintf(
@@ -395,8 +548,16 @@ func (l *SpacesLot) GetSpace(unitSpace SpaceKey) *Space {
 	return space
 }
 
-func (l *SpacesLot) ReleaseSpaces(cTime time.Time) error {
+// ReleaseSpaces Daily reset of all spaces on floors which reset at
+// resetTime. Clears auto release reservations and activates/returns temporary
+// releases for the next working day.
+func (l *SpacesLot) ReleaseSpaces(
+	cTime time.Time,
+	resetTime calendar.TimeOfDay,
+	cal *calendar.Calendar,
+) error {
 	var errs []error
+	nextWorkingDay := cal.NextWorkingDay(cTime)
 
 	for spaceKey, space := range l.UnitSpaces {
 		if space == nil {
@@ -404,14 +565,27 @@ func (l *SpacesLot) ReleaseSpaces(cTime time.Time) error {
 			errs = append(errs, err)
 			continue
 		}
+
+		if l.ResetTime(MakeFloorStr(space.Floor)) != resetTime {
+			continue
+		}
+
 		// Simple case
 		if space.Reserved && space.AutoRelease {
 			slog.Info("AutoRelease", "space", spaceKey)
+			l.recordUsed(space, cTime)
+			// NOTE: the daily reset frees all day reservations -> subscribers
+			// are only notified about spaces released by their users
 			space.Reserved = false
 			space.AutoRelease = false
+			space.Guest = nil
 			// Fall-through to check if this is also a temporary
 			// released space has to be reserved
 		}
+		if len(space.Slots) > 0 {
+			slog.Info("AutoRelease slots", "space", spaceKey, "slots", len(space.Slots))
+			space.Slots = nil
+		}
 
 		allReleases := l.ToBeReleased.GetAll(spaceKey)
 		var allValidReleases []ReleaseInfo
@@ -439,61 +613,88 @@ func (l *SpacesLot) ReleaseSpaces(cTime time.Time) error {
 		})
 		// If a scheduled release was setup
 		for _, release := range allValidReleases {
-			l.ReleaseTemp(space, cTime, release)
+			l.ReleaseTemp(space, cTime, nextWorkingDay, release)
 		}
 	}
 
+	l.pruneGuestBookings(cTime)
+	l.pruneHistory(cTime)
 	l.SynchronizeToFile()
 	return errors.Join(errs...)
 }
 
+// ReleaseTemp Activates or finishes a temporary release. nextWorkingDay is
+// the date for which spaces are reset i.e. a release ending on Friday is
+// returned to the owner on Friday's reset and a release starting on Monday
+// is activated on Friday's reset.
 func (l *SpacesLot) ReleaseTemp(
 	space *Space,
 	cTime time.Time,
+	nextWorkingDay time.Time,
 	releaseInfo ReleaseInfo,
 ) {
 	spaceKey := releaseInfo.SpaceKey
-	// On the day before the start of the release -> make the space
-	// available for selection
-	if releaseInfo.StartDate.Sub(cTime).Hours() < 24 &&
-		releaseInfo.StartDate.After(cTime) {
-		slog.Info("TempRelease", "space", spaceKey, "releaseInfo", releaseInfo)
-		space.Reserved = false
-		space.AutoRelease = false
-		releaseInfo.MarkActive()
-		l.ToBeReleased.Update(releaseInfo)
-	} else if releaseInfo.EndDate.Sub(cTime).Hours() < 24 && releaseInfo.EndDate.Before(cTime) {
-		// On the day of the end of release -> reserve back the space
+	if releaseInfo.EndDate.Before(nextWorkingDay) {
+		// Release is over by the next working day -> reserve back the space
 		// for the correct user
 		slog.Info("TempReserve (return to owner)", "space", spaceKey, "releaseInfo", releaseInfo)
 		space.Reserved = true
 		space.AutoRelease = false
 		space.ReservedBy = releaseInfo.OwnerName
 		space.ReservedById = releaseInfo.OwnerId
+		space.Guest = nil
 
 		err := l.ToBeReleased.Remove(releaseInfo)
 		if err != nil {
 			slog.Error("Failed removing release info", "space", spaceKey, "err", err)
 		}
+	} else if !releaseInfo.Active && !releaseInfo.StartDate.After(nextWorkingDay) {
+		// Release starts on (or before) the next working day -> make the
+		// space available for selection
+		slog.Info("TempRelease", "space", spaceKey, "releaseInfo", releaseInfo)
+		l.FreeSpace(space)
+		space.AutoRelease = false
+		releaseInfo.MarkActive(cTime)
+		l.ToBeReleased.Update(releaseInfo)
+		l.reserveForSwap(space, releaseInfo)
+	} else if releaseInfo.Active {
+		// Space handed over in a swap is auto released every day -> reserve
+		// it again for the next working day
+		l.reserveForSwap(space, releaseInfo)
 	}
 }
 
-func GetSpacesLot(filename string) (spacesLot SpacesLot) {
-	fileData, err := os.ReadFile(filename)
+func GetSpacesLot(filename string) SpacesLot {
+	spacesLot, err := ReadSpacesLot(filename)
 	if err != nil {
-		log.Fatalf("Could not read spaces file (%s)", filename)
-	}
-
-	spacesLot = NewSpacesLotFromJson(fileData, filename)
-
-	loadedSpacesNum := len(spacesLot.UnitSpaces)
-	if loadedSpacesNum == 0 {
-		log.Fatalf("No spaces found in (%s).", filename)
+		log.Fatal(err)
 	}
 
 	slog.Info(
 		"INIT: Spaces list loaded successfully",
-		"file", filename, "spaces", loadedSpacesNum,
+		"file", filename, "spaces", len(spacesLot.UnitSpaces),
 	)
-	return spacesLot
+	return *spacesLot
+}
+
+// ReadSpacesLot Reads & validates spaces file
+func ReadSpacesLot(filename string) (*SpacesLot, error) {
+	version := datafile.Stat(filename)
+	fileData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spaces file (%s): %w", filename, err)
+	}
+
+	spacesLot := NewSpacesLot()
+	err = spacesLot.synchronizeFromFile(fileData)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse spaces file (%s): %w", filename, err)
+	}
+	spacesLot.Filename = filename
+	spacesLot.fileVersion = version
+
+	if len(spacesLot.UnitSpaces) == 0 {
+		return nil, fmt.Errorf("no spaces found in (%s)", filename)
+	}
+	return &spacesLot, nil
 }
@@ -0,0 +1,1175 @@
+package spaces
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+)
+
+const (
+	testOwner   = "owner"
+	testOwnerId = "U_OWNER"
+	testOther   = "other"
+	testOtherId = "U_OTHER"
+)
+
+// 2026-10-12 is a Monday
+func day(d, hour, min int) time.Time {
+	return time.Date(2026, 10, d, hour, min, 0, 0, time.UTC)
+}
+
+type testRelease struct {
+	start, end int
+	active     bool
+}
+
+type spaceState struct {
+	reservedById string
+	autoRelease  bool
+}
+
+func newTestLot(t *testing.T) (*SpacesLot, *Space) {
+	t.Helper()
+	lot := NewSpacesLot()
+	lot.Filename = filepath.Join(t.TempDir(), "parking.json")
+	lot.SetDefaultResetTime(calendar.TimeOfDay{Hour: 17})
+
+	space := NewSpace(1, 1, "")
+	space.Reserved = true
+	space.ReservedBy = testOwner
+	space.ReservedById = testOwnerId
+	lot.UnitSpaces[space.Key()] = space
+	return &lot, space
+}
+
+func addRelease(
+	t *testing.T,
+	lot *SpacesLot,
+	space *Space,
+	r testRelease,
+	now time.Time,
+) {
+	t.Helper()
+	release := lot.ToBeReleased.Add("viewId", testOwner, testOwnerId, space, now)
+	start := day(r.start, 0, 0)
+	end := day(r.end, 0, 0)
+	release.StartDate = &start
+	release.EndDate = &end
+	release.MarkSubmitted(testOwner, now)
+	if r.active {
+		release.MarkActive(now)
+	}
+	err := lot.ToBeReleased.Update(release)
+	if err != nil {
+		t.Fatalf("failed to add release: %v", err)
+	}
+}
+
+func TestReleaseSpaces(t *testing.T) {
+	easter, err := calendar.NewCalendarFromJson([]byte(
+		`{"Holidays": [{"Date": "2026-10-19", "Name": "Made up holiday"}]}`,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		calendar *calendar.Calendar
+		cTime    time.Time
+		releases []testRelease
+		// space state right before the reset. empty reservedById means free
+		before spaceState
+		after  spaceState
+		// active flag of each remaining release (in order of start date)
+		wantReleases []bool
+	}{
+		{
+			name:         "auto release reservation is cleared",
+			cTime:        day(14, 17, 0),
+			before:       spaceState{testOtherId, true},
+			after:        spaceState{"", false},
+			wantReleases: nil,
+		},
+		{
+			name:         "permanent reservation is kept",
+			cTime:        day(14, 17, 0),
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{testOwnerId, false},
+			wantReleases: nil,
+		},
+		{
+			name:         "release starting tomorrow is activated",
+			cTime:        day(13, 17, 0),
+			releases:     []testRelease{{start: 14, end: 16}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+		{
+			name:         "release starting after next working day is not activated",
+			cTime:        day(13, 17, 0),
+			releases:     []testRelease{{start: 15, end: 16}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{testOwnerId, false},
+			wantReleases: []bool{false},
+		},
+		{
+			name:         "missed release is activated",
+			cTime:        day(14, 17, 0),
+			releases:     []testRelease{{start: 12, end: 16}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+		{
+			name:         "release starting on monday is activated on friday",
+			cTime:        day(16, 17, 0),
+			releases:     []testRelease{{start: 19, end: 21}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+		{
+			name:         "release starting on weekend is activated on friday",
+			cTime:        day(16, 17, 0),
+			releases:     []testRelease{{start: 17, end: 20}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+		{
+			name:         "active release ending tomorrow is kept",
+			cTime:        day(14, 17, 0),
+			releases:     []testRelease{{start: 12, end: 15, active: true}},
+			before:       spaceState{testOtherId, true},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+		{
+			name:         "release ending today is returned to owner",
+			cTime:        day(14, 17, 0),
+			releases:     []testRelease{{start: 12, end: 14, active: true}},
+			before:       spaceState{testOtherId, true},
+			after:        spaceState{testOwnerId, false},
+			wantReleases: nil,
+		},
+		{
+			name:         "release ending on sunday is returned on friday",
+			cTime:        day(16, 17, 0),
+			releases:     []testRelease{{start: 12, end: 18, active: true}},
+			before:       spaceState{"", false},
+			after:        spaceState{testOwnerId, false},
+			wantReleases: nil,
+		},
+		{
+			name:         "weekend only release is dropped on friday",
+			cTime:        day(16, 17, 0),
+			releases:     []testRelease{{start: 17, end: 18}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{testOwnerId, false},
+			wantReleases: nil,
+		},
+		{
+			name:         "release ending on holiday monday is returned on friday",
+			calendar:     easter,
+			cTime:        day(16, 17, 0),
+			releases:     []testRelease{{start: 12, end: 19, active: true}},
+			before:       spaceState{"", false},
+			after:        spaceState{testOwnerId, false},
+			wantReleases: nil,
+		},
+		{
+			name:         "release starting after holiday is activated on friday",
+			calendar:     easter,
+			cTime:        day(16, 17, 0),
+			releases:     []testRelease{{start: 20, end: 21}},
+			before:       spaceState{testOwnerId, false},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+		{
+			name:  "consecutive releases: first is returned & second activated",
+			cTime: day(14, 17, 0),
+			releases: []testRelease{
+				{start: 12, end: 14, active: true},
+				{start: 15, end: 16},
+			},
+			before:       spaceState{testOtherId, true},
+			after:        spaceState{"", false},
+			wantReleases: []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := tt.calendar
+			if cal == nil {
+				cal = calendar.NewCalendar()
+			}
+
+			lot, space := newTestLot(t)
+			for _, r := range tt.releases {
+				addRelease(t, lot, space, r, day(12, 9, 0))
+			}
+
+			space.Reserved = tt.before.reservedById != ""
+			space.ReservedById = tt.before.reservedById
+			space.AutoRelease = tt.before.autoRelease
+
+			err := lot.ReleaseSpaces(tt.cTime, calendar.TimeOfDayOf(tt.cTime), cal)
+			if err != nil {
+				t.Fatalf("ReleaseSpaces: %v", err)
+			}
+
+			wantReserved := tt.after.reservedById != ""
+			if space.Reserved != wantReserved ||
+				(wantReserved && space.ReservedById != tt.after.reservedById) ||
+				space.AutoRelease != tt.after.autoRelease {
+				t.Errorf(
+					"space = {Reserved:%v ReservedById:%q AutoRelease:%v}; want %+v",
+					space.Reserved,
+					space.ReservedById,
+					space.AutoRelease,
+					tt.after,
+				)
+			}
+
+			remaining := lot.ToBeReleased.GetAll(space.Key())
+			if len(remaining) != len(tt.wantReleases) {
+				t.Fatalf("got %d releases; want %d", len(remaining), len(tt.wantReleases))
+			}
+			for i, active := range tt.wantReleases {
+				if remaining[i].Active != active {
+					t.Errorf("release %d active = %v; want %v", i, remaining[i].Active, active)
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseSpacesPerFloorResetTime(t *testing.T) {
+	lot, space := newTestLot(t)
+	basement := NewSpace(1, -1, "")
+	lot.UnitSpaces[basement.Key()] = basement
+
+	lateReset := calendar.TimeOfDay{Hour: 18, Min: 30}
+	lot.SetFloorResetTime(MakeFloorStr(-1), lateReset)
+
+	want := []calendar.TimeOfDay{{Hour: 17}, lateReset}
+	if got := lot.AllResetTimes(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("AllResetTimes() = %v; want %v", got, want)
+	}
+
+	for _, s := range []*Space{space, basement} {
+		s.Reserved = true
+		s.AutoRelease = true
+		s.ReservedById = testOtherId
+	}
+
+	cal := calendar.NewCalendar()
+	err := lot.ReleaseSpaces(day(14, 17, 0), calendar.TimeOfDay{Hour: 17}, cal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if space.Reserved || !basement.Reserved {
+		t.Fatalf("after 17:00 reset: 1st floor reserved=%v; -1st floor reserved=%v", space.Reserved, basement.Reserved)
+	}
+
+	err = lot.ReleaseSpaces(day(14, 18, 30), lateReset, cal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if basement.Reserved {
+		t.Errorf("-1st floor not reset at %s", lateReset)
+	}
+
+	// setting floor reset time back to default removes the floor override
+	lot.SetFloorResetTime(MakeFloorStr(-1), calendar.TimeOfDay{Hour: 17})
+	if got := lot.AllResetTimes(); len(got) != 1 {
+		t.Errorf("AllResetTimes() = %v; want only default", got)
+	}
+}
+
+func TestDefaultResetTimeOfConfig(t *testing.T) {
+	lot, _ := newTestLot(t)
+	configTime := calendar.TimeOfDay{Hour: 17}
+	adminTime := calendar.TimeOfDay{Hour: 18}
+
+	// config changes are applied until an admin changes the default
+	lot.SetDefaultResetTime(calendar.TimeOfDay{Hour: 16})
+	if lot.ResetTimes.Default != (calendar.TimeOfDay{Hour: 16}) {
+		t.Fatalf("expected reset time of config, got %v", lot.ResetTimes.Default)
+	}
+
+	lot.ChangeDefaultResetTime(adminTime, configTime)
+	lot.SetDefaultResetTime(configTime)
+	if lot.ResetTimes.Default != adminTime {
+		t.Fatalf("expected reset time of admin, got %v", lot.ResetTimes.Default)
+	}
+	if got := lot.AllResetTimesFor(configTime); !slices.Equal(got, []calendar.TimeOfDay{adminTime}) {
+		t.Fatalf("expected reset times of admin, got %v", got)
+	}
+
+	// setting the time of config again makes the config apply
+	lot.ChangeDefaultResetTime(configTime, configTime)
+	newConfigTime := calendar.TimeOfDay{Hour: 16, Min: 30}
+	if got := lot.AllResetTimesFor(newConfigTime); !slices.Equal(got, []calendar.TimeOfDay{newConfigTime}) {
+		t.Fatalf("expected reset times of config, got %v", got)
+	}
+	lot.SetDefaultResetTime(newConfigTime)
+	if lot.ResetTimes.Default != newConfigTime {
+		t.Fatalf("expected reset time of config, got %v", lot.ResetTimes.Default)
+	}
+}
+
+func TestReserveRestricted(t *testing.T) {
+	lot, space := newTestLot(t)
+	space.Reserved = false
+	space.Groups = []string{"management"}
+
+	clk := clock.NewFake(day(14, 9, 0))
+	lot.SetClock(clk)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		openTime   *calendar.TimeOfDay
+		userGroups []string
+		allowed    bool
+	}{
+		{"member of group", day(14, 9, 0), nil, []string{"visitors", "management"}, true},
+		{"not member of group", day(14, 9, 0), nil, []string{"visitors"}, false},
+		{"before open time", day(14, 9, 59), &calendar.TimeOfDay{Hour: 10}, nil, false},
+		{"after open time", day(14, 10, 0), &calendar.TimeOfDay{Hour: 10}, nil, true},
+		{"after reset", day(14, 17, 0), &calendar.TimeOfDay{Hour: 10}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			space.Reserved = false
+			clk.Set(tt.now)
+			lot.SetRestrictedOpenTime(tt.openTime)
+
+			errTxt := lot.Reserve(space.Key(), testOther, testOtherId, tt.userGroups, true)
+			if (errTxt == "") != tt.allowed {
+				t.Fatalf("expected allowed=%v, got error: %q", tt.allowed, errTxt)
+			}
+			if space.Reserved != tt.allowed {
+				t.Fatalf("expected reserved=%v", tt.allowed)
+			}
+		})
+	}
+}
+
+func TestAssignGuests(t *testing.T) {
+	lot, space := newTestLot(t)
+	space.Reserved = false
+	lot.SetClock(clock.NewFake(day(14, 17, 0)))
+
+	second := NewSpace(2, 1, "")
+	lot.UnitSpaces[second.Key()] = second
+
+	noGroups := func(string) []string { return nil }
+	guest := Guest{Name: "Guest", Plate: "ABC123", HostId: testOtherId, HostName: testOther}
+
+	first := lot.AddGuestBooking(guest, 1, day(15, 0, 0))
+	other := lot.AddGuestBooking(guest, 1, day(16, 0, 0))
+	third := lot.AddGuestBooking(guest, 1, day(15, 0, 0))
+	overflow := lot.AddGuestBooking(guest, 1, day(15, 0, 0))
+
+	if count := lot.GuestBookingsInWeek(testOtherId, day(12, 0, 0)); count != 4 {
+		t.Fatalf("expected 4 guest bookings in week, got %d", count)
+	}
+
+	assigned, failed := lot.AssignGuests(day(15, 0, 0), calendar.TimeOfDay{Hour: 17}, noGroups)
+	if len(assigned) != 2 || assigned[0] != first || assigned[1] != third {
+		t.Fatalf("expected first & third booking to be assigned, got %v", assigned)
+	}
+	if len(failed) != 1 || failed[0] != overflow {
+		t.Fatalf("expected overflow booking to fail, got %v", failed)
+	}
+	if first.SpaceKey != space.Key() || third.SpaceKey != second.Key() {
+		t.Fatalf("expected lowest free spaces to be assigned, got %s & %s", first.SpaceKey, third.SpaceKey)
+	}
+	if other.SpaceKey != "" {
+		t.Fatalf("expected booking on another date to stay unassigned")
+	}
+	if len(lot.Guests) != 3 {
+		t.Fatalf("expected failed booking to be removed, got %d bookings", len(lot.Guests))
+	}
+	if space.ReservedById != "" || space.Guest == nil || !space.AutoRelease {
+		t.Fatalf("expected space to be reserved for guest: %+v", space)
+	}
+
+	lot.freed = nil
+	_, err := lot.CancelGuestBooking(testOtherId, first.Id)
+	if err != nil {
+		t.Fatalf("failed to cancel guest booking: %v", err)
+	}
+	if space.Reserved || space.Guest != nil {
+		t.Fatalf("expected space to be released after cancellation")
+	}
+	if !lot.freed[space.Key()] {
+		t.Fatalf("expected cancelled guest space to be notified to subscribers")
+	}
+}
+
+func TestApplySwap(t *testing.T) {
+	t.Run("reservations", func(t *testing.T) {
+		lot, space := newTestLot(t)
+		space.AutoRelease = true
+		lot.SetClock(clock.NewFake(day(14, 10, 0)))
+
+		request := &SwapRequest{
+			FromId:   testOwnerId,
+			FromName: testOwner,
+			ToId:     testOtherId,
+			ToName:   testOther,
+		}
+		result, err := lot.ApplySwap(request, day(14, 0, 0), day(14, 10, 0))
+		if err != nil {
+			t.Fatalf("failed to swap reservations: %v", err)
+		}
+		if result.Given != space || result.Received != nil {
+			t.Fatalf("unexpected swap result %+v", result)
+		}
+		if space.ReservedById != testOtherId || !space.AutoRelease {
+			t.Fatalf("expected reservation to be handed over: %+v", space)
+		}
+
+		_, err = lot.ApplySwap(request, day(14, 0, 0), day(14, 10, 0))
+		if err == nil {
+			t.Fatalf("expected swap to fail once reservation was handed over")
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		lot, space := newTestLot(t)
+		now := day(14, 10, 0)
+		lot.SetClock(clock.NewFake(now))
+
+		other := NewSpace(2, 1, "")
+		other.Reserved = true
+		other.ReservedBy = testOther
+		other.ReservedById = testOtherId
+		lot.UnitSpaces[other.Key()] = other
+
+		start := day(14, 0, 0)
+		end := day(15, 0, 0)
+		request := &SwapRequest{
+			FromId:    testOwnerId,
+			FromName:  testOwner,
+			ToId:      testOtherId,
+			ToName:    testOther,
+			StartDate: &start,
+			EndDate:   &end,
+		}
+		result, err := lot.ApplySwap(request, day(14, 0, 0), now)
+		if err != nil {
+			t.Fatalf("failed to swap spaces: %v", err)
+		}
+		if result.Given != space || result.Received != other {
+			t.Fatalf("unexpected swap result %+v", result)
+		}
+
+		want := map[*Space]spaceState{
+			space: {reservedById: testOtherId, autoRelease: true},
+			other: {reservedById: testOwnerId, autoRelease: true},
+		}
+		for s, state := range want {
+			if s.ReservedById != state.reservedById || s.AutoRelease != state.autoRelease {
+				t.Fatalf("space %s: expected %+v after swap, got %+v", s.Key(), state, s)
+			}
+		}
+
+		release, err := lot.ToBeReleased.GetActive(space.Key())
+		if err != nil {
+			t.Fatalf("expected active release of %s: %v", space.Key(), err)
+		}
+		paired, err := lot.ToBeReleased.GetPaired(release)
+		if err != nil || paired.SpaceKey != other.Key() || paired.OwnerId != testOtherId {
+			t.Fatalf("expected paired release of %s, got %+v (%v)", other.Key(), paired, err)
+		}
+
+		// swapped spaces are reserved for each other until the end date
+		cal := calendar.NewCalendar()
+		resetTime := calendar.TimeOfDay{Hour: 17}
+		lot.SetClock(clock.NewFake(day(14, 17, 0)))
+		err = lot.ReleaseSpaces(day(14, 17, 0), resetTime, cal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for s, state := range want {
+			if s.ReservedById != state.reservedById {
+				t.Fatalf("space %s: expected %+v after 1st reset, got %+v", s.Key(), state, s)
+			}
+		}
+
+		lot.SetClock(clock.NewFake(day(15, 17, 0)))
+		err = lot.ReleaseSpaces(day(15, 17, 0), resetTime, cal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if space.ReservedById != testOwnerId || other.ReservedById != testOtherId {
+			t.Fatalf("expected spaces to be returned to owners: %+v, %+v", space, other)
+		}
+	})
+}
+
+func TestFreeSpaceNotifications(t *testing.T) {
+	lot, space := newTestLot(t)
+	now := day(14, 10, 0)
+	lot.SetClock(clock.NewFake(now))
+	cal := calendar.NewCalendar()
+	throttle := time.Hour
+	noGroups := func(string) []string { return nil }
+
+	charger := NewSpace(2, 1, "", AttrEvCharger)
+	charger.Reserved = true
+	charger.ReservedBy = testOwner
+	charger.ReservedById = "U_CHARGER_OWNER"
+	lot.UnitSpaces[charger.Key()] = charger
+
+	const thirdId = "U_THIRD"
+	_, err := lot.AddSubscription(Subscription{UserId: testOtherId, Floor: MakeFloorStr(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lot.AddSubscription(Subscription{
+		UserId:     thirdId,
+		Attributes: []Attribute{AttrEvCharger},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lot.AddSubscription(Subscription{
+		UserId:     thirdId,
+		Attributes: []Attribute{AttrEvCharger},
+	})
+	if err == nil {
+		t.Fatalf("expected duplicate subscription to fail")
+	}
+	// charger is not compact -> does not match all attributes
+	const fourthId = "U_FOURTH"
+	_, err = lot.AddSubscription(Subscription{
+		UserId:     fourthId,
+		Attributes: []Attribute{AttrEvCharger, AttrCompact},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lot.Release(space.Key(), testOwner, testOwnerId)
+	notifications := lot.FreeSpaceNotifications(now, throttle, cal, noGroups)
+	if len(notifications) != 1 || notifications[0].UserId != testOtherId {
+		t.Fatalf("expected only floor subscriber to be notified, got %+v", notifications)
+	}
+	if free := notifications[0].Spaces; len(free) != 1 || free[0].Space != space ||
+		!common.EqualDate(free[0].Date, day(14, 0, 0)) {
+		t.Fatalf("expected space %s for today, got %+v", space.Key(), free)
+	}
+	if len(lot.FreeSpaceNotifications(now, throttle, cal, noGroups)) != 0 {
+		t.Fatalf("expected freed spaces to be notified only once")
+	}
+
+	// notified user is throttled & users with a space are skipped
+	lot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+	lot.Release(charger.Key(), testOwner, "U_CHARGER_OWNER")
+	later := now.Add(30 * time.Minute)
+	notifications = lot.FreeSpaceNotifications(later, throttle, cal, noGroups)
+	if len(notifications) != 1 || notifications[0].UserId != thirdId {
+		t.Fatalf("expected only attribute subscriber to be notified, got %+v", notifications)
+	}
+
+	lot.Release(space.Key(), testOther, testOtherId)
+	notifications = lot.FreeSpaceNotifications(later, throttle, cal, noGroups)
+	if len(notifications) != 0 {
+		t.Fatalf("expected notifications to be throttled, got %+v", notifications)
+	}
+
+	lot.Reserve(space.Key(), testOwner, testOwnerId, nil, false)
+	lot.Release(space.Key(), testOwner, testOwnerId)
+	notifications = lot.FreeSpaceNotifications(now.Add(throttle), throttle, cal, noGroups)
+	if len(notifications) != 1 || notifications[0].UserId != testOtherId {
+		t.Fatalf("expected floor subscriber to be notified after throttle, got %+v", notifications)
+	}
+}
+
+func TestResetFreeSpaceNotifications(t *testing.T) {
+	lot, space := newTestLot(t)
+	now := day(14, 17, 0)
+	lot.SetClock(clock.NewFake(now))
+	cal := calendar.NewCalendar()
+	noGroups := func(string) []string { return nil }
+
+	booked := NewSpace(2, 1, "")
+	booked.Reserved = true
+	booked.AutoRelease = true
+	booked.ReservedBy = testOther
+	booked.ReservedById = testOtherId
+	lot.UnitSpaces[booked.Key()] = booked
+
+	const subscriberId = "U_SUBSCRIBER"
+	_, err := lot.AddSubscription(Subscription{UserId: subscriberId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addRelease(t, lot, space, testRelease{start: 15, end: 15}, day(13, 10, 0))
+
+	err = lot.ReleaseSpaces(now, calendar.TimeOfDay{Hour: 17}, cal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if booked.Reserved || space.Reserved {
+		t.Fatalf("expected both spaces to be free after reset: %+v, %+v", booked, space)
+	}
+
+	// NOTE: day reservations end with every reset -> only the temporary
+	// release made a space available for tomorrow
+	notifications := lot.FreeSpaceNotifications(now, time.Hour, cal, noGroups)
+	if len(notifications) != 1 || len(notifications[0].Spaces) != 1 ||
+		notifications[0].Spaces[0].Space != space {
+		t.Fatalf("expected only released space %s to be notified, got %+v", space.Key(), notifications)
+	}
+}
+
+func TestReleasedOn(t *testing.T) {
+	lot, space := newTestLot(t)
+	now := day(12, 10, 0)
+
+	other := NewSpace(2, 1, "")
+	other.ReservedBy = testOther
+	other.ReservedById = testOtherId
+	lot.UnitSpaces[other.Key()] = other
+
+	addRelease(t, lot, other, testRelease{start: 13, end: 14}, now)
+	addRelease(t, lot, space, testRelease{start: 13, end: 13}, now)
+	addRelease(t, lot, space, testRelease{start: 15, end: 16}, now)
+	// unsubmitted releases are not shown
+	lot.ToBeReleased.Add("viewId", testOwner, testOwnerId, space, now)
+
+	released := lot.ReleasedOn(day(13, 17, 30))
+	if len(released) != 2 || released[0].SpaceKey != space.Key() ||
+		released[1].SpaceKey != other.Key() {
+		t.Fatalf("expected releases of %s & %s sorted, got %+v", space.Key(), other.Key(), released)
+	}
+
+	released = lot.ReleasedOn(day(15, 0, 0))
+	if len(released) != 1 || !common.EqualDate(*released[0].StartDate, day(15, 0, 0)) {
+		t.Fatalf("expected release starting on 15th, got %+v", released)
+	}
+
+	if released = lot.ReleasedOn(day(17, 0, 0)); len(released) != 0 {
+		t.Fatalf("expected no releases, got %+v", released)
+	}
+}
+
+func TestReleaseReminders(t *testing.T) {
+	lot, starting := newTestLot(t)
+	// Wednesday -> releases starting or ending on Thursday
+	now := day(14, 16, 0)
+	cal := calendar.NewCalendar()
+
+	newOwnedSpace := func(number int) *Space {
+		space := NewSpace(number, 1, "")
+		space.Reserved = true
+		space.ReservedBy = testOwner
+		space.ReservedById = testOwnerId
+		lot.UnitSpaces[space.Key()] = space
+		return space
+	}
+	ending := newOwnedSpace(2)
+	ongoing := newOwnedSpace(3)
+	automatic := newOwnedSpace(4)
+
+	addRelease(t, lot, starting, testRelease{start: 15, end: 16}, now)
+	addRelease(t, lot, ending, testRelease{start: 12, end: 15, active: true}, now)
+	addRelease(t, lot, ongoing, testRelease{start: 13, end: 16, active: true}, now)
+
+	release := lot.ToBeReleased.Add("viewId", "ParkingBot", BotReleaserId, automatic, now)
+	start, end := day(15, 0, 0), day(15, 0, 0)
+	release.StartDate = &start
+	release.EndDate = &end
+	release.MarkSubmitted("ParkingBot", now)
+	if err := lot.ToBeReleased.Update(release); err != nil {
+		t.Fatal(err)
+	}
+
+	reminders := lot.ReleaseReminders(now, cal)
+	if len(reminders) != 2 {
+		t.Fatalf("expected 2 reminders, got %+v", reminders)
+	}
+	if r := reminders[0]; r.Release.SpaceKey != starting.Key() || !r.Starting ||
+		!common.EqualDate(r.Date, day(15, 0, 0)) {
+		t.Errorf("expected starting reminder for %s, got %+v", starting.Key(), r)
+	}
+	if r := reminders[1]; r.Release.SpaceKey != ending.Key() || r.Starting {
+		t.Errorf("expected ending reminder for %s, got %+v", ending.Key(), r)
+	}
+
+	// Thursday -> release ending on Sunday ends after Friday
+	lot, space := newTestLot(t)
+	addRelease(t, lot, space, testRelease{start: 13, end: 18, active: true}, now)
+	reminders = lot.ReleaseReminders(day(15, 16, 0), cal)
+	if len(reminders) != 1 || reminders[0].Starting ||
+		!common.EqualDate(reminders[0].Date, day(16, 0, 0)) {
+		t.Fatalf("expected release to end after Friday, got %+v", reminders)
+	}
+}
+
+func TestReserveSlots(t *testing.T) {
+	lot, space := newTestLot(t)
+	space.Reserved = false
+	lot.SetClock(clock.NewFake(day(14, 9, 0)))
+
+	morning := calendar.TimeSlot{Start: calendar.TimeOfDay{Hour: 8}, End: calendar.TimeOfDay{Hour: 12}}
+	afternoon := calendar.TimeSlot{Start: calendar.TimeOfDay{Hour: 12}, End: calendar.TimeOfDay{Hour: 17}}
+	lot.SetTimeSlots([]calendar.TimeSlot{morning, afternoon})
+
+	other := NewSpace(2, 1, "")
+	lot.UnitSpaces[other.Key()] = other
+
+	if errTxt := lot.ReserveSlots(space.Key(), testOwner, testOwnerId, nil, []calendar.TimeSlot{afternoon}); errTxt != "" {
+		t.Fatalf("expected afternoon to be reserved, got error: %q", errTxt)
+	}
+	if lot.IsTaken(space) {
+		t.Fatal("expected space with free morning not to be taken")
+	}
+	if free := lot.GetSpacesByFloor(testOtherId, "1st floor", SpaceFree); len(free) != 2 {
+		t.Fatalf("expected both spaces to be free for someone else, got %d", len(free))
+	}
+
+	if errTxt := lot.ReserveSlots(other.Key(), testOwner, testOwnerId, nil, []calendar.TimeSlot{afternoon}); errTxt == "" {
+		t.Fatal("expected error when reserving the same slot on another space")
+	}
+	if errTxt := lot.ReserveSlots(space.Key(), testOther, testOtherId, nil, []calendar.TimeSlot{afternoon}); errTxt == "" {
+		t.Fatal("expected error when reserving slot of someone else")
+	}
+	if errTxt := lot.Reserve(space.Key(), testOther, testOtherId, nil, true); errTxt == "" {
+		t.Fatal("expected error when reserving whole day of a space with slots of someone else")
+	}
+
+	if errTxt := lot.ReserveSlots(space.Key(), testOther, testOtherId, nil, []calendar.TimeSlot{morning}); errTxt != "" {
+		t.Fatalf("expected morning to be reserved, got error: %q", errTxt)
+	}
+	if !lot.IsTaken(space) {
+		t.Fatal("expected space with all slots reserved to be taken")
+	}
+	if space.Slots[0].Slot != morning {
+		t.Fatalf("expected slots ordered by time, got %+v", space.Slots)
+	}
+
+	victimIds, _ := lot.ReleaseSlots(space.Key(), testOwner, testOwnerId, false)
+	if len(victimIds) != 0 || space.SlotReservation(afternoon) != nil ||
+		space.SlotReservation(morning) == nil {
+		t.Fatalf("expected only own slot to be released, got %+v", space.Slots)
+	}
+
+	// whole day reservation replaces own slots
+	if errTxt := lot.Reserve(space.Key(), testOther, testOtherId, nil, true); errTxt != "" {
+		t.Fatalf("expected whole day to be reserved, got error: %q", errTxt)
+	}
+	if len(space.Slots) != 0 {
+		t.Fatalf("expected slots to be replaced, got %+v", space.Slots)
+	}
+	if errTxt := lot.ReserveSlots(other.Key(), testOther, testOtherId, nil, []calendar.TimeSlot{morning}); errTxt == "" {
+		t.Fatal("expected error when reserving slot while having a whole day reservation")
+	}
+
+	lot.ReserveSlots(other.Key(), testOwner, testOwnerId, nil, []calendar.TimeSlot{morning})
+	err := lot.ReleaseSpaces(day(14, 17, 0), calendar.TimeOfDay{Hour: 17}, calendar.NewCalendar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Slots) != 0 {
+		t.Fatalf("expected slots to be cleared at reset, got %+v", other.Slots)
+	}
+}
+
+func TestDayBookings(t *testing.T) {
+	lot, space := newTestLot(t)
+	space.Reserved = false
+	lot.SetClock(clock.NewFake(day(14, 17, 0)))
+
+	other := NewSpace(2, 1, "")
+	lot.UnitSpaces[other.Key()] = other
+
+	_, err := lot.AddDayBookings(space.Key(), testOwner, testOwnerId, nil, []time.Time{day(15, 0, 0), day(16, 0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is booked if any of the dates conflicts
+	_, err = lot.AddDayBookings(space.Key(), testOther, testOtherId, nil, []time.Time{day(16, 0, 0), day(19, 0, 0)})
+	if err == nil || len(lot.Bookings) != 2 {
+		t.Fatalf("expected conflict & no new bookings, got err=%v bookings=%d", err, len(lot.Bookings))
+	}
+	_, err = lot.AddDayBookings(other.Key(), testOwner, testOwnerId, nil, []time.Time{day(15, 0, 0)})
+	if err == nil {
+		t.Fatal("expected error when the user already booked a space on the date")
+	}
+
+	booking := lot.UserBookingOn(testOwnerId, day(16, 0, 0))
+	if _, err := lot.CancelDayBooking(testOtherId, booking.Id); err == nil {
+		t.Fatal("expected error when cancelling booking of someone else")
+	}
+	if _, err := lot.CancelDayBooking(testOwnerId, booking.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, failed := lot.ApplyDayBookings(day(15, 0, 0), calendar.TimeOfDay{Hour: 17})
+	if len(applied) != 1 || len(failed) != 0 {
+		t.Fatalf("expected 1 applied booking, got applied=%v failed=%v", applied, failed)
+	}
+	if !space.Reserved || space.ReservedById != testOwnerId || !space.AutoRelease {
+		t.Fatalf("expected space to be reserved for the owner with auto release, got %+v", space)
+	}
+	if len(lot.Bookings) != 0 {
+		t.Fatalf("expected applied booking to be removed, got %v", lot.Bookings)
+	}
+
+	// booked space is not free on the day
+	lot.AddDayBookings(space.Key(), testOther, testOtherId, nil, []time.Time{day(16, 0, 0)})
+	applied, failed = lot.ApplyDayBookings(day(16, 0, 0), calendar.TimeOfDay{Hour: 17})
+	if len(applied) != 0 || len(failed) != 1 || len(lot.Bookings) != 0 {
+		t.Fatalf("expected failed booking to be removed, got applied=%v failed=%v", applied, failed)
+	}
+}
+
+func TestDayBookingsOfOwnedSpace(t *testing.T) {
+	lot, space := newTestLot(t)
+	now := day(14, 10, 0)
+	lot.SetClock(clock.NewFake(now))
+	addRelease(t, lot, space, testRelease{start: 16, end: 16}, now)
+
+	if !lot.OwnerUsesOn(space, day(15, 0, 0)) || lot.OwnerUsesOn(space, day(16, 0, 0)) {
+		t.Fatal("expected space to be used by its owner except on the released date")
+	}
+	_, err := lot.AddDayBookings(space.Key(), testOther, testOtherId, nil, []time.Time{day(15, 0, 0), day(16, 0, 0)})
+	if err == nil || len(lot.Bookings) != 0 {
+		t.Fatalf("expected conflict & no bookings, got err=%v bookings=%d", err, len(lot.Bookings))
+	}
+	if _, err := lot.AddDayBookings(space.Key(), testOther, testOtherId, nil, []time.Time{day(16, 0, 0)}); err != nil {
+		t.Fatalf("expected released date to be bookable, got %v", err)
+	}
+
+	// owner is back after the active release ends
+	space.Reserved = false
+	addRelease(t, lot, space, testRelease{start: 13, end: 14, active: true}, now)
+	if !lot.OwnerUsesOn(space, day(19, 0, 0)) {
+		t.Fatal("expected space to be used by its owner after the active release")
+	}
+
+	// reservation of a day booking has no owner
+	other := NewSpace(2, 1, "")
+	other.Reserved = true
+	other.AutoRelease = true
+	lot.UnitSpaces[other.Key()] = other
+	if lot.OwnerUsesOn(other, day(15, 0, 0)) {
+		t.Fatal("expected auto release reservation to have no owner")
+	}
+}
+
+func TestReserveForTeam(t *testing.T) {
+	lot, space := newTestLot(t)
+	lot.SetClock(clock.NewFake(day(14, 10, 0)))
+
+	var keys []SpaceKey
+	for number := 2; number <= 5; number++ {
+		s := NewSpace(number, 1, "")
+		lot.UnitSpaces[s.Key()] = s
+		keys = append(keys, s.Key())
+	}
+	keys = append(keys, space.Key())
+	if err := lot.SetNeighbourhood("window", keys); err != nil {
+		t.Fatal(err)
+	}
+	// space 3 is taken, so 4 & 5 are the only adjacent free spaces
+	lot.UnitSpaces[keys[1]].Reserved = true
+	lot.UnitSpaces[keys[1]].ReservedById = "U_THIRD"
+
+	noGroups := func(string) []string { return nil }
+	owner := TeamMember{Name: testOwner, Id: testOwnerId}
+	other := TeamMember{Name: testOther, Id: testOtherId}
+	third := TeamMember{Name: "third", Id: "U_THIRD"}
+	fourth := TeamMember{Name: "fourth", Id: "U_FOURTH"}
+
+	// nobody is booked if any member already has a space
+	if _, err := lot.ReserveForTeam("window", []TeamMember{other, owner}, noGroups); err == nil {
+		t.Fatal("expected error when a member already has a space")
+	}
+	if lot.HasSpace(testOtherId) {
+		t.Fatal("expected no reservation after a failed team booking")
+	}
+
+	space.Reserved = false
+	lot.UnitSpaces[keys[1]].Reserved = false
+	lot.UnitSpaces[keys[2]].Reserved = true
+	lot.UnitSpaces[keys[2]].ReservedById = "U_FIFTH"
+	// 1, 2, 3 are free & adjacent, 4 is taken
+	if _, err := lot.ReserveForTeam("window", []TeamMember{owner, other, third, fourth}, noGroups); err == nil {
+		t.Fatal("expected error when there are not enough adjacent free spaces")
+	}
+
+	team, err := lot.ReserveForTeam("window", []TeamMember{owner, other, third}, noGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, number := range []int{1, 2, 3} {
+		if team[i].Number != number || team[i].ReservedById != []TeamMember{owner, other, third}[i].Id {
+			t.Fatalf("expected space %d for member %d, got %+v", number, i, team[i])
+		}
+	}
+
+	lot.RemoveFromNeighbourhoods(keys[0])
+	if len(lot.Neighbourhoods["window"].Spaces) != 4 {
+		t.Fatalf("expected removed space to be dropped, got %v", lot.Neighbourhoods["window"].Spaces)
+	}
+}
+
+func TestCheckIns(t *testing.T) {
+	lot, space := newTestLot(t)
+	lot.SetClock(clock.NewFake(day(14, 8, 0)))
+	// permanent space of the owner doesn't need a check-in
+	space.AutoRelease = false
+
+	checkedIn := NewSpace(2, 1, "")
+	noShow := NewSpace(3, 1, "")
+	reserved := NewSpace(4, 1, "")
+	for _, s := range []*Space{checkedIn, noShow, reserved} {
+		lot.UnitSpaces[s.Key()] = s
+	}
+	lot.Reserve(checkedIn.Key(), testOther, testOtherId, nil, true)
+	lot.Reserve(noShow.Key(), "late", "U_LATE", nil, true)
+	lot.Reserve(reserved.Key(), "leaving", "U_LEAVING", nil, true)
+
+	pending := lot.RequireCheckIns()
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 check-ins, got %d", len(pending))
+	}
+
+	if err := lot.CheckIn(testOwnerId, checkedIn.Key()); err == nil {
+		t.Fatal("expected error when checking in to the space of someone else")
+	}
+	if err := lot.CheckIn(testOtherId, checkedIn.Key()); err != nil {
+		t.Fatal(err)
+	}
+
+	// space was released & reserved by someone else after the check-in was
+	// requested -> the new reservation is not a no-show
+	lot.SetClock(clock.NewFake(day(14, 9, 0)))
+	lot.Release(reserved.Key(), "leaving", "U_LEAVING")
+	lot.Reserve(reserved.Key(), "late", "U_LATE", nil, true)
+
+	noShows := lot.ReleaseNoShows()
+	if len(noShows) != 1 || noShows[0].SpaceKey != noShow.Key() {
+		t.Fatalf("expected only %s to be released, got %v", noShow.Key(), noShows)
+	}
+	if noShow.Reserved || !checkedIn.Reserved || !reserved.Reserved || !space.Reserved {
+		t.Fatal("expected only the no-show reservation to be released")
+	}
+	if len(lot.CheckIns) != 0 {
+		t.Fatalf("expected check-in requests to be dropped, got %v", lot.CheckIns)
+	}
+}
+
+func TestCheckInsOfReleasedSpaces(t *testing.T) {
+	lot, space := newTestLot(t)
+	now := day(14, 8, 0)
+	lot.SetClock(clock.NewFake(now))
+
+	// released space reserved by someone else needs a check-in
+	addRelease(t, lot, space, testRelease{start: 14, end: 14, active: true}, now)
+	space.Reserved = false
+	lot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+
+	// space handed over in a swap of permanent spaces doesn't
+	swapped := NewSpace(2, 1, "")
+	lot.UnitSpaces[swapped.Key()] = swapped
+	release := lot.ToBeReleased.Add("viewId", "third", "U_THIRD", swapped, now)
+	start, end := day(14, 0, 0), day(15, 0, 0)
+	release.StartDate, release.EndDate = &start, &end
+	release.ReservedForId, release.ReservedForName = "U_FOURTH", "fourth"
+	release.MarkSubmitted("third", now)
+	release.MarkActive(now)
+	if err := lot.ToBeReleased.Update(release); err != nil {
+		t.Fatal(err)
+	}
+	lot.reserveForSwap(swapped, release)
+
+	// team bookings need a check-in
+	teamSpace := NewSpace(3, 1, "")
+	lot.UnitSpaces[teamSpace.Key()] = teamSpace
+	if err := lot.SetNeighbourhood("window", []SpaceKey{teamSpace.Key()}); err != nil {
+		t.Fatal(err)
+	}
+	noGroups := func(string) []string { return nil }
+	member := TeamMember{Name: "fifth", Id: "U_FIFTH"}
+	if _, err := lot.ReserveForTeam("window", []TeamMember{member}, noGroups); err != nil {
+		t.Fatal(err)
+	}
+
+	pending := lot.RequireCheckIns()
+	var spaceKeys []SpaceKey
+	for _, checkIn := range pending {
+		spaceKeys = append(spaceKeys, checkIn.SpaceKey)
+	}
+	slices.Sort(spaceKeys)
+	want := []SpaceKey{space.Key(), teamSpace.Key()}
+	if !slices.Equal(spaceKeys, want) {
+		t.Fatalf("expected check-ins of %v, got %v", want, spaceKeys)
+	}
+}
+
+func TestSlotCheckIns(t *testing.T) {
+	lot, space := newTestLot(t)
+	space.Reserved = false
+	morning := calendar.TimeSlot{Start: calendar.TimeOfDay{Hour: 8}, End: calendar.TimeOfDay{Hour: 12}}
+	afternoon := calendar.TimeSlot{Start: calendar.TimeOfDay{Hour: 12}, End: calendar.TimeOfDay{Hour: 17}}
+	lot.SetTimeSlots([]calendar.TimeSlot{morning, afternoon})
+	lot.SetClock(clock.NewFake(day(14, 8, 0)))
+
+	lot.ReserveSlots(space.Key(), testOwner, testOwnerId, nil, []calendar.TimeSlot{morning})
+	lot.ReserveSlots(space.Key(), testOther, testOtherId, nil, []calendar.TimeSlot{afternoon})
+
+	pending := lot.RequireCheckIns()
+	if len(pending) != 2 || len(pending[0].Slots) != 1 || len(pending[1].Slots) != 1 {
+		t.Fatalf("expected a slot check-in per user, got %+v", pending)
+	}
+	if checkIns := lot.UserCheckIns(testOwnerId); len(checkIns) != 1 {
+		t.Fatalf("expected 1 check-in of %s, got %+v", testOwnerId, checkIns)
+	}
+	if err := lot.CheckIn(testOwnerId, space.Key()); err != nil {
+		t.Fatal(err)
+	}
+	if checkIns := lot.UserCheckIns(testOwnerId); len(checkIns) != 0 {
+		t.Fatalf("expected no check-ins of %s, got %+v", testOwnerId, checkIns)
+	}
+
+	noShows := lot.ReleaseNoShows()
+	if len(noShows) != 1 || noShows[0].ReservedById != testOtherId {
+		t.Fatalf("expected only slot of %s to be released, got %+v", testOtherId, noShows)
+	}
+	if len(space.Slots) != 1 || space.Slots[0].ReservedById != testOwnerId {
+		t.Fatalf("expected only the checked in slot to be kept, got %+v", space.Slots)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	lot, space := newTestLot(t)
+	cal := calendar.NewCalendar()
+	space.Reserved = false
+
+	// other reserved on Mon, Tue & Wed (Thu 2026-10-15 is the booking date)
+	for d := 12; d <= 14; d++ {
+		lot.SetClock(clock.NewFake(day(d, 9, 0)))
+		lot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+		err := lot.ReleaseSpaces(day(d, 17, 0), calendar.TimeOfDay{Hour: 17}, cal)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(lot.History) != 3 {
+		t.Fatalf("expected 3 used reservations, got %v", lot.History)
+	}
+
+	date := day(15, 0, 0)
+	if usage := lot.QuotaUsage(testOtherId, date); usage.Week != 3 || usage.Month != 3 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+
+	tests := []struct {
+		name    string
+		quota   user.Quota
+		wantErr bool
+	}{
+		{"unlimited", user.Quota{}, false},
+		{"week reached", user.Quota{PerWeek: 3}, true},
+		{"week left", user.Quota{PerWeek: 4}, false},
+		{"month reached", user.Quota{PerMonth: 3}, true},
+		{"consecutive reached", user.Quota{ConsecutiveDays: 3}, true},
+		{"consecutive left", user.Quota{ConsecutiveDays: 4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lot.CheckQuota(testOtherId, tt.quota, date, cal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckQuota() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// weekend doesn't break consecutive working days: Mon 2026-10-19 follows
+	// all working days of the previous week
+	for d := 15; d <= 16; d++ {
+		lot.SetClock(clock.NewFake(day(d, 9, 0)))
+		lot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+		lot.ReleaseSpaces(day(d, 17, 0), calendar.TimeOfDay{Hour: 17}, cal)
+	}
+	if lot.CheckQuota(testOtherId, user.Quota{ConsecutiveDays: 5}, day(19, 0, 0), cal) == nil {
+		t.Fatal("expected consecutive limit to be reached over the weekend")
+	}
+
+	// moving to another space is not a new reservation
+	lot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+	if err := lot.CheckQuota(testOtherId, user.Quota{PerWeek: 1}, day(19, 0, 0), cal); err != nil {
+		t.Fatalf("expected current reservation to be allowed to move, got %v", err)
+	}
+
+	now := day(14, 10, 0)
+	if err := CheckHorizon(user.Quota{HorizonDays: 7}, now, day(21, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if CheckHorizon(user.Quota{HorizonDays: 7}, now, day(22, 0, 0)) == nil {
+		t.Fatal("expected date after the horizon to be rejected")
+	}
+}
+
+func TestFloorMap(t *testing.T) {
+	lot, space := newTestLot(t)
+	space.MapPosition = &MapPosition{X: 0, Y: 0}
+
+	free := NewSpace(2, 1, "")
+	free.MapPosition = &MapPosition{X: 1, Y: 0}
+	unplaced := NewSpace(3, 1, "")
+	otherFloor := NewSpace(4, 2, "")
+	otherFloor.MapPosition = &MapPosition{X: 0, Y: 0}
+	for _, s := range []*Space{free, unplaced, otherFloor} {
+		lot.UnitSpaces[s.Key()] = s
+	}
+
+	floor := MakeFloorStr(1)
+	if !lot.HasFloorMap(floor) || lot.HasFloorMap(MakeFloorStr(-1)) {
+		t.Fatal("expected only floors with placed spaces to have a map")
+	}
+
+	ownerMap := lot.FloorMap(testOwnerId, floor)
+	expected := MapSpaces{
+		{Number: 1, Position: MapPosition{0, 0}, State: MapSpaceMine},
+		{Number: 2, Position: MapPosition{1, 0}, State: MapSpaceFree},
+	}
+	if !slices.Equal(ownerMap, expected) {
+		t.Fatalf("expected %v, got %v", expected, ownerMap)
+	}
+
+	otherMap := lot.FloorMap(testOtherId, floor)
+	if otherMap[0].State != MapSpaceTaken {
+		t.Fatalf("expected space of the owner to be taken for others, got %v", otherMap[0].State)
+	}
+	if ownerMap.Version() == otherMap.Version() {
+		t.Fatal("expected maps with different states to have different versions")
+	}
+
+	version := otherMap.Version()
+	lot.Reserve(free.Key(), testOther, testOtherId, nil, true)
+	if lot.FloorMap(testOtherId, floor).Version() == version {
+		t.Fatal("expected version to change after reservation")
+	}
+}
@@ -0,0 +1,178 @@
+package spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Neighbourhood Group of adjacent workspaces where a team can sit together
+// (only used for workspaces). Spaces are on the same floor & ordered by
+// number, neighbouring spaces in the list are next to each other.
+type Neighbourhood struct {
+	Name   string
+	Floor  int
+	Spaces []SpaceKey
+}
+
+// Neighbourhoods name -> neighbourhood
+type Neighbourhoods map[string]*Neighbourhood
+
+// TeamMember User for whom a space is reserved in a team booking
+type TeamMember struct {
+	Name string
+	Id   string
+}
+
+// SetNeighbourhood Adds or replaces the neighbourhood. All spaces have to
+// exist & be on the same floor.
+func (d *SpacesLot) SetNeighbourhood(name string, spaceKeys []SpaceKey) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("neighbourhood name is not set")
+	}
+	if len(spaceKeys) == 0 {
+		return fmt.Errorf("neighbourhood %s has no spaces", name)
+	}
+
+	var neighbourhoodSpaces SpacesInfo
+	for _, spaceKey := range spaceKeys {
+		space, found := d.UnitSpaces[spaceKey]
+		if !found {
+			return fmt.Errorf("couldn't find the space %s", spaceKey)
+		}
+		if space.Floor != d.UnitSpaces[spaceKeys[0]].Floor {
+			return fmt.Errorf("spaces of neighbourhood %s are not on the same floor", name)
+		}
+		neighbourhoodSpaces = append(neighbourhoodSpaces, space)
+	}
+	slices.SortFunc(neighbourhoodSpaces, func(a, b *Space) int {
+		return a.Number - b.Number
+	})
+
+	neighbourhood := &Neighbourhood{
+		Name:  name,
+		Floor: neighbourhoodSpaces[0].Floor,
+	}
+	for _, space := range neighbourhoodSpaces {
+		neighbourhood.Spaces = append(neighbourhood.Spaces, space.Key())
+	}
+
+	if d.Neighbourhoods == nil {
+		d.Neighbourhoods = Neighbourhoods{}
+	}
+	d.Neighbourhoods[name] = neighbourhood
+	return nil
+}
+
+func (d *SpacesLot) RemoveNeighbourhood(name string) {
+	delete(d.Neighbourhoods, name)
+}
+
+// NeighbourhoodNames Returns names of neighbourhoods on the given floors
+// sorted by name. All neighbourhoods are returned if floors is nil.
+func (d *SpacesLot) NeighbourhoodNames(floors []int) []string {
+	var names []string
+	for name, neighbourhood := range d.Neighbourhoods {
+		if floors == nil || slices.Contains(floors, neighbourhood.Floor) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ReserveForTeam Reserves adjacent free spaces of the neighbourhood for all
+// members (the first member gets the first space etc.). Nothing is reserved
+// if any member already has a space or there are not enough adjacent free
+// spaces which the members can reserve.
+func (d *SpacesLot) ReserveForTeam(
+	name string,
+	members []TeamMember,
+	userGroups func(userId string) []string,
+) (SpacesInfo, error) {
+	neighbourhood, found := d.Neighbourhoods[name]
+	if !found {
+		return nil, fmt.Errorf("couldn't find neighbourhood %s", name)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no team members selected")
+	}
+	if len(members) > len(neighbourhood.Spaces) {
+		return nil, fmt.Errorf(
+			"neighbourhood %s only has %d spaces for %d team members",
+			name,
+			len(neighbourhood.Spaces),
+			len(members),
+		)
+	}
+
+	var booked []string
+	for _, member := range members {
+		if d.HasSpace(member.Id) || d.HasAnySlot(member.Id) {
+			booked = append(booked, member.Name)
+		}
+	}
+	if len(booked) > 0 {
+		return nil, fmt.Errorf("already have a workspace: %s", strings.Join(booked, ", "))
+	}
+
+	for start := 0; start+len(members) <= len(neighbourhood.Spaces); start++ {
+		team, ok := d.teamSpaces(neighbourhood.Spaces[start:start+len(members)], members, userGroups)
+		if !ok {
+			continue
+		}
+
+		now := d.clock.Now()
+		for i, space := range team {
+			member := members[i]
+			slog.Info("TEAM_RESERVE", "user", member.Name, "space", space.Key(), "neighbourhood", name)
+			space.Reserved = true
+			space.AutoRelease = true
+			space.ReservedBy = member.Name
+			space.ReservedById = member.Id
+			space.ReservedTime = now
+		}
+		d.SynchronizeToFile()
+		return team, nil
+	}
+
+	return nil, fmt.Errorf(
+		"there are no %d adjacent free spaces in neighbourhood %s",
+		len(members),
+		name,
+	)
+}
+
+// teamSpaces Returns the spaces if all of them are free & can be reserved by
+// the member with the same index
+func (d *SpacesLot) teamSpaces(
+	spaceKeys []SpaceKey,
+	members []TeamMember,
+	userGroups func(userId string) []string,
+) (SpacesInfo, bool) {
+	var team SpacesInfo
+	for i, spaceKey := range spaceKeys {
+		space, found := d.UnitSpaces[spaceKey]
+		if !found || space.Reserved || len(space.Slots) > 0 ||
+			!d.CanReserve(space, userGroups(members[i].Id)) {
+			return nil, false
+		}
+		team = append(team, space)
+	}
+	return team, true
+}
+
+// RemoveFromNeighbourhoods Removes a deleted space from neighbourhoods.
+// Neighbourhoods without spaces are removed.
+func (d *SpacesLot) RemoveFromNeighbourhoods(spaceKey SpaceKey) {
+	for name, neighbourhood := range d.Neighbourhoods {
+		neighbourhood.Spaces = slices.DeleteFunc(neighbourhood.Spaces, func(key SpaceKey) bool {
+			return key == spaceKey
+		})
+		if len(neighbourhood.Spaces) == 0 {
+			delete(d.Neighbourhoods, name)
+		}
+	}
+}
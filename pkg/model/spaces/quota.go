@@ -0,0 +1,159 @@
+package spaces
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+)
+
+// historyDays How long used reservations are kept (enough for monthly quotas
+// & consecutive days)
+const historyDays = 62
+
+// UsedReservation Day on which the user had a space reserved which was
+// released automatically. Used to enforce quotas.
+type UsedReservation struct {
+	UserId   string
+	SpaceKey SpaceKey
+	Date     time.Time
+}
+
+type UsedReservations []UsedReservation
+
+// QuotaUsage Number of reservations of the user (including the current one)
+type QuotaUsage struct {
+	Week  int
+	Month int
+}
+
+// recordUsed Remembers the reservation of the space which is being reset
+// on the date. Guest reservations are not counted.
+func (d *SpacesLot) recordUsed(space *Space, date time.Time) {
+	if space.Guest != nil {
+		return
+	}
+	d.History = append(d.History, UsedReservation{
+		UserId:   space.ReservedById,
+		SpaceKey: space.Key(),
+		Date:     common.DateOf(date),
+	})
+}
+
+// pruneHistory Removes used reservations older than historyDays
+func (d *SpacesLot) pruneHistory(now time.Time) {
+	oldest := common.DateOf(now).AddDate(0, 0, -historyDays)
+	d.History = slices.DeleteFunc(d.History, func(used UsedReservation) bool {
+		return used.Date.Before(oldest)
+	})
+}
+
+// usedOn Checks if the user had a reservation on the date
+func (d *SpacesLot) usedOn(userId string, date time.Time) bool {
+	return slices.ContainsFunc(d.History, func(used UsedReservation) bool {
+		return used.UserId == userId && common.EqualDate(used.Date, date)
+	})
+}
+
+// hasCurrentReservation Checks if the user holds a reservation which counts
+// towards the quota (permanent spaces & guest reservations don't)
+func (d *SpacesLot) hasCurrentReservation(userId string) bool {
+	for _, space := range d.UnitSpaces {
+		if space.Reserved && space.AutoRelease && space.Guest == nil && space.ReservedById == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// QuotaUsage Returns number of reservations of the user in the week & month
+// of the booking date including the current reservation
+func (d *SpacesLot) QuotaUsage(userId string, date time.Time) QuotaUsage {
+	year, week := date.ISOWeek()
+
+	var usage QuotaUsage
+	for _, used := range d.History {
+		if used.UserId != userId || common.EqualDate(used.Date, date) {
+			continue
+		}
+		if usedYear, usedWeek := used.Date.ISOWeek(); usedYear == year && usedWeek == week {
+			usage.Week++
+		}
+		if used.Date.Year() == date.Year() && used.Date.Month() == date.Month() {
+			usage.Month++
+		}
+	}
+
+	if d.hasCurrentReservation(userId) {
+		usage.Week++
+		usage.Month++
+	}
+	return usage
+}
+
+// CheckQuota Returns an error if a new reservation of the user for the
+// booking date would exceed the quota. Moving to another space is not a new
+// reservation.
+func (d *SpacesLot) CheckQuota(
+	userId string,
+	quota user.Quota,
+	date time.Time,
+	cal *calendar.Calendar,
+) error {
+	if d.hasCurrentReservation(userId) {
+		return nil
+	}
+
+	usage := d.QuotaUsage(userId, date)
+	if quota.PerWeek > 0 && usage.Week >= quota.PerWeek {
+		return fmt.Errorf("you reached your limit of %d reservations per week", quota.PerWeek)
+	}
+	if quota.PerMonth > 0 && usage.Month >= quota.PerMonth {
+		return fmt.Errorf("you reached your limit of %d reservations per month", quota.PerMonth)
+	}
+
+	if quota.ConsecutiveDays > 0 {
+		consecutive := 0
+		day := common.DateOf(date).AddDate(0, 0, -1)
+		for consecutive < quota.ConsecutiveDays && !day.Before(date.AddDate(0, 0, -historyDays)) {
+			if !cal.IsWorkingDay(day) {
+				day = day.AddDate(0, 0, -1)
+				continue
+			}
+			if !d.usedOn(userId, day) {
+				break
+			}
+			consecutive++
+			day = day.AddDate(0, 0, -1)
+		}
+		if consecutive >= quota.ConsecutiveDays {
+			return fmt.Errorf(
+				"you can't reserve for more than %d consecutive working days",
+				quota.ConsecutiveDays,
+			)
+		}
+	}
+	return nil
+}
+
+// CheckHorizon Returns an error if the date is further ahead than the quota
+// allows
+func CheckHorizon(quota user.Quota, now, date time.Time) error {
+	if quota.HorizonDays == 0 {
+		return nil
+	}
+
+	last := common.DateOf(now).AddDate(0, 0, quota.HorizonDays)
+	if common.DateOf(date).After(last) {
+		return fmt.Errorf(
+			"%s is more than %d days ahead (last bookable day is %s)",
+			date.Format("Mon 2006-01-02"),
+			quota.HorizonDays,
+			last.Format("Mon 2006-01-02"),
+		)
+	}
+	return nil
+}
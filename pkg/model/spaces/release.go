@@ -29,6 +29,15 @@ type ReleaseInfo struct {
 	ActiveTime    *time.Time
 	CreatedTime   *time.Time
 
+	// ReservedForId & ReservedForName User to whom the space is handed over
+	// while the release is active (i.e. swap of permanent spaces). If empty
+	// the released space is free for everyone.
+	ReservedForId   string `json:",omitempty"`
+	ReservedForName string `json:",omitempty"`
+	// PairedSpaceKey Space which was released in exchange for this one (swap
+	// of permanent spaces)
+	PairedSpaceKey SpaceKey `json:",omitempty"`
+
 	// These are only used while the user is choosing date range to refer
 	// between space selected and release range selected (i.e. between booking modal
 	// and corresponding release modal)
@@ -40,8 +49,8 @@ func NewReleaseInfo(
 	uniqueId int,
 	rootViewId, releaserId, ownerId, ownerName string,
 	spaceKey SpaceKey,
+	now time.Time,
 ) ReleaseInfo {
-	now := time.Now()
 	return ReleaseInfo{
 		InUse:         true,
 		ReleaserId:    releaserId,
@@ -83,9 +92,8 @@ func NewEmptyRelease() ReleaseInfo {
 	}
 }
 
-func (i *ReleaseInfo) MarkSubmitted(releaser string) {
+func (i *ReleaseInfo) MarkSubmitted(releaser string, now time.Time) {
 	slog.Info("ReleaseInfo Submitted", "releaser", releaser, "info", i)
-	now := time.Now()
 	i.Submitted = true
 	i.SubmittedTime = &now
 
@@ -97,9 +105,8 @@ func (i *ReleaseInfo) MarkSubmitted(releaser string) {
 	i.ViewId = ""
 }
 
-func (i *ReleaseInfo) MarkActive() {
+func (i *ReleaseInfo) MarkActive(now time.Time) {
 	slog.Info("ReleaseInfo Active", "info", i)
-	now := time.Now()
 	i.Active = true
 	i.ActiveTime = &now
 }
@@ -117,7 +124,14 @@ func (i *ReleaseInfo) DataPresent() bool {
 		i.EndDate != nil)
 }
 
-func (i *ReleaseInfo) Check() string {
+// IsEditable Dates of releases of swapped spaces are tied to the other
+// release of the swap (or to the colleague the space is handed over to) so
+// they can't be changed
+func (i ReleaseInfo) IsEditable() bool {
+	return i.ReservedForId == "" && i.PairedSpaceKey == ""
+}
+
+func (i *ReleaseInfo) Check(now time.Time) string {
 	if !i.DataPresent() {
 		return fmt.Sprintf(
 			"Missing date information for temporary release of space (%s)",
@@ -125,7 +139,7 @@ func (i *ReleaseInfo) Check() string {
 		)
 	}
 
-	return common.CheckDateRange(*i.StartDate, *i.EndDate)
+	return common.CheckDateRange(*i.StartDate, *i.EndDate, now)
 }
 
 func (i ReleaseInfo) String() string {
@@ -199,6 +213,22 @@ func (q ReleaseMap) HasActiveRelease(spaceKey SpaceKey) bool {
 	return !errors.Is(err, my_err.ErrNotFound)
 }
 
+// GetPaired Returns release of the other space of a swap of permanent spaces
+func (q ReleaseMap) GetPaired(release ReleaseInfo) (ReleaseInfo, error) {
+	if release.PairedSpaceKey == "" {
+		return EmptyRelease, my_err.ErrNotFound
+	}
+
+	for _, r := range q.GetAll(release.PairedSpaceKey) {
+		if r.PairedSpaceKey == release.SpaceKey &&
+			r.OwnerId == release.ReservedForId &&
+			r.ReservedForId == release.OwnerId {
+			return r, nil
+		}
+	}
+	return EmptyRelease, my_err.ErrNotFound
+}
+
 func (q ReleaseMap) GetByRootViewId(rootId string) (ReleaseInfo, error) {
 	for _, pool := range q {
 		release, err := pool.ByRootViewId(rootId)
@@ -363,6 +393,7 @@ func (q ReleaseMap) Add(
 	releaserName,
 	releaserId string,
 	space *Space,
+	now time.Time,
 ) ReleaseInfo {
 	spaceKey := space.Key()
 
@@ -395,6 +426,7 @@ func (q ReleaseMap) Add(
 		ownerId,
 		ownerName,
 		space.Key(),
+		now,
 	)
 	slog.Info("Adding to release map",
 		"releaser",
@@ -3,6 +3,7 @@ package spaces
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/AngelVI13/slack-bot/pkg/model/my_err"
 )
@@ -60,13 +61,14 @@ func (p *ReleasePool) Add(
 	ownerId,
 	ownerName string,
 	spaceKey SpaceKey,
+	now time.Time,
 ) ReleaseInfo {
 	idx := p.freeIdx()
 	if idx == -1 {
 		p.grow(2 * p.Capacity)
 		idx = p.freeIdx()
 	}
-	releaseInfo := NewReleaseInfo(idx, viewId, releaserId, ownerId, ownerName, spaceKey)
+	releaseInfo := NewReleaseInfo(idx, viewId, releaserId, ownerId, ownerName, spaceKey, now)
 
 	p.Data[idx] = releaseInfo
 	return releaseInfo
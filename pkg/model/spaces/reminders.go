@@ -0,0 +1,61 @@
+package spaces
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+)
+
+// BotReleaserId Releaser of releases that are created automatically by HCM &
+// BSS syncs
+const BotReleaserId = "ParkingBotId"
+
+// ReleaseReminder Release that starts or ends on the next working day
+type ReleaseReminder struct {
+	Release ReleaseInfo
+	// Starting true if release becomes active at the next reset, false if
+	// the next working day is the last day of the release
+	Starting bool
+	// Date Next working day
+	Date time.Time
+}
+
+// ReleaseReminders Returns releases that become active at the next reset or
+// end after the next working day. Releases created by HCM & BSS syncs are
+// skipped as the owner did not create them.
+func (d *SpacesLot) ReleaseReminders(now time.Time, cal *calendar.Calendar) []ReleaseReminder {
+	nextDate := cal.NextWorkingDay(now)
+	afterNextDate := cal.NextWorkingDay(nextDate)
+
+	var reminders []ReleaseReminder
+	for spaceKey := range d.ToBeReleased {
+		for _, release := range d.ToBeReleased.GetAll(spaceKey) {
+			if !release.Submitted || !release.DataPresent() || release.Cancelled ||
+				release.ReleaserId == BotReleaserId {
+				continue
+			}
+
+			reminder := ReleaseReminder{Release: release, Date: nextDate}
+			endDate := common.DateOf(*release.EndDate)
+			if !release.Active && !release.StartDate.After(nextDate) {
+				reminder.Starting = true
+			} else if !release.Active ||
+				endDate.Before(nextDate) ||
+				!endDate.Before(afterNextDate) {
+				continue
+			}
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	slices.SortFunc(reminders, func(a, b ReleaseReminder) int {
+		if c := strings.Compare(a.Release.OwnerId, b.Release.OwnerId); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Release.SpaceKey), string(b.Release.SpaceKey))
+	})
+	return reminders
+}
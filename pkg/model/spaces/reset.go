@@ -0,0 +1,116 @@
+package spaces
+
+import (
+	"slices"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+)
+
+// ResetTimes Daily reset times of a lot. Floors without a dedicated reset time
+// are reset at the Default time.
+type ResetTimes struct {
+	Default calendar.TimeOfDay
+	// DefaultChanged Default was changed by an admin at runtime, therefore
+	// the default reset time of the config is not applied
+	DefaultChanged bool `json:",omitempty"`
+	// Floors floor (i.e. -1st floor) -> reset time
+	Floors map[string]calendar.TimeOfDay
+}
+
+func NewResetTimes(defaultTime calendar.TimeOfDay) *ResetTimes {
+	return &ResetTimes{
+		Default: defaultTime,
+		Floors:  map[string]calendar.TimeOfDay{},
+	}
+}
+
+// SetDefaultResetTime Sets default reset time of the lot from config unless it
+// was changed by an admin at runtime (see ChangeDefaultResetTime).
+func (d *SpacesLot) SetDefaultResetTime(defaultTime calendar.TimeOfDay) {
+	if d.ResetTimes == nil {
+		d.ResetTimes = NewResetTimes(defaultTime)
+	}
+	if !d.ResetTimes.DefaultChanged {
+		d.ResetTimes.Default = defaultTime
+	}
+	if d.ResetTimes.Floors == nil {
+		d.ResetTimes.Floors = map[string]calendar.TimeOfDay{}
+	}
+}
+
+// ChangeDefaultResetTime Changes default reset time at runtime (admins). It
+// takes precedence over the one from config (configTime) unless they are the
+// same.
+func (d *SpacesLot) ChangeDefaultResetTime(defaultTime, configTime calendar.TimeOfDay) {
+	d.ResetTimes.Default = defaultTime
+	d.ResetTimes.DefaultChanged = defaultTime != configTime
+}
+
+// ResetTime Returns reset time of the given floor (i.e. -1st floor)
+func (d *SpacesLot) ResetTime(floor string) calendar.TimeOfDay {
+	if resetTime, found := d.ResetTimes.Floors[floor]; found {
+		return resetTime
+	}
+	return d.ResetTimes.Default
+}
+
+// SpaceResetTime Returns reset time of the floor where the space is located
+func (d *SpacesLot) SpaceResetTime(spaceKey SpaceKey) calendar.TimeOfDay {
+	space, found := d.UnitSpaces[spaceKey]
+	if !found || space == nil {
+		return d.ResetTimes.Default
+	}
+	return d.ResetTime(MakeFloorStr(space.Floor))
+}
+
+// SetFloorResetTime Sets reset time of a floor. If it is the same as the
+// default one then the floor specific reset time is removed.
+func (d *SpacesLot) SetFloorResetTime(floor string, resetTime calendar.TimeOfDay) {
+	if resetTime == d.ResetTimes.Default {
+		delete(d.ResetTimes.Floors, floor)
+		return
+	}
+	d.ResetTimes.Floors[floor] = resetTime
+}
+
+// AllResetTimes Returns all distinct reset times of floors that have spaces
+// sorted from earliest to latest.
+func (d *SpacesLot) AllResetTimes() []calendar.TimeOfDay {
+	return d.allResetTimes(d.ResetTimes.Default)
+}
+
+// AllResetTimesFor Returns all reset times (see AllResetTimes) as they are
+// once the default reset time of the config is applied (i.e. on config reload)
+func (d *SpacesLot) AllResetTimesFor(configTime calendar.TimeOfDay) []calendar.TimeOfDay {
+	if d.ResetTimes.DefaultChanged {
+		return d.AllResetTimes()
+	}
+	return d.allResetTimes(configTime)
+}
+
+func (d *SpacesLot) allResetTimes(defaultTime calendar.TimeOfDay) []calendar.TimeOfDay {
+	var resetTimes []calendar.TimeOfDay
+	for _, floor := range d.GetAllFloors() {
+		resetTime, found := d.ResetTimes.Floors[floor]
+		if !found {
+			resetTime = defaultTime
+		}
+		if !slices.Contains(resetTimes, resetTime) {
+			resetTimes = append(resetTimes, resetTime)
+		}
+	}
+
+	if len(resetTimes) == 0 {
+		resetTimes = append(resetTimes, defaultTime)
+	}
+
+	slices.SortFunc(resetTimes, func(a, b calendar.TimeOfDay) int {
+		if a.Before(b) {
+			return -1
+		} else if b.Before(a) {
+			return 1
+		}
+		return 0
+	})
+	return resetTimes
+}
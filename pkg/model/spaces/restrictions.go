@@ -0,0 +1,78 @@
+package spaces
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+)
+
+// IsRestricted Checks if only some user groups can reserve the space
+func (p *Space) IsRestricted() bool {
+	return len(p.Groups) > 0
+}
+
+// IsEligible Checks if a member of userGroups can reserve the space
+func (p *Space) IsEligible(userGroups []string) bool {
+	if !p.IsRestricted() {
+		return true
+	}
+
+	for _, group := range userGroups {
+		if slices.Contains(p.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRestrictedOpenTime Sets time of day after which restricted spaces that
+// are still free can be reserved by everyone (until the reset of their
+// floor). nil disables the fallback.
+func (d *SpacesLot) SetRestrictedOpenTime(openTime *calendar.TimeOfDay) {
+	d.restrictedOpenTime = openTime
+}
+
+// RestrictedOpenTime Returns time of day after which restricted spaces are
+// open to everyone or nil if they are never opened
+func (d *SpacesLot) RestrictedOpenTime() *calendar.TimeOfDay {
+	return d.restrictedOpenTime
+}
+
+// RestrictionLifted Checks if the space is currently open to everyone
+// because it is past the restricted open time & before the floor reset.
+func (d *SpacesLot) RestrictionLifted(space *Space) bool {
+	if d.restrictedOpenTime == nil {
+		return false
+	}
+
+	now := calendar.TimeOfDayOf(d.clock.Now())
+	resetTime := d.ResetTime(MakeFloorStr(space.Floor))
+	return !now.Before(*d.restrictedOpenTime) && now.Before(resetTime)
+}
+
+// CanReserve Checks if a member of userGroups is allowed to reserve the
+// space (regardless if it is free or not)
+func (d *SpacesLot) CanReserve(space *Space, userGroups []string) bool {
+	return space.IsEligible(userGroups) || d.RestrictionLifted(space)
+}
+
+// GetRestrictionText Describes restriction of the space or returns empty
+// string if the space is not restricted
+func (d *SpacesLot) GetRestrictionText(space *Space) string {
+	if !space.IsRestricted() {
+		return ""
+	}
+
+	groups := strings.Join(space.Groups, ", ")
+	if d.RestrictionLifted(space) {
+		return fmt.Sprintf(":unlock: Open to everyone (normally only for: _%s_)", groups)
+	}
+
+	txt := fmt.Sprintf(":lock: Only for: _%s_", groups)
+	if d.restrictedOpenTime != nil {
+		txt += fmt.Sprintf(" (open to everyone after %s if free)", d.restrictedOpenTime)
+	}
+	return txt
+}
@@ -0,0 +1,228 @@
+package spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+)
+
+// SlotReservation Reservation of a space for a part of the day (only used
+// for workspaces)
+type SlotReservation struct {
+	Slot         calendar.TimeSlot
+	ReservedBy   string
+	ReservedById string
+	ReservedTime time.Time
+}
+
+// SetTimeSlots Sets parts of the day for which spaces can be reserved
+// separately. If empty spaces can only be reserved for the whole day.
+func (d *SpacesLot) SetTimeSlots(slots []calendar.TimeSlot) {
+	d.timeSlots = slots
+}
+
+// TimeSlots Returns parts of the day for which spaces can be reserved
+// separately
+func (d *SpacesLot) TimeSlots() []calendar.TimeSlot {
+	return d.timeSlots
+}
+
+// SlotReservation Returns reservation of the slot or nil if it is free
+func (p *Space) SlotReservation(slot calendar.TimeSlot) *SlotReservation {
+	for i := range p.Slots {
+		if p.Slots[i].Slot == slot {
+			return &p.Slots[i]
+		}
+	}
+	return nil
+}
+
+// HasSlots Checks if the user reserved any slot of the space
+func (p *Space) HasSlots(userId string) bool {
+	return slices.ContainsFunc(p.Slots, func(r SlotReservation) bool {
+		return r.ReservedById == userId
+	})
+}
+
+// IsTaken Checks if the space is reserved for the whole day or all of its
+// slots are reserved
+func (d *SpacesLot) IsTaken(space *Space) bool {
+	if space.Reserved {
+		return true
+	}
+	if len(space.Slots) == 0 {
+		return false
+	}
+	for _, slot := range d.timeSlots {
+		if space.SlotReservation(slot) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isUsedBy Checks if the user reserved the space for the whole day or for
+// any of its slots
+func (d *SpacesLot) isUsedBy(space *Space, userId string) bool {
+	return (space.Reserved && space.ReservedById == userId) || space.HasSlots(userId)
+}
+
+// SlotSpace Returns space where the user reserved the slot or nil
+func (d *SpacesLot) SlotSpace(userId string, slot calendar.TimeSlot) *Space {
+	for _, space := range d.UnitSpaces {
+		reservation := space.SlotReservation(slot)
+		if reservation != nil && reservation.ReservedById == userId {
+			return space
+		}
+	}
+	return nil
+}
+
+// HasAnySlot Checks if the user reserved a slot of any space
+func (d *SpacesLot) HasAnySlot(userId string) bool {
+	for _, space := range d.UnitSpaces {
+		if space.HasSlots(userId) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReserveSlots Reserves slots of the space for the user. Slots the user
+// already reserved on the space are kept.
+func (l *SpacesLot) ReserveSlots(
+	unitSpace SpaceKey,
+	user, userId string,
+	userGroups []string,
+	slots []calendar.TimeSlot,
+) (errMsg string) {
+	space := l.GetSpace(unitSpace)
+	if space == nil {
+		return fmt.Sprintf(
+			"Failed to reserve space: couldn't find the space %s",
+			unitSpace,
+		)
+	}
+
+	if len(slots) == 0 {
+		return fmt.Sprintf("*Error*: Could not reserve *%s*. No time slots selected", unitSpace)
+	}
+
+	if space.Reserved {
+		if space.ReservedById == userId {
+			return fmt.Sprintf("*Error*: You already reserved *%s* for the whole day", unitSpace)
+		}
+		return fmt.Sprintf(
+			"*Error*: Could not reserve *%s*. *%s* has reserved it for the whole day",
+			unitSpace,
+			space.ReservedBy,
+		)
+	}
+	if other := l.GetReservedSpace(userId); other != nil {
+		return fmt.Sprintf(
+			"*Error*: Could not reserve *%s*. You already reserved *%s* for the whole day",
+			unitSpace,
+			other.Key(),
+		)
+	}
+
+	for _, slot := range slots {
+		if !slices.Contains(l.timeSlots, slot) {
+			return fmt.Sprintf("*Error*: Could not reserve *%s*. Unknown time slot %s", unitSpace, slot)
+		}
+
+		reservation := space.SlotReservation(slot)
+		if reservation != nil && reservation.ReservedById != userId {
+			return fmt.Sprintf(
+				"*Error*: Could not reserve *%s* for %s. *%s* has just reserved it (at *%s*)",
+				unitSpace,
+				slot,
+				reservation.ReservedBy,
+				reservation.ReservedTime.Format("Mon 15:04"),
+			)
+		}
+
+		other := l.SlotSpace(userId, slot)
+		if other != nil && other != space {
+			return fmt.Sprintf(
+				"*Error*: Could not reserve *%s*. You already reserved *%s* for %s",
+				unitSpace,
+				other.Key(),
+				slot,
+			)
+		}
+	}
+
+	if !l.CanReserve(space, userGroups) {
+		return fmt.Sprintf(
+			"*Error*: Could not reserve *%s*. It is reserved for: *%s*",
+			unitSpace,
+			strings.Join(space.Groups, ", "),
+		)
+	}
+	slog.Info("SPACE_RESERVE_SLOTS", "user", user, "space", unitSpace, "slots", slots)
+
+	now := l.clock.Now()
+	for _, slot := range slots {
+		if space.SlotReservation(slot) != nil {
+			continue
+		}
+		space.Slots = append(space.Slots, SlotReservation{
+			Slot:         slot,
+			ReservedBy:   user,
+			ReservedById: userId,
+			ReservedTime: now,
+		})
+	}
+	slices.SortFunc(space.Slots, func(a, b SlotReservation) int {
+		if a.Slot.Start.Before(b.Slot.Start) {
+			return -1
+		} else if b.Slot.Start.Before(a.Slot.Start) {
+			return 1
+		}
+		return 0
+	})
+
+	l.SynchronizeToFile()
+	return ""
+}
+
+// ReleaseSlots Releases slots of the space reserved by the user. If all is
+// true (admins) slots of all users are released. Returns users whose slots
+// were released by someone else & the message for them.
+func (l *SpacesLot) ReleaseSlots(
+	unitSpace SpaceKey,
+	userName, userId string,
+	all bool,
+) (victimIds []string, msg string) {
+	space := l.GetSpace(unitSpace)
+	if space == nil {
+		return nil, ""
+	}
+
+	slog.Info("SPACE_RELEASE_SLOTS", "user", userName, "space", unitSpace, "all", all)
+
+	var kept []SlotReservation
+	for _, reservation := range space.Slots {
+		if reservation.ReservedById != userId && !all {
+			kept = append(kept, reservation)
+			continue
+		}
+		if reservation.ReservedById != userId &&
+			!slices.Contains(victimIds, reservation.ReservedById) {
+			victimIds = append(victimIds, reservation.ReservedById)
+		}
+	}
+	space.Slots = kept
+	l.SynchronizeToFile()
+
+	return victimIds, fmt.Sprintf(
+		":warning: *%s* released your reservation of space (*%s*)",
+		userName,
+		unitSpace,
+	)
+}
@@ -12,14 +12,27 @@ type Space struct {
 	Number      int
 	Floor       int
 	Description string
+	Attributes  []Attribute `json:",omitempty"`
+	// Groups User groups that can reserve the space. Everyone can reserve
+	// it if empty.
+	Groups []string `json:",omitempty"`
+	// Guest External guest for whom the space is reserved (if any)
+	Guest *Guest `json:",omitempty"`
+	// Slots Reservations of parts of the day (see SpacesLot.TimeSlots). A
+	// space is either reserved for the whole day or for some slots.
+	Slots []SlotReservation `json:",omitempty"`
+	// MapPosition Cell of the space on the rendered floor map (nil means the
+	// space is not shown on the map)
+	MapPosition *MapPosition `json:",omitempty"`
 	common.ReservedProps
 }
 
-func NewSpace(number, floor int, description string) *Space {
+func NewSpace(number, floor int, description string, attributes ...Attribute) *Space {
 	return &Space{
 		Number:      number,
 		Floor:       floor,
 		Description: description,
+		Attributes:  attributes,
 	}
 }
 
@@ -28,12 +41,22 @@ func (p *Space) GetPropsText() string {
 	if p.Description != "" {
 		description = fmt.Sprintf(" - %s", p.Description)
 	}
-	return fmt.Sprintf("(%d floor%s)", p.Floor, description)
+
+	badges := ""
+	if len(p.Attributes) > 0 {
+		badges = " " + p.GetAttributesBadges()
+	}
+	return fmt.Sprintf("(%d floor%s)%s", p.Floor, description, badges)
 }
 
 func (p *Space) GetStatusEmoji() string {
+	return StatusEmoji(p.Reserved)
+}
+
+// StatusEmoji Emoji of a free (green) or taken (orange) space
+func StatusEmoji(taken bool) string {
 	emoji := ":large_green_circle:"
-	if p.Reserved {
+	if taken {
 		emoji = ":large_orange_circle:"
 	}
 	return emoji
@@ -43,7 +66,9 @@ func (p *Space) GetStatusEmoji() string {
 // Returns empty string if space is free
 func (p *Space) GetStatusDescription() string {
 	status := ""
-	if p.Reserved {
+	if p.Reserved && p.Guest != nil {
+		status = fmt.Sprintf("Guest of <@%s>", p.Guest.HostId)
+	} else if p.Reserved {
 		// timeStr := p.ReservedTime.Format("Mon 15:04")
 		// status = fmt.Sprintf("_:bust_in_silhouette:*<@%s>*\ton\t:clock1: *%s*_", p.ReservedById, timeStr)
 		status = fmt.Sprintf("<@%s>", p.ReservedById)
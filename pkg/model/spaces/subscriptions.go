@@ -0,0 +1,232 @@
+package spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+)
+
+// Subscription Request of a user to be notified when a space matching all of
+// the filters becomes free. Empty filters match any space.
+type Subscription struct {
+	Id       int
+	UserId   string
+	UserName string
+	// Floor Floor string (i.e. 1st floor) or empty for any floor
+	Floor string `json:",omitempty"`
+	// Attributes Attributes the space has to have (all of them)
+	Attributes []Attribute `json:",omitempty"`
+	SpaceKey   SpaceKey    `json:",omitempty"`
+	// LastNotified Time the user was last notified (used for throttling)
+	LastNotified time.Time
+}
+
+func (s *Subscription) String() string {
+	var filters []string
+	if s.SpaceKey != "" {
+		filters = append(filters, fmt.Sprintf("space *%s*", s.SpaceKey))
+	}
+	if s.Floor != "" {
+		filters = append(filters, fmt.Sprintf("*%s*", s.Floor))
+	}
+	for _, attr := range s.Attributes {
+		filters = append(filters, AttributeLabel(attr))
+	}
+	if len(filters) == 0 {
+		return "any space"
+	}
+	return strings.Join(filters, ", ")
+}
+
+// Matches Checks if space matches all filters of the subscription
+func (s *Subscription) Matches(space *Space) bool {
+	if s.Floor != "" && MakeFloorStr(space.Floor) != s.Floor {
+		return false
+	}
+	if !space.HasAttributes(s.Attributes) {
+		return false
+	}
+	return s.SpaceKey == "" || s.SpaceKey == space.Key()
+}
+
+func (s *Subscription) sameFilters(other *Subscription) bool {
+	return s.UserId == other.UserId &&
+		s.Floor == other.Floor &&
+		sameAttributes(s.Attributes, other.Attributes) &&
+		s.SpaceKey == other.SpaceKey
+}
+
+// sameAttributes Checks if both lists contain the same attributes (in any
+// order)
+func sameAttributes(a, b []Attribute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, attr := range a {
+		if !slices.Contains(b, attr) {
+			return false
+		}
+	}
+	return true
+}
+
+type Subscriptions []*Subscription
+
+// FreeSpace Space that became free & the date for which it can be reserved
+type FreeSpace struct {
+	Space *Space
+	Date  time.Time
+}
+
+// FreeSpaceNotification Free spaces matching subscriptions of a user
+type FreeSpaceNotification struct {
+	UserId string
+	Spaces []FreeSpace
+}
+
+// AddSubscription Stores subscription of the user
+func (d *SpacesLot) AddSubscription(subscription Subscription) (*Subscription, error) {
+	if subscription.SpaceKey != "" {
+		if _, found := d.UnitSpaces[subscription.SpaceKey]; !found {
+			return nil, fmt.Errorf("space %s does not exist", subscription.SpaceKey)
+		}
+	}
+
+	subscription.Id = 1
+	for _, other := range d.Subscriptions {
+		if other.sameFilters(&subscription) {
+			return nil, fmt.Errorf("you are already subscribed to %s", other)
+		}
+		subscription.Id = max(subscription.Id, other.Id+1)
+	}
+
+	d.Subscriptions = append(d.Subscriptions, &subscription)
+	d.SynchronizeToFile()
+	return &subscription, nil
+}
+
+// RemoveSubscription Removes subscription of the user
+func (d *SpacesLot) RemoveSubscription(userId string, id int) (*Subscription, error) {
+	idx := slices.IndexFunc(d.Subscriptions, func(s *Subscription) bool {
+		return s.Id == id && s.UserId == userId
+	})
+	if idx == -1 {
+		return nil, fmt.Errorf("subscription (id=%d) no longer exists", id)
+	}
+
+	subscription := d.Subscriptions[idx]
+	d.Subscriptions = slices.Delete(d.Subscriptions, idx, idx+1)
+	d.SynchronizeToFile()
+	return subscription, nil
+}
+
+// UserSubscriptions Returns subscriptions of the user in the order they were
+// added
+func (d *SpacesLot) UserSubscriptions(userId string) Subscriptions {
+	var subscriptions Subscriptions
+	for _, subscription := range d.Subscriptions {
+		if subscription.UserId == userId {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions
+}
+
+// FreeSpace Makes space available for reservation & remembers it so that
+// subscribers can be notified
+func (d *SpacesLot) FreeSpace(space *Space) {
+	space.Reserved = false
+	d.markFreed(space)
+}
+
+func (d *SpacesLot) markFreed(space *Space) {
+	if d.freed == nil {
+		d.freed = map[SpaceKey]bool{}
+	}
+	d.freed[space.Key()] = true
+}
+
+// FreeSpaceNotifications Matches spaces that became free since the last call
+// (& are still free) against subscriptions. Users that already have a space
+// or were notified less than throttle ago are skipped.
+func (d *SpacesLot) FreeSpaceNotifications(
+	now time.Time,
+	throttle time.Duration,
+	cal *calendar.Calendar,
+	userGroups func(userId string) []string,
+) []FreeSpaceNotification {
+	var freed SpacesInfo
+	for spaceKey := range d.freed {
+		space, found := d.UnitSpaces[spaceKey]
+		if found && !space.Reserved {
+			freed = append(freed, space)
+		}
+	}
+	d.freed = nil
+	if len(freed) == 0 || len(d.Subscriptions) == 0 {
+		return nil
+	}
+	slices.SortFunc(freed, func(a, b *Space) int {
+		if a.Smaller(b) {
+			return -1
+		}
+		return 1
+	})
+
+	var userIds []string
+	for _, subscription := range d.Subscriptions {
+		if !slices.Contains(userIds, subscription.UserId) {
+			userIds = append(userIds, subscription.UserId)
+		}
+	}
+	slices.Sort(userIds)
+
+	var notifications []FreeSpaceNotification
+	for _, userId := range userIds {
+		subscriptions := d.UserSubscriptions(userId)
+		if d.HandOverSpace(userId) != nil || notifiedWithin(subscriptions, now, throttle) {
+			continue
+		}
+
+		groups := userGroups(userId)
+		notification := FreeSpaceNotification{UserId: userId}
+		for _, space := range freed {
+			matches := slices.ContainsFunc(subscriptions, func(s *Subscription) bool {
+				return s.Matches(space)
+			})
+			if !matches || !d.CanReserve(space, groups) {
+				continue
+			}
+
+			date := cal.BookingDate(now, d.SpaceResetTime(space.Key()))
+			notification.Spaces = append(notification.Spaces, FreeSpace{Space: space, Date: date})
+		}
+		if len(notification.Spaces) == 0 {
+			continue
+		}
+
+		slog.Info("FreeSpaceNotification", "user", userId, "spaces", len(notification.Spaces))
+		for _, subscription := range subscriptions {
+			subscription.LastNotified = now
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if len(notifications) > 0 {
+		d.SynchronizeToFile()
+	}
+	return notifications
+}
+
+func notifiedWithin(subscriptions Subscriptions, now time.Time, throttle time.Duration) bool {
+	for _, subscription := range subscriptions {
+		if now.Sub(subscription.LastNotified) < throttle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package spaces
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+)
+
+// SwapRequest Request to hand over a space to another user (or to exchange
+// spaces with them) waiting for the answer of the target user
+type SwapRequest struct {
+	Id       int
+	FromId   string
+	FromName string
+	ToId     string
+	ToName   string
+	// StartDate & EndDate Date range for which permanent spaces are
+	// exchanged. nil if only the current reservation is handed over.
+	StartDate *time.Time `json:",omitempty"`
+	EndDate   *time.Time `json:",omitempty"`
+}
+
+// IsPermanent Checks if the request is for permanent spaces (over a date
+// range) instead of the current reservation
+func (r *SwapRequest) IsPermanent() bool {
+	return r.StartDate != nil && r.EndDate != nil
+}
+
+func (r *SwapRequest) String() string {
+	dates := "current reservation"
+	if r.IsPermanent() {
+		dates = fmt.Sprintf(
+			"%s -> %s",
+			r.StartDate.Format("2006-01-02"),
+			r.EndDate.Format("2006-01-02"),
+		)
+	}
+	return fmt.Sprintf("%s -> %s (%s)", r.FromName, r.ToName, dates)
+}
+
+// SwapResult Spaces that changed hands after a swap request was accepted
+type SwapResult struct {
+	// Given Space of the initiator handed over to the target
+	Given *Space
+	// Received Space of the target handed over to the initiator (nil if the
+	// target didn't have a space)
+	Received *Space
+}
+
+// AddSwapRequest Stores swap request until it is answered
+func (d *SpacesLot) AddSwapRequest(request SwapRequest) *SwapRequest {
+	request.Id = 1
+	for _, other := range d.Swaps {
+		request.Id = max(request.Id, other.Id+1)
+	}
+
+	d.Swaps = append(d.Swaps, &request)
+	d.SynchronizeToFile()
+	return &request
+}
+
+// TakeSwapRequest Removes & returns swap request addressed to the user
+func (d *SpacesLot) TakeSwapRequest(toId string, id int) (*SwapRequest, error) {
+	idx := slices.IndexFunc(d.Swaps, func(request *SwapRequest) bool {
+		return request.Id == id && request.ToId == toId
+	})
+	if idx == -1 {
+		return nil, fmt.Errorf("swap request (id=%d) no longer exists", id)
+	}
+
+	request := d.Swaps[idx]
+	d.Swaps = slices.Delete(d.Swaps, idx, idx+1)
+	d.SynchronizeToFile()
+	return request, nil
+}
+
+// HandOverSpace Space of the user that can be handed over or swapped: a
+// permanently owned space (even if temporarily released) or a space reserved
+// for the current booking date. Returns nil if the user has no space.
+func (d *SpacesLot) HandOverSpace(userId string) *Space {
+	owned := d.OwnsSpace(userId)
+	if owned != nil {
+		return owned
+	}
+	return d.GetReservedSpace(userId)
+}
+
+// ApplySwap Hands over space of the initiator to the target. If the target
+// has a space as well both spaces are exchanged. All checks are made before
+// anything is changed so the swap is either applied completely or not at all.
+func (d *SpacesLot) ApplySwap(
+	request *SwapRequest,
+	bookingDate, now time.Time,
+) (SwapResult, error) {
+	if request.IsPermanent() {
+		return d.swapPermanent(request, bookingDate, now)
+	}
+	return d.swapReservations(request)
+}
+
+// swapReservations Exchanges temporary reservations of the current booking
+// date
+func (d *SpacesLot) swapReservations(request *SwapRequest) (SwapResult, error) {
+	given := d.GetReservedSpace(request.FromId)
+	if given == nil || !given.AutoRelease {
+		return SwapResult{}, fmt.Errorf("%s no longer has a reserved space", request.FromName)
+	}
+
+	received := d.GetReservedSpace(request.ToId)
+	if received != nil && !received.AutoRelease {
+		return SwapResult{}, fmt.Errorf(
+			"%s owns space %s - permanent spaces can only be swapped for a date range",
+			request.ToName,
+			received.Key(),
+		)
+	}
+
+	slog.Info("SPACE_SWAP", "request", request, "given", given.Key())
+	given.ReservedBy = request.ToName
+	given.ReservedById = request.ToId
+	given.ReservedTime = d.clock.Now()
+	if received != nil {
+		slog.Info("SPACE_SWAP", "request", request, "received", received.Key())
+		received.ReservedBy = request.FromName
+		received.ReservedById = request.FromId
+		received.ReservedTime = d.clock.Now()
+	}
+
+	d.SynchronizeToFile()
+	return SwapResult{Given: given, Received: received}, nil
+}
+
+// swapPermanent Releases permanent space of the initiator to the target for
+// the date range & if the target owns a permanent space releases it to the
+// initiator for the same range. Releases starting on the booking date are
+// activated immediately.
+func (d *SpacesLot) swapPermanent(
+	request *SwapRequest,
+	bookingDate, now time.Time,
+) (SwapResult, error) {
+	given := d.OwnsSpace(request.FromId)
+	if given == nil {
+		return SwapResult{}, fmt.Errorf("%s no longer owns a space", request.FromName)
+	}
+	received := d.OwnsSpace(request.ToId)
+
+	errTxt := common.CheckDateRange(*request.StartDate, *request.EndDate, now)
+	if errTxt != "" {
+		return SwapResult{}, errors.New(errTxt)
+	}
+
+	spaces := []*Space{given}
+	if received != nil {
+		spaces = append(spaces, received)
+	}
+	for _, space := range spaces {
+		overlaps := d.ToBeReleased.CheckOverlap(ReleaseInfo{
+			SpaceKey:  space.Key(),
+			StartDate: request.StartDate,
+			EndDate:   request.EndDate,
+			UniqueId:  -1,
+		})
+		if len(overlaps) > 0 {
+			return SwapResult{}, fmt.Errorf(
+				"space %s already has releases in the selected date range: %v",
+				space.Key(),
+				overlaps,
+			)
+		}
+	}
+
+	givenRelease := swapRelease{
+		ownerId:   request.FromId,
+		ownerName: request.FromName,
+		forId:     request.ToId,
+		forName:   request.ToName,
+	}
+	if received != nil {
+		givenRelease.pairedKey = received.Key()
+		d.releaseForSwap(received, swapRelease{
+			ownerId:   request.ToId,
+			ownerName: request.ToName,
+			forId:     request.FromId,
+			forName:   request.FromName,
+			pairedKey: given.Key(),
+		}, request, bookingDate, now)
+	}
+	d.releaseForSwap(given, givenRelease, request, bookingDate, now)
+
+	d.SynchronizeToFile()
+	return SwapResult{Given: given, Received: received}, nil
+}
+
+// swapRelease Owner of a space swapped for the date range & the user to whom
+// it is handed over
+type swapRelease struct {
+	ownerId   string
+	ownerName string
+	forId     string
+	forName   string
+	pairedKey SpaceKey
+}
+
+// releaseForSwap Adds release of owner's space reserved for the other user
+func (d *SpacesLot) releaseForSwap(
+	space *Space,
+	swap swapRelease,
+	request *SwapRequest,
+	bookingDate, now time.Time,
+) {
+	release := d.ToBeReleased.Add("", swap.ownerName, swap.ownerId, space, d.clock.Now())
+	start := *request.StartDate
+	end := *request.EndDate
+	release.StartDate = &start
+	release.EndDate = &end
+	release.ReservedForId = swap.forId
+	release.ReservedForName = swap.forName
+	release.PairedSpaceKey = swap.pairedKey
+	release.MarkSubmitted(swap.ownerName, now)
+
+	if !start.After(bookingDate) {
+		release.MarkActive(now)
+		d.reserveForSwap(space, release)
+	}
+	d.ToBeReleased.Update(release)
+}
+
+// reserveForSwap Reserves space of an active release for the user to whom it
+// was handed over (if any)
+func (d *SpacesLot) reserveForSwap(space *Space, release ReleaseInfo) {
+	if release.ReservedForId == "" || release.Cancelled {
+		return
+	}
+
+	slog.Info("SwapReserve", "space", space.Key(), "user", release.ReservedForName)
+	space.Reserved = true
+	space.AutoRelease = true
+	space.ReservedBy = release.ReservedForName
+	space.ReservedById = release.ReservedForId
+	space.ReservedTime = d.clock.Now()
+	space.Guest = nil
+}
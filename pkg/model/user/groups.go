@@ -0,0 +1,51 @@
+package user
+
+import (
+	"slices"
+	"strings"
+)
+
+// ParseGroups Parses comma separated group names (i.e. "management,
+// visitors"). Names are trimmed, lowercased & deduplicated.
+func ParseGroups(value string) []string {
+	var groups []string
+	for _, group := range strings.Split(value, ",") {
+		group = strings.ToLower(strings.TrimSpace(group))
+		if group != "" && !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// GetGroups Returns groups of the user
+func (m *Manager) GetGroups(userId string) []string {
+	user := m.getUser(userId)
+	if user == nil {
+		return nil
+	}
+	return user.Groups
+}
+
+// SetGroups Sets groups of the user
+func (m *Manager) SetGroups(userId string, groups []string) {
+	user := m.getUser(userId)
+	if user == nil {
+		return
+	}
+	user.Groups = groups
+}
+
+// AllGroups Returns sorted names of all groups that have at least one member
+func (m *Manager) AllGroups() []string {
+	var groups []string
+	for _, user := range m.users {
+		for _, group := range user.Groups {
+			if !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+	}
+	slices.Sort(groups)
+	return groups
+}
@@ -2,16 +2,21 @@ package user
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"slices"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/datafile"
 )
 
 type AccessRight int
 
-// NOTE: Currently access rights are not used
+// NOTE: Access rights are replaced by roles. They are only read to migrate
+// older users files.
 const (
 	STANDARD AccessRight = iota
 	ADMIN
@@ -40,78 +45,130 @@ type CompanyInfo[T CompanyId] struct {
 }
 
 type User struct {
-	Id                  string
-	Rights              AccessRight
-	HasPermanentParking bool `json:"has_parking"`
+	Id string
+	// Rights Deprecated: replaced by Roles
+	Rights AccessRight `json:",omitempty"`
+	Roles  []Role      `json:",omitempty"`
+	// ManagedFloors Floors managed by a floor manager
+	ManagedFloors []int `json:",omitempty"`
+	// Groups User groups (i.e. management) used to restrict which spaces
+	// the user can reserve
+	Groups []string `json:",omitempty"`
+	// Plates Car licence plates of the user (see NormalizePlate)
+	Plates []string `json:",omitempty"`
+	// NoShows Reservations which were released because the user did not
+	// check in
+	NoShows []NoShow `json:",omitempty"`
+	// BookingLimitedUntil Last day on which the user can't book spaces (set
+	// by admins i.e. after repeated no-shows)
+	BookingLimitedUntil *time.Time `json:",omitempty"`
+	// QuotaOverride Quota values set by admins which replace the default
+	// quota
+	QuotaOverride       *QuotaOverride `json:",omitempty"`
+	HasPermanentParking bool           `json:"has_parking"`
 	HcmInfo             []CompanyInfo[int]
 	BssInfo             []CompanyInfo[string]
 }
 
 type UsersMap map[string]*User
 
-func getUsers(path string) (users UsersMap) {
+// readUsers Reads & validates users file
+func readUsers(path string) (users UsersMap, err error) {
 	fileData, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Could not read users file (%s)", path)
+		return nil, fmt.Errorf("could not read users file (%s): %w", path, err)
 	}
 
 	err = json.Unmarshal(fileData, &users)
 	if err != nil {
-		log.Fatalf("Could not parse users file (%s). Error: %+v", path, err)
+		return nil, fmt.Errorf("could not parse users file (%s): %w", path, err)
 	}
 
-	loadedUsersNum := len(users)
-	if loadedUsersNum == 0 {
-		log.Fatalf("No users found in (%s).", path)
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no users found in (%s)", path)
 	}
 
-	slog.Info("INIT: User list loaded successfully", "users", loadedUsersNum)
-	return users
+	migrateUsersRights(users)
+	return users, nil
 }
 
 type Manager struct {
 	users         UsersMap
 	usersFilename string
+	// fileVersion Version of users file when it was last read or written
+	fileVersion datafile.Version
+	// configAdmins Slack user IDs that are admins according to config
+	configAdmins []string
+	// plateLookupRoles Roles that can look up car owners by plate
+	plateLookupRoles []Role
+	// defaultQuota Quota of users without an override (from config)
+	defaultQuota Quota
 }
 
 func NewManager(usersFilename string) *Manager {
-	usersMap := getUsers(usersFilename)
+	m := &Manager{usersFilename: usersFilename}
+	err := m.Reload()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	slog.Info("INIT: User list loaded successfully", "users", len(m.users))
+	return m
+}
 
-	return &Manager{
-		users:         usersMap,
-		usersFilename: usersFilename,
+// Reload Reads users file again. If the file is invalid the current users are
+// kept.
+func (m *Manager) Reload() error {
+	version := datafile.Stat(m.usersFilename)
+	users, err := readUsers(m.usersFilename)
+	if err != nil {
+		return err
 	}
+
+	m.users = users
+	m.fileVersion = version
+	return nil
 }
 
+// ChangedOnDisk Checks if users file was changed since it was last read or
+// written by the bot
+func (m *Manager) ChangedOnDisk() bool {
+	return datafile.Changed(m.usersFilename, m.fileVersion)
+}
+
+// SynchronizeToFile Writes users to file. If the file was changed on disk
+// since it was loaded (i.e. edited by hand) it is not overwritten - the
+// change is picked up by the next reload instead.
 func (m *Manager) SynchronizeToFile() {
 	data, err := json.MarshalIndent(m.users, "", "\t")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.WriteFile(m.usersFilename, data, 0o666)
-	if err != nil {
+	m.fileVersion, err = datafile.Write(m.usersFilename, data, m.fileVersion)
+	if errors.Is(err, datafile.ErrChanged) {
+		slog.Warn("Users file was not overwritten", "err", err)
+		return
+	} else if err != nil {
 		log.Fatal(err)
 	}
 	slog.Info("Wrote users list to file")
 }
 
-func (m *Manager) IsAdminId(userId string) bool {
-	for _, user := range m.users {
-		if user.Id == userId {
-			return user.Rights == ADMIN
-		}
-	}
-	return false
+// SetConfigAdmins Sets users which are always admins (regardless of their
+// rights in users file)
+func (m *Manager) SetConfigAdmins(userIds []string) {
+	m.configAdmins = userIds
 }
 
-func (m *Manager) SetAccessRights(userId string, rights AccessRight) {
-	for _, user := range m.users {
-		if user.Id == userId {
-			user.Rights = rights
-			return
-		}
+// IsAdminId Checks if user has the admin role (all permissions)
+func (m *Manager) IsAdminId(userId string) bool {
+	if slices.Contains(m.configAdmins, userId) {
+		return true
 	}
+
+	user := m.getUser(userId)
+	return user != nil && user.HasRole(RoleAdmin)
 }
 
 func (m *Manager) SetParkingPermission(userId string, hasParking bool) {
@@ -197,6 +254,33 @@ func (m *Manager) GetBssInfoFromUserId(userId string) []CompanyInfo[string] {
 	return nil
 }
 
+// GetCompanies Returns companies (teams) of the user based on their HCM & BSS
+// info
+func (m *Manager) GetCompanies(userId string) []Company {
+	var companies []Company
+	add := func(company Company) {
+		if company != UnknownCompany && !slices.Contains(companies, company) {
+			companies = append(companies, company)
+		}
+	}
+
+	for _, user := range m.users {
+		if user.Id != userId {
+			continue
+		}
+
+		for _, hcm := range user.HcmInfo {
+			add(hcm.Company)
+		}
+		for _, bss := range user.BssInfo {
+			if bss.Id != "" {
+				add(bss.Company)
+			}
+		}
+	}
+	return companies
+}
+
 func (m *Manager) GetUserIdFromHcmId(hcmId int, hcmCompany Company) string {
 	for _, user := range m.users {
 		for _, hcm := range user.HcmInfo {
@@ -219,6 +303,47 @@ func (m *Manager) GetUserIdFromBssId(bssId string, company Company) string {
 	return ""
 }
 
+// AllUsers Returns all users by their name. Users must not be modified.
+func (m *Manager) AllUsers() UsersMap {
+	users := make(UsersMap, len(m.users))
+	for name, user := range m.users {
+		users[name] = user
+	}
+	return users
+}
+
+// ImportUsers Inserts imported users or replaces name, parking rights, car
+// plates & HCM/BSS info of users with the same ID. Roles & managed floors are
+// kept.
+func (m *Manager) ImportUsers(imported UsersMap) {
+	importedIds := map[string]bool{}
+	for _, user := range imported {
+		importedIds[user.Id] = true
+	}
+
+	// NOTE: existing users are removed first so that users can swap names
+	existing := map[string]*User{}
+	for name, user := range m.users {
+		if importedIds[user.Id] {
+			existing[user.Id] = user
+			delete(m.users, name)
+		}
+	}
+
+	for name, importedUser := range imported {
+		user, found := existing[importedUser.Id]
+		if !found {
+			user = &User{Id: importedUser.Id}
+		}
+
+		user.HasPermanentParking = importedUser.HasPermanentParking
+		user.Plates = append([]string{}, importedUser.Plates...)
+		user.HcmInfo = append([]CompanyInfo[int]{}, importedUser.HcmInfo...)
+		user.BssInfo = append([]CompanyInfo[string]{}, importedUser.BssInfo...)
+		m.users[name] = user
+	}
+}
+
 func (m *Manager) AllUserNames() []string {
 	var users []string
 
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// noShowHistory How long no-shows are kept
+const noShowHistory = 180 * 24 * time.Hour
+
+// NoShow Reservation which was released because the user did not check in
+type NoShow struct {
+	Date  time.Time
+	Space string
+}
+
+// UserNoShows Number of no-shows of the user
+type UserNoShows struct {
+	UserName string
+	UserId   string
+	Count    int
+}
+
+// AddNoShow Records a no-show of the user. The user is added if they are not
+// in the users file yet. No-shows older than noShowHistory are dropped.
+func (m *Manager) AddNoShow(userId, userName string, noShow NoShow) {
+	if !m.Exists(userId) {
+		err := m.InsertUser(userId, userName)
+		if err != nil {
+			slog.Error("Failed to record no-show", "user", userName, "err", err)
+			return
+		}
+	}
+
+	user := m.getUser(userId)
+	user.NoShows = slices.DeleteFunc(user.NoShows, func(n NoShow) bool {
+		return noShow.Date.Sub(n.Date) > noShowHistory
+	})
+	user.NoShows = append(user.NoShows, noShow)
+}
+
+// GetNoShows Returns no-shows of the user since the given time
+func (m *Manager) GetNoShows(userId string, since time.Time) []NoShow {
+	user := m.getUser(userId)
+	if user == nil {
+		return nil
+	}
+
+	var noShows []NoShow
+	for _, noShow := range user.NoShows {
+		if !noShow.Date.Before(since) {
+			noShows = append(noShows, noShow)
+		}
+	}
+	return noShows
+}
+
+// RepeatNoShows Returns users with at least minCount no-shows since the given
+// time sorted by count (most first) & name
+func (m *Manager) RepeatNoShows(since time.Time, minCount int) []UserNoShows {
+	var offenders []UserNoShows
+	for name, user := range m.users {
+		count := len(m.GetNoShows(user.Id, since))
+		if count >= minCount {
+			offenders = append(offenders, UserNoShows{UserName: name, UserId: user.Id, Count: count})
+		}
+	}
+
+	slices.SortFunc(offenders, func(a, b UserNoShows) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.UserName, b.UserName)
+	})
+	return offenders
+}
+
+// BookingLimitedUntil Returns the last day on which the user can't book
+// spaces or nil if booking is not limited
+func (m *Manager) BookingLimitedUntil(userId string) *time.Time {
+	user := m.getUser(userId)
+	if user == nil {
+		return nil
+	}
+	return user.BookingLimitedUntil
+}
+
+// SetBookingLimitedUntil Limits booking of the user until the date
+// (including). nil removes the limit.
+func (m *Manager) SetBookingLimitedUntil(userId string, until *time.Time) {
+	user := m.getUser(userId)
+	if user == nil {
+		return
+	}
+	user.BookingLimitedUntil = until
+}
+
+// CheckBookingAllowed Returns an error if booking of the user is limited on
+// the day of now
+func (m *Manager) CheckBookingAllowed(userId string, now time.Time) error {
+	until := m.BookingLimitedUntil(userId)
+	if until == nil || now.Format("2006-01-02") > until.Format("2006-01-02") {
+		return nil
+	}
+	return fmt.Errorf(
+		"your booking rights are limited until %s because of missed check-ins",
+		until.Format("Mon 2006-01-02"),
+	)
+}
@@ -0,0 +1,54 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNoShows(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users.json")
+	err := os.WriteFile(usersFile, []byte(`{
+		"Late": {"Id": "U1"},
+		"Punctual": {"Id": "U2"}
+	}`), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(usersFile)
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	m.AddNoShow("U1", "Late", NoShow{Date: day(12), Space: "1 (Floor 1)"})
+	m.AddNoShow("U1", "Late", NoShow{Date: day(14), Space: "2 (Floor 1)"})
+	m.AddNoShow("U2", "Punctual", NoShow{Date: day(14), Space: "3 (Floor 1)"})
+	// unknown users are added
+	m.AddNoShow("U3", "New", NoShow{Date: day(15), Space: "4 (Floor 1)"})
+	if !m.Exists("U3") {
+		t.Fatal("expected user with no-show to be added")
+	}
+
+	if noShows := m.GetNoShows("U1", day(13)); len(noShows) != 1 {
+		t.Fatalf("expected 1 no-show since the date, got %v", noShows)
+	}
+	offenders := m.RepeatNoShows(day(1), 2)
+	if len(offenders) != 1 || offenders[0].UserId != "U1" || offenders[0].Count != 2 {
+		t.Fatalf("expected only U1 to be a repeat offender, got %v", offenders)
+	}
+
+	until := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	m.SetBookingLimitedUntil("U1", &until)
+	if m.CheckBookingAllowed("U1", day(16)) == nil {
+		t.Fatal("expected booking to be limited on the last day")
+	}
+	if err := m.CheckBookingAllowed("U1", day(17)); err != nil {
+		t.Fatalf("expected booking to be allowed after the limit, got %v", err)
+	}
+	m.SetBookingLimitedUntil("U1", nil)
+	if err := m.CheckBookingAllowed("U1", day(16)); err != nil {
+		t.Fatalf("expected removed limit to allow booking, got %v", err)
+	}
+}
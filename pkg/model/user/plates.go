@@ -0,0 +1,123 @@
+package user
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NormalizePlate Converts car plate to the format in which it is stored &
+// searched (uppercase without spaces or dashes)
+func NormalizePlate(plate string) string {
+	plate = strings.ToUpper(plate)
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, plate)
+}
+
+// ParsePlates Parses comma separated car plates (i.e. "ABC 123, XYZ-987").
+// Plates are normalized & deduplicated.
+func ParsePlates(value string) []string {
+	var plates []string
+	for _, plate := range strings.Split(value, ",") {
+		plate = NormalizePlate(plate)
+		if plate != "" && !slices.Contains(plates, plate) {
+			plates = append(plates, plate)
+		}
+	}
+	return plates
+}
+
+// PlateMatch Registered car plate & its owner
+type PlateMatch struct {
+	Plate    string
+	UserName string
+	UserId   string
+}
+
+// GetPlates Returns car plates of the user
+func (m *Manager) GetPlates(userId string) []string {
+	user := m.getUser(userId)
+	if user == nil {
+		return nil
+	}
+	return user.Plates
+}
+
+// SetPlates Sets car plates of the user. Plates registered by another user
+// are rejected & nothing is changed.
+func (m *Manager) SetPlates(userId string, plates []string) error {
+	user := m.getUser(userId)
+	if user == nil {
+		return fmt.Errorf("unknown user <@%s>", userId)
+	}
+
+	for name, other := range m.users {
+		if other.Id == userId {
+			continue
+		}
+		for _, plate := range plates {
+			if slices.Contains(other.Plates, plate) {
+				return fmt.Errorf("car plate %s is already registered by %s", plate, name)
+			}
+		}
+	}
+
+	user.Plates = plates
+	return nil
+}
+
+// FindPlates Returns registered plates that contain the (partial) plate
+// sorted by plate
+func (m *Manager) FindPlates(query string) []PlateMatch {
+	query = NormalizePlate(query)
+	if query == "" {
+		return nil
+	}
+
+	var matches []PlateMatch
+	for name, user := range m.users {
+		for _, plate := range user.Plates {
+			if strings.Contains(plate, query) {
+				matches = append(matches, PlateMatch{
+					Plate:    plate,
+					UserName: name,
+					UserId:   user.Id,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b PlateMatch) int {
+		return strings.Compare(a.Plate, b.Plate)
+	})
+	return matches
+}
+
+// SetPlateLookupRoles Sets roles that are allowed to look up car owners by
+// plate
+func (m *Manager) SetPlateLookupRoles(roles []Role) {
+	m.plateLookupRoles = roles
+}
+
+// CanLookupPlates Checks if user can look up car owners by plate. Admins
+// always can.
+func (m *Manager) CanLookupPlates(userId string) bool {
+	if m.IsAdminId(userId) {
+		return true
+	}
+
+	user := m.getUser(userId)
+	if user == nil {
+		return false
+	}
+	for _, role := range m.plateLookupRoles {
+		if user.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlates(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users.json")
+	err := os.WriteFile(usersFile, []byte(`{
+		"Owner": {"Id": "U1", "Plates": ["ABC123"]},
+		"Security": {"Id": "U2", "Roles": ["security"]},
+		"Standard": {"Id": "U3"}
+	}`), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(usersFile)
+	m.SetPlateLookupRoles([]Role{RoleSecurity})
+
+	plates := ParsePlates("xyz 987, abc-123, ABC123")
+	if len(plates) != 2 || plates[0] != "XYZ987" || plates[1] != "ABC123" {
+		t.Fatalf("unexpected parsed plates: %v", plates)
+	}
+
+	err = m.SetPlates("U3", plates)
+	if err == nil {
+		t.Fatal("expected plate of another user to be rejected")
+	}
+	err = m.SetPlates("U3", []string{"XYZ987"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := m.FindPlates("c1")
+	if len(matches) != 1 || matches[0].UserId != "U1" {
+		t.Fatalf("expected partial match of owner's plate, got: %v", matches)
+	}
+	if len(m.FindPlates("xyz-9")) != 1 || len(m.FindPlates("QQQ")) != 0 {
+		t.Fatal("unexpected plate lookup result")
+	}
+
+	if !m.CanLookupPlates("U2") || m.CanLookupPlates("U3") {
+		t.Fatal("only configured roles should be able to look up plates")
+	}
+}
@@ -0,0 +1,100 @@
+package user
+
+// Quota Limits of reservations of users without a permanent space. 0 means
+// unlimited.
+type Quota struct {
+	// PerWeek & PerMonth Max parking reservations per week (Mon-Sun) & per
+	// calendar month
+	PerWeek  int
+	PerMonth int
+	// ConsecutiveDays Max consecutive working days with a parking reservation
+	ConsecutiveDays int
+	// HorizonDays Max number of days ahead for which workspaces & parking
+	// guests can be booked
+	HorizonDays int
+}
+
+// QuotaOverride Quota values of a user set by admins. nil values use the
+// default quota.
+type QuotaOverride struct {
+	PerWeek         *int `json:",omitempty"`
+	PerMonth        *int `json:",omitempty"`
+	ConsecutiveDays *int `json:",omitempty"`
+	HorizonDays     *int `json:",omitempty"`
+}
+
+// IsEmpty Checks if all values use the default quota
+func (o QuotaOverride) IsEmpty() bool {
+	return o.PerWeek == nil && o.PerMonth == nil && o.ConsecutiveDays == nil && o.HorizonDays == nil
+}
+
+// Equal Checks if both overrides have the same values
+func (o QuotaOverride) Equal(other QuotaOverride) bool {
+	equal := func(a, b *int) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	return equal(o.PerWeek, other.PerWeek) &&
+		equal(o.PerMonth, other.PerMonth) &&
+		equal(o.ConsecutiveDays, other.ConsecutiveDays) &&
+		equal(o.HorizonDays, other.HorizonDays)
+}
+
+// Apply Returns the quota with overridden values replaced
+func (o QuotaOverride) Apply(quota Quota) Quota {
+	for _, value := range []struct {
+		override *int
+		quota    *int
+	}{
+		{o.PerWeek, &quota.PerWeek},
+		{o.PerMonth, &quota.PerMonth},
+		{o.ConsecutiveDays, &quota.ConsecutiveDays},
+		{o.HorizonDays, &quota.HorizonDays},
+	} {
+		if value.override != nil {
+			*value.quota = *value.override
+		}
+	}
+	return quota
+}
+
+// SetDefaultQuota Sets quota of users without an override
+func (m *Manager) SetDefaultQuota(quota Quota) {
+	m.defaultQuota = quota
+}
+
+// DefaultQuota Returns quota of users without an override
+func (m *Manager) DefaultQuota() Quota {
+	return m.defaultQuota
+}
+
+// GetQuota Returns quota of the user (default quota with values overridden
+// by admins)
+func (m *Manager) GetQuota(userId string) Quota {
+	user := m.getUser(userId)
+	if user == nil || user.QuotaOverride == nil {
+		return m.defaultQuota
+	}
+	return user.QuotaOverride.Apply(m.defaultQuota)
+}
+
+// GetQuotaOverride Returns quota values of the user set by admins
+func (m *Manager) GetQuotaOverride(userId string) QuotaOverride {
+	user := m.getUser(userId)
+	if user == nil || user.QuotaOverride == nil {
+		return QuotaOverride{}
+	}
+	return *user.QuotaOverride
+}
+
+// SetQuotaOverride Sets quota values of the user. Empty override removes it.
+func (m *Manager) SetQuotaOverride(userId string, override QuotaOverride) {
+	user := m.getUser(userId)
+	if user == nil {
+		return
+	}
+	if override.IsEmpty() {
+		user.QuotaOverride = nil
+		return
+	}
+	user.QuotaOverride = &override
+}
@@ -0,0 +1,29 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuotaOverride(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users.json")
+	err := os.WriteFile(usersFile, []byte(`{"Standard": {"Id": "U1"}}`), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(usersFile)
+	m.SetDefaultQuota(Quota{PerWeek: 3, PerMonth: 10})
+
+	unlimited := 0
+	m.SetQuotaOverride("U1", QuotaOverride{PerWeek: &unlimited})
+	if quota := m.GetQuota("U1"); quota.PerWeek != 0 || quota.PerMonth != 10 {
+		t.Fatalf("expected only weekly quota to be overridden, got %+v", quota)
+	}
+
+	m.SetQuotaOverride("U1", QuotaOverride{})
+	if quota := m.GetQuota("U1"); quota.PerWeek != 3 {
+		t.Fatalf("expected empty override to restore the default, got %+v", quota)
+	}
+}
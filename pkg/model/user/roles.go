@@ -0,0 +1,216 @@
+package user
+
+import (
+	"log/slog"
+	"slices"
+)
+
+type Role string
+
+const (
+	// RoleAdmin Has all permissions (same as the former ADMIN access right)
+	RoleAdmin          Role = "admin"
+	RoleParkingAdmin   Role = "parking_admin"
+	RoleWorkspaceAdmin Role = "workspace_admin"
+	// RoleFloorManager Can manage parking spaces & workspaces only on the
+	// floors assigned to the user (see User.ManagedFloors)
+	RoleFloorManager Role = "floor_manager"
+	// RoleHrOperator Manages HCM/BSS integration info of users
+	RoleHrOperator Role = "hr_operator"
+	// RoleAuditor Can open admin modals but can't change anything
+	RoleAuditor Role = "auditor"
+	// RoleSecurity Building security. Can look up car owners by plate if
+	// configured (see config plate_lookup_roles)
+	RoleSecurity Role = "security"
+)
+
+var AllRoles = []Role{
+	RoleAdmin,
+	RoleParkingAdmin,
+	RoleWorkspaceAdmin,
+	RoleFloorManager,
+	RoleHrOperator,
+	RoleAuditor,
+	RoleSecurity,
+}
+
+var RoleNameMap = map[Role]string{
+	RoleAdmin:          "Admin",
+	RoleParkingAdmin:   "Parking Admin",
+	RoleWorkspaceAdmin: "Workspace Admin",
+	RoleFloorManager:   "Floor Manager",
+	RoleHrOperator:     "HR Integration Operator",
+	RoleAuditor:        "Auditor (read-only)",
+	RoleSecurity:       "Security",
+}
+
+var RoleDescriptionMap = map[Role]string{
+	RoleAdmin:          "Everything.",
+	RoleParkingAdmin:   "Manage parking spaces & reservations.",
+	RoleWorkspaceAdmin: "Manage workspaces & reservations.",
+	RoleFloorManager:   "Manage reservations on managed floors.",
+	RoleHrOperator:     "Manage users' HCM/BSS info.",
+	RoleAuditor:        "View admin settings without changing them.",
+	RoleSecurity:       "Look up car owners by plate.",
+}
+
+type Permission string
+
+const (
+	// PermManageUsers Change user roles & parking rights
+	PermManageUsers Permission = "manage users"
+	// PermManageHr Change users' HCM/BSS info
+	PermManageHr Permission = "manage hr info"
+	// PermEditParkingSpaces Add/remove parking spaces, plans & reset times
+	PermEditParkingSpaces Permission = "edit parking spaces"
+	// PermEditWorkspaces Add/remove workspaces, plans, reset times & channels
+	PermEditWorkspaces Permission = "edit workspaces"
+	// PermManageParking Release/book parking spaces on behalf of others
+	PermManageParking Permission = "manage parking"
+	// PermManageWorkspaces Release workspaces on behalf of others
+	PermManageWorkspaces Permission = "manage workspaces"
+	// PermAudit View admin modals
+	PermAudit Permission = "audit"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermManageUsers,
+		PermManageHr,
+		PermEditParkingSpaces,
+		PermEditWorkspaces,
+		PermManageParking,
+		PermManageWorkspaces,
+		PermAudit,
+	},
+	RoleParkingAdmin:   {PermEditParkingSpaces, PermManageParking},
+	RoleWorkspaceAdmin: {PermEditWorkspaces, PermManageWorkspaces},
+	RoleHrOperator:     {PermManageHr},
+	RoleAuditor:        {PermAudit},
+}
+
+// floorManagerPermissions Permissions of floor managers on their managed
+// floors
+var floorManagerPermissions = []Permission{PermManageParking, PermManageWorkspaces}
+
+func (u *User) HasRole(role Role) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+func (u *User) hasPermission(perm Permission) bool {
+	for _, role := range u.Roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateRights Converts the former ADMIN access right to admin role
+func (u *User) migrateRights() bool {
+	if u.Rights != ADMIN {
+		return false
+	}
+
+	if !u.HasRole(RoleAdmin) {
+		u.Roles = append(u.Roles, RoleAdmin)
+	}
+	u.Rights = STANDARD
+	return true
+}
+
+func migrateUsersRights(users UsersMap) {
+	var migrated []string
+	for name, user := range users {
+		if user.migrateRights() {
+			migrated = append(migrated, name)
+		}
+	}
+
+	if len(migrated) > 0 {
+		slog.Info("Migrated admin rights to admin role", "users", migrated)
+	}
+}
+
+func (m *Manager) getUser(userId string) *User {
+	for _, user := range m.users {
+		if user.Id == userId {
+			return user
+		}
+	}
+	return nil
+}
+
+// HasPermission Checks if any of user's roles grants the permission.
+// NOTE: floor managers don't have any permission outside of their floors -
+// use HasFloorPermission for floor specific checks.
+func (m *Manager) HasPermission(userId string, perm Permission) bool {
+	if slices.Contains(m.configAdmins, userId) {
+		return true
+	}
+
+	user := m.getUser(userId)
+	return user != nil && user.hasPermission(perm)
+}
+
+// HasFloorPermission Checks if user has the permission in general or as a
+// floor manager of the given floor
+func (m *Manager) HasFloorPermission(userId string, perm Permission, floor int) bool {
+	if m.HasPermission(userId, perm) {
+		return true
+	}
+
+	user := m.getUser(userId)
+	return user != nil &&
+		user.HasRole(RoleFloorManager) &&
+		slices.Contains(user.ManagedFloors, floor) &&
+		slices.Contains(floorManagerPermissions, perm)
+}
+
+// IsFloorManager Checks if user manages at least one floor
+func (m *Manager) IsFloorManager(userId string) bool {
+	user := m.getUser(userId)
+	return user != nil && user.HasRole(RoleFloorManager) && len(user.ManagedFloors) > 0
+}
+
+// HasAnyPermission Checks if user has at least one of the permissions
+func (m *Manager) HasAnyPermission(userId string, perms ...Permission) bool {
+	for _, perm := range perms {
+		if m.HasPermission(userId, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) GetRoles(userId string) []Role {
+	user := m.getUser(userId)
+	if user == nil {
+		return nil
+	}
+	return user.Roles
+}
+
+func (m *Manager) SetRoles(userId string, roles []Role) {
+	user := m.getUser(userId)
+	if user == nil {
+		return
+	}
+	user.Roles = roles
+}
+
+func (m *Manager) GetManagedFloors(userId string) []int {
+	user := m.getUser(userId)
+	if user == nil {
+		return nil
+	}
+	return user.ManagedFloors
+}
+
+func (m *Manager) SetManagedFloors(userId string, floors []int) {
+	user := m.getUser(userId)
+	if user == nil {
+		return
+	}
+	user.ManagedFloors = floors
+}
@@ -0,0 +1,58 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRolesMigrationAndPermissions(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users.json")
+	err := os.WriteFile(usersFile, []byte(`{
+		"Old Admin": {"Id": "U1", "Rights": 1},
+		"Standard": {"Id": "U2", "Rights": 0},
+		"Floor Manager": {"Id": "U3", "Roles": ["floor_manager"], "ManagedFloors": [4]},
+		"Auditor": {"Id": "U4", "Roles": ["auditor"]}
+	}`), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(usersFile)
+	m.SetConfigAdmins([]string{"U5"})
+
+	tests := []struct {
+		name   string
+		userId string
+		perm   Permission
+		floor  int
+		want   bool
+	}{
+		{"migrated admin", "U1", PermManageUsers, 4, true},
+		{"standard user", "U2", PermManageParking, 4, false},
+		{"floor manager on managed floor", "U3", PermManageParking, 4, true},
+		{"floor manager on other floor", "U3", PermManageParking, 5, false},
+		{"floor manager can't edit spaces", "U3", PermEditParkingSpaces, 4, false},
+		{"auditor can audit", "U4", PermAudit, 4, true},
+		{"auditor can't manage users", "U4", PermManageUsers, 4, false},
+		{"config admin", "U5", PermManageHr, 4, true},
+		{"unknown user", "U6", PermAudit, 4, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.HasFloorPermission(tt.userId, tt.perm, tt.floor)
+			if got != tt.want {
+				t.Fatalf("HasFloorPermission(%s, %s, %d) = %v, want %v",
+					tt.userId, tt.perm, tt.floor, got, tt.want)
+			}
+		})
+	}
+
+	if !m.IsAdminId("U1") {
+		t.Fatal("user with former ADMIN rights should have admin role")
+	}
+	if m.getUser("U1").Rights != STANDARD {
+		t.Fatal("migrated user should not keep ADMIN rights")
+	}
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/datafile"
+)
+
+// watchInterval How often config & data files are checked for changes
+const watchInterval = 10 * time.Second
+
+// Watcher Reloads config & data files when they are changed on disk (i.e.
+// edited by hand) so that the bot doesn't have to be restarted.
+type Watcher struct {
+	data           *Data
+	configFilename string
+	envPath        string
+	configVersion  datafile.Version
+	// onConfig Called (with data lock held) after a valid config was
+	// reloaded, i.e. to update schedules
+	onConfig func(*config.Config) error
+	// lastReloadErr Used to report an invalid data file only once & not on
+	// every check until it is fixed
+	lastReloadErr string
+
+	wg sync.WaitGroup
+}
+
+func NewWatcher(
+	data *Data,
+	configFilename, envPath string,
+	onConfig func(*config.Config) error,
+) *Watcher {
+	return &Watcher{
+		data:           data,
+		configFilename: configFilename,
+		envPath:        envPath,
+		configVersion:  datafile.Stat(configFilename),
+		onConfig:       onConfig,
+	}
+}
+
+func (w *Watcher) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	go w.run(ctx)
+	return nil
+}
+
+func (w *Watcher) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check Reloads config & data files that changed since the last check.
+// Invalid files are reported & ignored until they are fixed.
+func (w *Watcher) Check() {
+	w.data.Lock()
+	defer w.data.Unlock()
+
+	err := w.data.ReloadChanged()
+	if err != nil && err.Error() != w.lastReloadErr {
+		slog.Error("Failed to reload data file. Keeping current data", "err", err)
+	}
+	w.lastReloadErr = ""
+	if err != nil {
+		w.lastReloadErr = err.Error()
+	}
+
+	if !datafile.Changed(w.configFilename, w.configVersion) {
+		return
+	}
+	// NOTE: invalid config is not retried until it is changed again
+	w.configVersion = datafile.Stat(w.configFilename)
+
+	conf, err := config.NewConfig(w.configFilename, w.envPath)
+	if err != nil {
+		slog.Error("Failed to reload config. Keeping current config", "err", err)
+		return
+	}
+
+	if w.onConfig != nil {
+		err = w.onConfig(conf)
+	}
+	if err == nil {
+		err = w.data.ApplyConfig(conf)
+	}
+	if err != nil {
+		slog.Error("Failed to apply reloaded config", "err", err)
+		return
+	}
+
+	slog.Info(
+		"Reloaded config. NOTE: slack, storage, company & channel settings require restart",
+		"file", w.configFilename,
+	)
+}
@@ -0,0 +1,157 @@
+package parking_spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+func (m *Manager) handleEditTempReleaseParking(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+) []event.ResponseAction {
+	space := m.data.ParkingLot.GetSpace(actionValues.SpaceKey)
+	if space == nil {
+		return nil
+	}
+
+	release, err := m.ownRelease(data.UserId, actionValues)
+	if err == nil && !release.IsEditable() {
+		err = fmt.Errorf("dates of releases of swapped spaces can't be changed")
+	}
+	if err != nil {
+		errorTxt := err.Error()
+		var modal slack.ModalViewRequest
+		if actionValues.ModalType == views.PersonalModal {
+			modal = m.personalView.Generate(data.UserId, errorTxt)
+		} else {
+			modal = m.bookingView.Generate(data.UserId, views.DefaultPageNum, errorTxt)
+		}
+		return []event.ResponseAction{
+			common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errorTxt),
+		}
+	}
+
+	editModal := m.releaseView.GenerateEdit(space, release, data.ViewId, actionValues.ModalType)
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, editModal)}
+}
+
+func (m *Manager) handleEditReleaseSubmission(data *slackApi.ViewSubmission) *common.Response {
+	actionValues := views.ActionValues{}.Decode(data.PrivateMetadata)
+	release, errTxt := m.editRelease(data, actionValues)
+	if errTxt != "" {
+		slog.Error("Failed to edit release", "user", data.UserName, "err", errTxt)
+		errTxt = fmt.Sprintf("Release of space %s was not changed: %s", actionValues.SpaceKey, errTxt)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+	slog.Info("Release edited", "user", data.UserName, "release", release)
+
+	var modal slack.ModalViewRequest
+	if actionValues.ModalType == views.PersonalModal {
+		modal = m.personalView.Generate(data.UserId, "")
+	} else {
+		modal = m.bookingView.Generate(data.UserId, views.DefaultPageNum, "")
+	}
+	return common.NewResponseEvent(
+		data.UserName,
+		common.NewUpdateViewAction(data.TriggerId, actionValues.RootViewId, modal, ""),
+	)
+}
+
+// editRelease Validates dates of the edit release modal & stores them
+func (m *Manager) editRelease(
+	data *slackApi.ViewSubmission,
+	actionValues views.ActionValues,
+) (*spaces.ReleaseInfo, string) {
+	release, err := m.ownRelease(data.UserId, actionValues)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	location := m.data.Clock.Now().Location()
+	startDate, err := parseDate(
+		data.IValueString(views.ReleaseBlockId, views.ReleaseStartDateActionId),
+		location,
+	)
+	if err != nil {
+		return nil, err.Error()
+	}
+	endDate, err := parseDate(
+		data.IValueString(views.ReleaseBlockId, views.ReleaseEndDateActionId),
+		location,
+	)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	return m.changeReleaseDates(release, startDate, endDate, data.UserName, data.UserId)
+}
+
+// changeReleaseDates Validates new dates of a submitted release & stores
+// them. Start date of an active release can't be changed & it can't end
+// before the booking date (it has to be cancelled instead). A scheduled
+// release that now starts on (or before) the booking date is activated
+// right away - same as a newly submitted release.
+func (m *Manager) changeReleaseDates(
+	release spaces.ReleaseInfo,
+	startDate, endDate time.Time,
+	userName, userId string,
+) (*spaces.ReleaseInfo, string) {
+	if !release.IsEditable() {
+		return nil, "dates of releases of swapped spaces can't be changed"
+	}
+
+	now := m.data.Clock.Now()
+	resetTime := m.data.ParkingLot.SpaceResetTime(release.SpaceKey)
+	bookingDate := m.data.Calendar.BookingDate(now, resetTime)
+
+	if release.Active {
+		if !common.EqualDate(startDate, *release.StartDate) {
+			return nil, fmt.Sprintf(
+				"the release already started on %s so only its end date can be changed",
+				release.StartDate.Format("2006-01-02"),
+			)
+		}
+		if endDate.Before(bookingDate) {
+			return nil, fmt.Sprintf(
+				"the space is already released for %s. Cancel the release to end it earlier",
+				bookingDate.Format("2006-01-02"),
+			)
+		}
+	} else if errTxt := common.CheckDateRange(startDate, endDate, now); errTxt != "" {
+		return nil, errTxt
+	}
+
+	release.StartDate = &startDate
+	release.EndDate = &endDate
+	overlaps := m.data.ParkingLot.ToBeReleased.CheckOverlap(release)
+	if len(overlaps) > 0 {
+		return nil, fmt.Sprintf(
+			"%s overlaps with some of the previously scheduled releases: %v",
+			release.DateRange(),
+			overlaps,
+		)
+	}
+
+	if !release.Active && !startDate.After(bookingDate) {
+		m.data.ParkingLot.Release(release.SpaceKey, userName, userId)
+		release.MarkActive(now)
+	}
+
+	err := m.data.ParkingLot.ToBeReleased.Update(release)
+	if err != nil {
+		return nil, err.Error()
+	}
+	m.data.ParkingLot.SynchronizeToFile()
+	return &release, ""
+}
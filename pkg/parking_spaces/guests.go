@@ -0,0 +1,225 @@
+package parking_spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+// guestRootView View from which guest modal was opened. It is updated after
+// guest booking is submitted.
+type guestRootView struct {
+	viewId    string
+	modalType views.ModalType
+}
+
+func (m *Manager) handleGuestBooking(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+) []event.ResponseAction {
+	m.guestRootViews[data.UserId] = guestRootView{
+		viewId:    data.ViewId,
+		modalType: actionValues.ModalType,
+	}
+
+	guestModal := m.guestView.Generate(data.UserId, "")
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, guestModal)}
+}
+
+func (m *Manager) handleCancelGuestBooking(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+) []event.ResponseAction {
+	errorTxt := ""
+	booking, err := m.data.ParkingLot.CancelGuestBooking(data.UserId, actionValues.GuestId)
+	if err != nil {
+		errorTxt = fmt.Sprintf("Failed to cancel guest booking: %v", err)
+	} else {
+		slog.Info("Cancelled guest booking", "host", data.UserName, "booking", booking)
+	}
+
+	guestModal := m.guestView.Generate(data.UserId, errorTxt)
+	return []event.ResponseAction{
+		common.NewUpdateViewAction(data.TriggerId, data.ViewId, guestModal, errorTxt),
+	}
+}
+
+func (m *Manager) handleGuestSubmission(data *slackApi.ViewSubmission) *common.Response {
+	booking, errTxt := m.bookGuest(data)
+	if errTxt != "" {
+		slog.Error("Failed guest booking", "host", data.UserName, "err", errTxt)
+		errTxt = fmt.Sprintf("Guest booking failed: %s", errTxt)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+
+	txt := fmt.Sprintf(
+		"Guest booking for *%s* (%s) on *%s* is registered. "+
+			"A space will be assigned at the reset before that day and "+
+			"you will receive a message with the space to forward to your guest.",
+		booking.Name,
+		booking.Plate,
+		booking.Date.Format("2006-01-02"),
+	)
+	if booking.SpaceKey != "" {
+		txt = m.guestSpaceMessage(booking)
+	}
+	actions := []event.ResponseAction{common.NewPostAction(data.UserId, txt, false)}
+
+	rootView, found := m.guestRootViews[data.UserId]
+	delete(m.guestRootViews, data.UserId)
+	if found {
+		var modal slack.ModalViewRequest
+		if rootView.modalType == views.PersonalModal {
+			modal = m.personalView.Generate(data.UserId, "")
+		} else {
+			modal = m.bookingView.Generate(data.UserId, views.DefaultPageNum, "")
+		}
+		actions = append(
+			actions,
+			common.NewUpdateViewAction(data.TriggerId, rootView.viewId, modal, ""),
+		)
+	}
+
+	return common.NewResponseEvent(data.UserName, actions...)
+}
+
+// bookGuest Validates guest booking & reserves a space immediately if
+// reservations for the selected date are already being made
+func (m *Manager) bookGuest(data *slackApi.ViewSubmission) (*spaces.GuestBooking, string) {
+	name := strings.TrimSpace(data.IValueString(views.GuestNameBlockId, views.GuestNameActionId))
+	plate := user.NormalizePlate(
+		data.IValueString(views.GuestPlateBlockId, views.GuestPlateActionId),
+	)
+	if name == "" || plate == "" {
+		return nil, "guest name and car plate are required"
+	}
+
+	floorStr := data.IValueString(views.GuestFloorBlockId, views.GuestFloorActionId)
+	floor, err := strconv.Atoi(floorStr)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid floor %q", floorStr)
+	}
+
+	dateStr := data.IValueString(views.GuestDateBlockId, views.GuestDateActionId)
+	now := m.data.Clock.Now()
+	date, err := time.ParseInLocation("2006-01-02", dateStr, now.Location())
+	if err != nil {
+		return nil, fmt.Sprintf("invalid date %q", dateStr)
+	}
+
+	resetTime := m.data.ParkingLot.ResetTime(spaces.MakeFloorStr(floor))
+	bookingDate := m.data.Calendar.BookingDate(now, resetTime)
+	if date.Before(bookingDate) {
+		return nil, fmt.Sprintf(
+			"can't book for %s - reservations are currently made for %s",
+			dateStr,
+			bookingDate.Format("2006-01-02"),
+		)
+	}
+	if !m.data.Calendar.IsWorkingDay(date) {
+		return nil, fmt.Sprintf("%s is not a working day", dateStr)
+	}
+	err = spaces.CheckHorizon(m.data.UserManager.GetQuota(data.UserId), m.data.Clock.Now(), date)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	if m.data.ParkingLot.GuestBookingsInWeek(data.UserId, date) >= m.guestsPerWeek {
+		return nil, fmt.Sprintf(
+			"you already have %d guest bookings in the week of %s",
+			m.guestsPerWeek,
+			dateStr,
+		)
+	}
+
+	guest := spaces.Guest{
+		Name:     name,
+		Plate:    plate,
+		HostId:   data.UserId,
+		HostName: data.UserName,
+	}
+	booking := m.data.ParkingLot.AddGuestBooking(guest, floor, date)
+	slog.Info("Added guest booking", "host", data.UserName, "booking", booking)
+
+	if common.EqualDate(date, bookingDate) {
+		err := m.data.ParkingLot.ReserveForGuest(
+			booking,
+			m.data.UserManager.GetGroups(data.UserId),
+		)
+		if err != nil {
+			m.data.ParkingLot.CancelGuestBooking(data.UserId, booking.Id)
+			return nil, err.Error()
+		}
+	}
+
+	m.data.ParkingLot.SynchronizeToFile()
+	return booking, ""
+}
+
+// assignGuests Reserves spaces for guests arriving on the next working day &
+// informs their hosts
+func (m *Manager) assignGuests(cTime time.Time, resetTime calendar.TimeOfDay) {
+	nextWorkingDay := m.data.Calendar.NextWorkingDay(cTime)
+	assigned, failed := m.data.ParkingLot.AssignGuests(
+		nextWorkingDay,
+		resetTime,
+		m.data.UserManager.GetGroups,
+	)
+
+	var actions []event.ResponseAction
+	for _, booking := range assigned {
+		txt := m.guestSpaceMessage(booking)
+		actions = append(actions, common.NewPostAction(booking.HostId, txt, false))
+	}
+	for _, booking := range failed {
+		txt := fmt.Sprintf(
+			":warning: Couldn't find a free parking space on %s for your guest "+
+				"*%s* (%s) on *%s*. The guest booking was cancelled.",
+			spaces.MakeFloorStr(booking.Floor),
+			booking.Name,
+			booking.Plate,
+			booking.Date.Format("2006-01-02"),
+		)
+		actions = append(actions, common.NewPostAction(booking.HostId, txt, false))
+	}
+
+	if len(actions) == 0 {
+		return
+	}
+	m.eventManager.Publish(common.NewResponseEvent("Parking AssignGuests Timer", actions...))
+}
+
+// guestSpaceMessage Message for the host to forward to the guest
+func (m *Manager) guestSpaceMessage(booking *spaces.GuestBooking) string {
+	txt := fmt.Sprintf(
+		"Parking space *%s* is reserved for your guest *%s* (%s) on *%s*.",
+		booking.SpaceKey,
+		booking.Name,
+		booking.Plate,
+		booking.Date.Format("2006-01-02"),
+	)
+
+	link, found := m.data.ParkingLot.FloorPlanLink(booking.Floor)
+	if found {
+		txt += fmt.Sprintf(
+			"\nFloor plan: <%s|%s plan>",
+			link,
+			spaces.MakeFloorStr(booking.Floor),
+		)
+	}
+	return txt + "\n_Forward this message to your guest._"
+}
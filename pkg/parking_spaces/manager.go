@@ -1,6 +1,7 @@
 package parking_spaces
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -8,11 +9,15 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/floor_map"
 	"github.com/AngelVI13/slack-bot/pkg/model"
 	"github.com/AngelVI13/slack-bot/pkg/model/my_err"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	parkingModel "github.com/AngelVI13/slack-bot/pkg/parking_spaces/model"
 	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
 	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
@@ -24,19 +29,28 @@ const (
 	SlashCmd     = "/parking"
 	TestSlashCmd = "/test-park"
 
-	ResetParking = "Reset parking status"
-	ResetHour    = parkingModel.ResetHour
-	ResetMin     = parkingModel.ResetMin
+	ResetParking   = "Reset parking status"
+	RemindReleases = "Remind parking releases"
 )
 
 type Manager struct {
-	eventManager   *event.EventManager
-	data           *parkingModel.ParkingData
-	bookingView    *views.Booking
-	releaseView    *views.Release
-	personalView   *views.Personal
-	reportPersonId string
-	testingActive  bool
+	eventManager *event.EventManager
+	data         *parkingModel.ParkingData
+	bookingView  *views.Booking
+	releaseView  *views.Release
+	personalView *views.Personal
+	guestView    *views.Guests
+	swapView     *views.Swaps
+	extendView   *views.Extend
+	// subscriptionView Subscriptions to free space notifications
+	subscriptionView *views.Subscriptions
+	guestRootViews   map[string]guestRootView
+	guestsPerWeek    int
+	reportPersonId   string
+	testingActive    bool
+	// notificationThrottle Min time between free space notifications sent
+	// to the same user
+	notificationThrottle time.Duration
 }
 
 func NewManager(
@@ -46,26 +60,45 @@ func NewManager(
 ) *Manager {
 	parkingData := parkingModel.NewParkingData(data)
 
-	bookingView := views.NewBooking(Identifier, parkingData)
+	bookingView := views.NewBooking(Identifier, parkingData, floor_map.NewLinks(conf))
 	releaseView := views.NewRelease(Identifier, parkingData)
 	personalView := views.NewPersonal(Identifier, parkingData)
+	guestView := views.NewGuests(Identifier, parkingData, conf.GuestBookingsPerWeek)
+	swapView := views.NewSwaps(Identifier, parkingData)
+	subscriptionView := views.NewSubscriptions(Identifier, parkingData, conf.NotificationThrottle)
+	extendView := views.NewExtend(Identifier)
 
 	bookingView.Title = common.MakeTitle(bookingView.Title, conf.TestingActive)
 	releaseView.Title = common.MakeTitle(releaseView.Title, conf.TestingActive)
 	personalView.Title = common.MakeTitle(personalView.Title, conf.TestingActive)
+	guestView.Title = common.MakeTitle(guestView.Title, conf.TestingActive)
+	swapView.Title = common.MakeTitle(swapView.Title, conf.TestingActive)
+	subscriptionView.Title = common.MakeTitle(subscriptionView.Title, conf.TestingActive)
+	extendView.Title = common.MakeTitle(extendView.Title, conf.TestingActive)
 
 	return &Manager{
-		eventManager:   eventManager,
-		data:           parkingData,
-		bookingView:    bookingView,
-		releaseView:    releaseView,
-		personalView:   personalView,
-		reportPersonId: conf.ReportPersonId,
-		testingActive:  conf.TestingActive,
+		eventManager:         eventManager,
+		data:                 parkingData,
+		bookingView:          bookingView,
+		releaseView:          releaseView,
+		personalView:         personalView,
+		guestView:            guestView,
+		swapView:             swapView,
+		extendView:           extendView,
+		subscriptionView:     subscriptionView,
+		guestRootViews:       map[string]guestRootView{},
+		guestsPerWeek:        conf.GuestBookingsPerWeek,
+		reportPersonId:       conf.ReportPersonId,
+		testingActive:        conf.TestingActive,
+		notificationThrottle: conf.NotificationThrottle,
 	}
 }
 
 func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
 	switch e.Type() {
 	case event.SlashCmdEvent:
 		data := e.(*slackApi.Slash)
@@ -84,20 +117,35 @@ func (m *Manager) Consume(e event.Event) {
 		data := e.(*slackApi.BlockAction)
 
 		response := m.handleBlockActions(data)
-		if response == nil {
-			return
+		if response != nil {
+			m.eventManager.Publish(response)
 		}
-
-		m.eventManager.Publish(response)
+		m.publishFreeSpaceNotifications(data.UserName)
 
 	case event.TimerEvent:
 		data := e.(*event.TimerDone)
-		if data.Label != ResetParking {
+		if data.Label == RemindReleases {
+			m.handleReleaseReminders(data)
+			return
+		} else if data.Label != ResetParking {
+			return
+		}
+
+		// NOTE: reservations made on a non-working day are for the next
+		// working day so they should not be reset
+		if !m.data.Calendar.IsWorkingDay(data.Scheduled) {
+			slog.Info("Skip ReleaseSpaces: not a working day", "scheduled", data.Scheduled)
 			return
 		}
 
-		slog.Info("ReleaseSpaces")
-		err := m.data.ParkingLot.ReleaseSpaces(data.Time)
+		// NOTE: every floor reset time has its own job so only floors
+		// that reset at the scheduled time are released
+		resetTime := calendar.TimeOfDayOf(data.Scheduled)
+		slog.Info("ReleaseSpaces", "resetTime", resetTime)
+		// NOTE: scheduled time is used so that a caught up reset (i.e. the
+		// one of Thursday run on Friday morning) is done for the same next
+		// working day as the missed one
+		err := m.data.ParkingLot.ReleaseSpaces(data.Scheduled, resetTime, m.data.Calendar)
 		if err != nil {
 			postAction := common.NewPostAction(
 				m.reportPersonId,
@@ -108,20 +156,29 @@ func (m *Manager) Consume(e event.Event) {
 			response := common.NewResponseEvent("Parking ReleaseSpaces Timer", postAction)
 			m.eventManager.Publish(response)
 		}
+
+		m.assignGuests(data.Scheduled, resetTime)
+		m.publishFreeSpaceNotifications("Parking ReleaseSpaces Timer")
 	case event.ViewSubmissionEvent:
 		data := e.(*slackApi.ViewSubmission)
 
-		// NOTE: Currently only modal with ViewSubmission for parking pkg
-		// is related to parking booking (temporary release of parking space)
-		if data.Title != m.releaseView.Title {
-			return
+		var response *common.Response
+		switch data.Title {
+		case m.releaseView.Title:
+			response = m.handleViewSubmission(data)
+		case m.guestView.Title:
+			response = m.handleGuestSubmission(data)
+		case m.swapView.Title:
+			response = m.handleSwapSubmission(data)
+		case m.subscriptionView.Title:
+			response = m.handleSubscriptionSubmission(data)
+		case m.extendView.Title:
+			response = m.handleExtendSubmission(data)
 		}
-
-		response := m.handleViewSubmission(data)
-		if response == nil {
-			return
+		if response != nil {
+			m.eventManager.Publish(response)
 		}
-		m.eventManager.Publish(response)
+		m.publishFreeSpaceNotifications(data.UserName)
 	case event.ViewOpenedEvent:
 		data := e.(*slackApi.ViewOpened)
 
@@ -137,21 +194,31 @@ func (m *Manager) Context() string {
 	return Identifier
 }
 
-func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
-	errorTxt := ""
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
 
-	var modal slack.ModalViewRequest
-	if m.data.ParkingLot.OwnsSpace(data.UserId) != nil {
-		modal = m.personalView.Generate(data.UserId, errorTxt)
-	} else {
-		modal = m.bookingView.Generate(data.UserId, views.DefaultPageNum, errorTxt)
-	}
+// Stop Nothing to flush - parking spaces are written to file by model.Data
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
 
-	action := common.NewOpenViewAction(data.TriggerId, modal)
+func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
+	action := common.NewOpenViewAction(data.TriggerId, m.generateStartModal(data.UserId))
 	response := common.NewResponseEvent(data.UserName, action)
 	return response
 }
 
+// generateStartModal Space owners start in the personal modal, everyone else
+// in the booking modal
+func (m *Manager) generateStartModal(userId string) slack.ModalViewRequest {
+	errorTxt := ""
+	if m.data.ParkingLot.OwnsSpace(userId) != nil {
+		return m.personalView.Generate(userId, errorTxt)
+	}
+	return m.bookingView.Generate(userId, views.DefaultPageNum, errorTxt)
+}
+
 func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Response {
 	var actions []event.ResponseAction
 
@@ -181,10 +248,45 @@ func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Respons
 			actionValues := views.ActionValues{}.Decode(action.Value)
 			actions = m.handleTempReleaseParking(data, actionValues)
 
-		case views.CancelTempReleaseParkingActionId:
+		case views.CancelTempReleaseParkingActionId, views.EndReleaseEarlyActionId:
 			actionValues := views.ActionValues{}.Decode(action.Value)
 			actions = m.handleCancelTempReleaseParking(data, actionValues)
 
+		case views.EditTempReleaseParkingActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleEditTempReleaseParking(data, actionValues)
+
+		case views.ExtendReleaseActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleExtendRelease(data, actionValues)
+
+		case views.GuestBookingActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleGuestBooking(data, actionValues)
+
+		case views.CancelGuestBookingActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleCancelGuestBooking(data, actionValues)
+
+		case views.OpenBookingActionId:
+			modal := m.generateStartModal(data.UserId)
+			actions = append(actions, common.NewOpenViewAction(data.TriggerId, modal))
+
+		case views.SwapActionId:
+			actions = m.handleSwap(data)
+
+		case views.SubscribeActionId:
+			actions = m.handleSubscribe(data)
+
+		case views.RemoveSubscriptionActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleRemoveSubscription(data, actionValues)
+
+		case views.AcceptSwapActionId, views.DeclineSwapActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			accepted := action.ActionID == views.AcceptSwapActionId
+			actions = m.handleSwapAnswer(data, actionValues, accepted)
+
 		case views.ReleaseStartDateActionId, views.ReleaseEndDateActionId:
 			selectedDate := action.SelectedDate
 			isStartDate := action.ActionID == views.ReleaseStartDateActionId
@@ -201,6 +303,22 @@ func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Respons
 				actions,
 				common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errorTxt),
 			)
+		case views.AttributesOptionId:
+			var selectedValues []string
+			for _, option := range action.SelectedOptions {
+				selectedValues = append(selectedValues, option.Value)
+			}
+			selectedAttributes, err := spaces.ParseAttributes(selectedValues)
+			errorTxt := ""
+			if err != nil {
+				errorTxt = err.Error()
+			}
+			m.data.SelectedAttributes[data.UserId] = selectedAttributes
+			modal := m.bookingView.Generate(data.UserId, views.DefaultPageNum, errorTxt)
+			actions = append(
+				actions,
+				common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errorTxt),
+			)
 		case views.PagingOptionId:
 			selectedPageValue := data.IValueSingle(
 				views.PagingActionId,
@@ -241,6 +359,11 @@ func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Respons
 }
 
 func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Response {
+	// NOTE: only the modal of an edited release refers to an existing release
+	if data.PrivateMetadata != "" {
+		return m.handleEditReleaseSubmission(data)
+	}
+
 	var actions []event.ResponseAction
 
 	submittedData, ok := data.Values[views.ReleaseBlockId]
@@ -262,7 +385,7 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 
 	releaseInfo.StartDate = startDate
 	releaseInfo.EndDate = endDate
-	releaseInfo.MarkSubmitted(data.UserName)
+	releaseInfo.MarkSubmitted(data.UserName, m.data.Clock.Now())
 
 	overlaps := m.data.ParkingLot.ToBeReleased.CheckOverlap(releaseInfo)
 	if len(overlaps) > 0 {
@@ -289,16 +412,18 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 		return common.NewResponseEvent(data.UserName, actions...)
 	}
 
-	currentTime := time.Now()
+	now := m.data.Clock.Now()
+	resetTime := m.data.ParkingLot.SpaceResetTime(releaseInfo.SpaceKey)
+	bookingDate := m.data.Calendar.BookingDate(now, resetTime)
 
-	if common.EqualDate(*startDate, currentTime) || (currentTime.Before(*startDate) &&
-		startDate.Sub(currentTime).Hours() < 24 &&
-		currentTime.Hour() >= ResetHour && currentTime.Minute() >= ResetMin) {
-		// Directly release space in two cases:
+	if !startDate.After(bookingDate) {
+		// Directly release space if release starts on (or before) the day
+		// for which reservations are currently made:
 		// * Release starts from today
-		// * Release starts from tomorrow & current time is after Reset time
+		// * Release starts from the next working day & current time is after
+		//   Reset time (or today is not a working day)
 		m.data.ParkingLot.Release(releaseInfo.SpaceKey, data.UserName, data.UserId)
-		releaseInfo.MarkActive()
+		releaseInfo.MarkActive(now)
 	}
 
 	m.data.ParkingLot.ToBeReleased.Update(releaseInfo)
@@ -331,7 +456,7 @@ func (m *Manager) validateTempReleaseDateInputs(
 		return nil, nil, m.handleViewSubmissionError(data, "no start date provided")
 	}
 
-	currentLocation := time.Now().Location()
+	currentLocation := m.data.Clock.Now().Location()
 
 	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, currentLocation)
 	if err != nil {
@@ -358,7 +483,7 @@ func (m *Manager) validateTempReleaseDateInputs(
 		return nil, nil, m.handleViewSubmissionError(data, errTxt)
 	}
 
-	errorTxt := common.CheckDateRange(startDate, endDate)
+	errorTxt := common.CheckDateRange(startDate, endDate, m.data.Clock.Now())
 	if errorTxt != "" {
 		return nil, nil, m.handleViewSubmissionError(data, errorTxt)
 	}
@@ -414,12 +539,33 @@ func (m *Manager) handleReserveParking(
 
 	parkingSpace := actionValues.SpaceKey
 
-	errStr := m.data.ParkingLot.Reserve(
-		parkingSpace,
-		data.UserName,
-		data.UserId,
-		autoRelease,
-	)
+	var errStr string
+	// NOTE: owners of permanent spaces are not limited as they don't have to
+	// check in & don't use quotas
+	err := m.checkReservationAllowed(data.UserId, parkingSpace)
+	if autoRelease && err != nil {
+		errStr = fmt.Sprintf("*Error*: Could not reserve *%s* - %v", parkingSpace, err)
+	} else {
+		errStr = m.data.ParkingLot.Reserve(
+			parkingSpace,
+			data.UserName,
+			data.UserId,
+			m.data.UserManager.GetGroups(data.UserId),
+			autoRelease,
+		)
+	}
+
+	// NOTE: reserve button of a free space notification message -> there is
+	// no modal to update so the message is updated instead
+	if data.ViewId == "" {
+		txt := fmt.Sprintf(":white_check_mark: You reserved space *%s*.", parkingSpace)
+		if errStr != "" {
+			txt = errStr
+		}
+		return []event.ResponseAction{
+			common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, txt),
+		}
+	}
 
 	var bookingModal slack.ModalViewRequest
 	if actionValues.ModalType == views.PersonalModal {
@@ -459,9 +605,10 @@ func (m *Manager) handleTempReleaseParking(
 		data.UserName,
 		data.UserId,
 		chosenParkingSpace,
+		m.data.Clock.Now(),
 	)
 
-	releaseModal := m.releaseView.Generate(chosenParkingSpace, info.Check())
+	releaseModal := m.releaseView.Generate(chosenParkingSpace, info.Check(m.data.Clock.Now()))
 	action := common.NewPushViewAction(data.TriggerId, releaseModal)
 	actions = append(actions, action)
 	return actions
@@ -484,27 +631,80 @@ func (m *Manager) handleCancelTempReleaseParking(
 	errorTxt := ""
 
 	releaseInfo, err := m.data.ParkingLot.ToBeReleased.Get(parkingSpace, releaseId)
+	// NOTE: buttons of reminder messages can be pressed long after the
+	// release was removed (& its id reused by another release)
+	if data.ViewId == "" && err == nil && !m.isOwnRelease(releaseInfo, data.UserId) {
+		err = my_err.ErrNotFound
+	}
 	if errors.Is(err, my_err.ErrNotFound) {
 		errorTxt = fmt.Sprintf(
 			"Couldn't find release info (id=%d) for space %s",
 			releaseId,
 			parkingSpace,
 		)
-	} else if !releaseInfo.Active {
+	} else {
+		actions = append(actions, m.cancelPairedRelease(releaseInfo, data.UserId)...)
+		errorTxt = m.cancelTempRelease(chosenParkingSpace, releaseInfo)
+	}
+	m.data.ParkingLot.SynchronizeToFile()
+
+	// NOTE: cancel button of a release reminder message -> there is no modal
+	// to update so the message is updated instead
+	if data.ViewId == "" {
+		txt := fmt.Sprintf(
+			":white_check_mark: The release of space *%s* (%s) is cancelled.",
+			parkingSpace,
+			releaseInfo.DateRange(),
+		)
+		if errorTxt != "" {
+			txt = errorTxt
+		}
+		return append(actions, common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, txt))
+	}
+
+	var bookingModal slack.ModalViewRequest
+	if actionValues.ModalType == views.PersonalModal {
+		bookingModal = m.personalView.Generate(data.UserId, errorTxt)
+	} else {
+		bookingModal = m.bookingView.Generate(data.UserId, views.DefaultPageNum, errorTxt)
+	}
+
+	action := common.NewUpdateViewAction(
+		data.TriggerId,
+		data.ViewId,
+		bookingModal,
+		errorTxt,
+	)
+	actions = append(actions, action)
+	return actions
+}
+
+// cancelTempRelease Cancels temporary release & returns space to owner (now or
+// at the end of the booking day if someone already reserved it). Returns
+// text to be shown to the user (if any).
+func (m *Manager) cancelTempRelease(
+	chosenParkingSpace *spaces.Space,
+	releaseInfo spaces.ReleaseInfo,
+) string {
+	parkingSpace := chosenParkingSpace.Key()
+	errorTxt := ""
+
+	if !releaseInfo.Active {
 		slog.Info("Cancel scheduled (not active) temp. release", "space", parkingSpace, "releaseInfo", releaseInfo)
 		err := m.data.ParkingLot.ToBeReleased.Remove(releaseInfo)
 		if err != nil {
 			slog.Error(
-				"failed to remove release", "space", parkingSpace, "id", releaseId,
+				"failed to remove release", "space", parkingSpace, "id", releaseInfo.UniqueId,
 				"releaseInfo", releaseInfo, "err", err,
 			)
 		}
-	} else if releaseInfo.StartDate.After(time.Now()) {
+	} else if releaseInfo.StartDate.After(m.data.Clock.Now()) {
 		slog.Info("Cancel temp. release & return to owner", "space", parkingSpace, "releaseInfo", releaseInfo)
 		chosenParkingSpace.Reserved = true
 		chosenParkingSpace.AutoRelease = false
 		chosenParkingSpace.ReservedBy = releaseInfo.OwnerName
 		chosenParkingSpace.ReservedById = releaseInfo.OwnerId
+		chosenParkingSpace.Guest = nil
 
 		err := m.data.ParkingLot.ToBeReleased.Remove(releaseInfo)
 		if err != nil {
@@ -513,9 +713,11 @@ func (m *Manager) handleCancelTempReleaseParking(
 				parkingSpace, err)
 		}
 	} else { // release data was before now - i.e. temp release is currently active
-		now := time.Now()
-		// If user cancelled before the daily reset
-		if (now.Hour() < ResetHour) || (now.Hour() == ResetHour && now.Minute() < ResetMin) {
+		now := m.data.Clock.Now()
+		resetTime := m.data.ParkingLot.SpaceResetTime(parkingSpace)
+		bookingDate := m.data.Calendar.BookingDate(now, resetTime)
+		// If user cancelled before the daily reset on a working day
+		if common.EqualDate(bookingDate, now) {
 			// Somebody already booked it for the day -> return it at end of day
 			if chosenParkingSpace.Reserved {
 				slog.Info(
@@ -525,10 +727,9 @@ func (m *Manager) handleCancelTempReleaseParking(
 				releaseInfo.MarkCancelled()
 				m.data.ParkingLot.ToBeReleased.Update(releaseInfo)
 				errorTxt = fmt.Sprintf(
-					"Temporary release cancelled. The space %s will be returned to you today at %d:%02d",
+					"Temporary release cancelled. The space %s will be returned to you today at %s",
 					parkingSpace,
-					ResetHour,
-					ResetMin,
+					resetTime,
 				)
 			} else {
 				// if parking space was not already reserved for the day transfer it to owner
@@ -545,22 +746,21 @@ func (m *Manager) handleCancelTempReleaseParking(
 					slog.Error("Failed removing release info", "space", parkingSpace, "err", err)
 				}
 			}
-		} else { // User cancelled space after EOD
+		} else { // User cancelled space after EOD (or on a non-working day)
 			if chosenParkingSpace.Reserved {
 				// if parking space was already reserved by someone else -> transfer
-				// back to owner on the day after tomorrow
+				// back to owner at the end of the next working day
 				slog.Info(
-					"Temporary release cancelled (after eod). Space is taken. Return to owner tomorrow after eod.",
+					"Temporary release cancelled (after eod). Space is taken. Return to owner on next working day after eod.",
 					"space", parkingSpace, "releaseInfo", releaseInfo)
 				errorTxt = fmt.Sprintf(
-					`Temporary release cancelled but someone already reserved the space for tomorrow. The space %s will be returned to you tomorrow at %d:%02d.`,
+					`Temporary release cancelled but someone already reserved the space for %s. The space %s will be returned to you on %s at %s.`,
+					bookingDate.Format("2006-01-02"),
 					parkingSpace,
-					ResetHour,
-					ResetMin,
+					bookingDate.Format("2006-01-02"),
+					resetTime,
 				)
-				hoursTillMidnight := 24 - now.Hour()
-				tomorrow := now.Add(time.Duration(hoursTillMidnight) * time.Hour)
-				releaseInfo.EndDate = &tomorrow
+				releaseInfo.EndDate = &bookingDate
 				releaseInfo.MarkCancelled()
 				m.data.ParkingLot.ToBeReleased.Update(releaseInfo)
 			} else {
@@ -581,23 +781,7 @@ func (m *Manager) handleCancelTempReleaseParking(
 			}
 		}
 	}
-	m.data.ParkingLot.SynchronizeToFile()
-
-	var bookingModal slack.ModalViewRequest
-	if actionValues.ModalType == views.PersonalModal {
-		bookingModal = m.personalView.Generate(data.UserId, errorTxt)
-	} else {
-		bookingModal = m.bookingView.Generate(data.UserId, views.DefaultPageNum, errorTxt)
-	}
-
-	action := common.NewUpdateViewAction(
-		data.TriggerId,
-		data.ViewId,
-		bookingModal,
-		errorTxt,
-	)
-	actions = append(actions, action)
-	return actions
+	return errorTxt
 }
 
 func (m *Manager) handleReleaseParking(
@@ -605,8 +789,12 @@ func (m *Manager) handleReleaseParking(
 	actionValues views.ActionValues,
 ) []event.ResponseAction {
 	parkingSpace := actionValues.SpaceKey
-	isReleaserAdmin := m.data.UserManager.IsAdminId(data.UserId)
 	space := m.data.ParkingLot.GetSpace(parkingSpace)
+	isReleaserAdmin := m.data.UserManager.HasFloorPermission(
+		data.UserId,
+		user.PermManageParking,
+		space.Floor,
+	)
 	isSpaceTempReserved := space.Reserved && space.AutoRelease
 
 	actions := []event.ResponseAction{}
@@ -659,13 +847,18 @@ func (m *Manager) handleReleaseRange(
 	selectedDate string,
 	isStartDate bool,
 ) []event.ResponseAction {
-	currentLocation := time.Now().Location()
+	currentLocation := m.data.Clock.Now().Location()
 	date, err := time.ParseInLocation("2006-01-02", selectedDate, currentLocation)
 	if err != nil {
 		slog.Error("Failed to parse date format", "date", selectedDate, "err", err)
 		return nil
 	}
 
+	// NOTE: dates of an edited release are only validated on submission
+	if data.PrivateMetadata != "" {
+		return nil
+	}
+
 	releaseInfo, err := m.data.ParkingLot.ToBeReleased.GetByViewId(data.ViewId)
 	// NOTE: releaseInfo is created when the user clicks "Release" button
 	if errors.Is(err, my_err.ErrNotFound) {
@@ -684,7 +877,7 @@ func (m *Manager) handleReleaseRange(
 	}
 	m.data.ParkingLot.ToBeReleased.Update(releaseInfo)
 
-	errTxt := releaseInfo.Check()
+	errTxt := releaseInfo.Check(m.data.Clock.Now())
 	space := m.data.ParkingLot.GetSpace(releaseInfo.SpaceKey)
 	if space == nil {
 		slog.Error("Failed to get space from key", "key", releaseInfo.SpaceKey)
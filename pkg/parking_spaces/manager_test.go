@@ -0,0 +1,470 @@
+package parking_spaces
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/clock"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+)
+
+const (
+	testOwner   = "owner"
+	testOwnerId = "U_OWNER"
+	testOther   = "other"
+	testOtherId = "U_OTHER"
+
+	testUsers = `{
+	"owner": {"Id": "U_OWNER", "Rights": 0, "has_parking": true},
+	"other": {"Id": "U_OTHER", "Rights": 0, "has_parking": true}
+}`
+	testSpaces = `{
+	"UnitSpaces": {
+		"1st floor 1": {
+			"Number": 1,
+			"Floor": 1,
+			"Reserved": true,
+			"ReservedBy": "owner",
+			"ReservedById": "U_OWNER"
+		}
+	}
+}`
+)
+
+var testReset = calendar.TimeOfDay{Hour: 17}
+
+// 2026-10-12 is a Monday
+func day(d, hour, min int) time.Time {
+	return time.Date(2026, 10, d, hour, min, 0, 0, time.UTC)
+}
+
+func newTestManager(t *testing.T, now time.Time) (*Manager, *clock.Fake) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.json":      testUsers,
+		"parking.json":    testSpaces,
+		"workspaces.json": testSpaces,
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &config.Config{
+		UsersFilename:      filepath.Join(dir, "users.json"),
+		ParkingFilename:    filepath.Join(dir, "parking.json"),
+		WorkspacesFilename: filepath.Join(dir, "workspaces.json"),
+		Schedules: config.SchedulesConfig{
+			ParkingReset:    testReset,
+			WorkspacesReset: testReset,
+		},
+	}
+	clk := clock.NewFake(now)
+	data := model.NewData(conf, clk)
+	return NewManager(event.NewEventManager(), data, conf), clk
+}
+
+func (m *Manager) testSpace() *spaces.Space {
+	return m.data.ParkingLot.GetSpace(spaces.MakeSpaceKey(1, 1))
+}
+
+// addTestRelease Adds a submitted release of the owner's space. The release
+// is activated if it starts on the current booking date (same as when
+// submitted through the release modal).
+func (m *Manager) addTestRelease(t *testing.T, start, end int) spaces.ReleaseInfo {
+	t.Helper()
+	space := m.testSpace()
+	now := m.data.Clock.Now()
+	release := m.data.ParkingLot.ToBeReleased.Add("viewId", testOwner, testOwnerId, space, now)
+	startDate := day(start, 0, 0)
+	endDate := day(end, 0, 0)
+	release.StartDate = &startDate
+	release.EndDate = &endDate
+
+	release.MarkSubmitted(testOwner, now)
+	bookingDate := m.data.Calendar.BookingDate(now, testReset)
+	if !startDate.After(bookingDate) {
+		m.data.ParkingLot.Release(space.Key(), testOwner, testOwnerId)
+		release.MarkActive(now)
+	}
+
+	err := m.data.ParkingLot.ToBeReleased.Update(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return release
+}
+
+func (m *Manager) reset(at time.Time) {
+	m.Consume(&event.TimerDone{
+		Label:     ResetParking,
+		Time:      at,
+		Scheduled: at,
+	})
+}
+
+func TestCancelTempRelease(t *testing.T) {
+	tests := []struct {
+		name string
+		// releasedAt Time at which owner released the space
+		releasedAt time.Time
+		start, end int
+		// takenBy Someone reserved the released space before cancellation
+		takenBy    bool
+		cancelAt   time.Time
+		wantOwner  bool
+		wantEndDay int
+		// returnAt Reset at which the space is returned to the owner if it
+		// was taken at the time of cancellation
+		returnAt time.Time
+	}{
+		{
+			name:       "scheduled release is removed",
+			releasedAt: day(13, 10, 0),
+			start:      15,
+			end:        16,
+			cancelAt:   day(13, 11, 0),
+			wantOwner:  true,
+		},
+		{
+			name:       "release activated after reset for tomorrow",
+			releasedAt: day(13, 17, 30),
+			start:      14,
+			end:        16,
+			takenBy:    true,
+			cancelAt:   day(13, 18, 0),
+			wantOwner:  true,
+		},
+		{
+			name:       "before reset & space free",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        21,
+			cancelAt:   day(14, 16, 59),
+			wantOwner:  true,
+		},
+		{
+			name:       "before reset & space taken",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        21,
+			takenBy:    true,
+			cancelAt:   day(14, 16, 59),
+			wantEndDay: 14,
+			returnAt:   day(14, 17, 0),
+		},
+		{
+			name:       "after reset & space free",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        21,
+			cancelAt:   day(14, 17, 0),
+			wantOwner:  true,
+		},
+		{
+			name:       "after reset & space taken",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        21,
+			takenBy:    true,
+			cancelAt:   day(14, 17, 0),
+			wantEndDay: 15,
+			returnAt:   day(15, 17, 0),
+		},
+		{
+			name:       "friday after reset & space taken",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        21,
+			takenBy:    true,
+			cancelAt:   day(16, 17, 30),
+			wantEndDay: 19,
+			returnAt:   day(19, 17, 0),
+		},
+		{
+			name:       "weekend & space taken",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        21,
+			takenBy:    true,
+			cancelAt:   day(17, 12, 0),
+			wantEndDay: 19,
+			returnAt:   day(19, 17, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clk := newTestManager(t, tt.releasedAt)
+			release := m.addTestRelease(t, tt.start, tt.end)
+
+			// run all resets between release & cancellation
+			for reset := testReset.On(tt.releasedAt); reset.Before(tt.cancelAt); reset = reset.AddDate(0, 0, 1) {
+				if reset.After(tt.releasedAt) {
+					clk.Set(reset)
+					m.reset(reset)
+				}
+			}
+
+			clk.Set(tt.cancelAt)
+			space := m.testSpace()
+			if tt.takenBy {
+				errTxt := m.data.ParkingLot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+				if errTxt != "" {
+					t.Fatalf("failed to reserve released space: %s", errTxt)
+				}
+			}
+
+			m.handleCancelTempReleaseParking(
+				&slackApi.BlockAction{
+					Interaction: slackApi.Interaction{
+						BaseEvent: slackApi.BaseEvent{
+							UserName: testOwner,
+							UserId:   testOwnerId,
+						},
+					},
+				},
+				views.ActionValues{
+					SpaceKey:  space.Key(),
+					ModalType: views.PersonalModal,
+					ReleaseId: release.UniqueId,
+				},
+			)
+
+			if tt.wantOwner {
+				if !space.Reserved || space.ReservedById != testOwnerId || space.AutoRelease {
+					t.Errorf("space not returned to owner: %+v", space.ReservedProps)
+				}
+				if releases := m.data.ParkingLot.ToBeReleased.GetAll(space.Key()); len(releases) != 0 {
+					t.Errorf("release not removed: %v", releases)
+				}
+				return
+			}
+
+			if space.ReservedById != testOtherId {
+				t.Errorf("space reservation of other user was removed: %+v", space.ReservedProps)
+			}
+
+			updated, err := m.data.ParkingLot.ToBeReleased.Get(space.Key(), release.UniqueId)
+			if err != nil || !updated.InUse {
+				t.Fatalf("release not found after cancel: %v", err)
+			}
+			if !updated.Cancelled || updated.EndDate.Day() != tt.wantEndDay {
+				t.Errorf(
+					"release = {Cancelled:%v EndDate:%v}; want cancelled with end day %d",
+					updated.Cancelled,
+					updated.EndDate,
+					tt.wantEndDay,
+				)
+			}
+
+			// reset right before the expected return keeps the space taken
+			m.reset(tt.returnAt.AddDate(0, 0, -1))
+			if space.Reserved && space.ReservedById == testOwnerId {
+				t.Errorf("space returned to owner before %v", tt.returnAt)
+			}
+
+			m.reset(tt.returnAt)
+			if !space.Reserved || space.ReservedById != testOwnerId || space.AutoRelease {
+				t.Errorf("space not returned to owner at %v: %+v", tt.returnAt, space.ReservedProps)
+			}
+		})
+	}
+}
+
+func TestResetSkipsNonWorkingDays(t *testing.T) {
+	saturday := day(17, 10, 0)
+	m, _ := newTestManager(t, saturday)
+	space := m.testSpace()
+	space.Reserved = false
+
+	// reservation made on saturday is for monday
+	errTxt := m.data.ParkingLot.Reserve(space.Key(), testOther, testOtherId, nil, true)
+	if errTxt != "" {
+		t.Fatal(errTxt)
+	}
+
+	m.reset(testReset.On(day(17, 0, 0)))
+	m.reset(testReset.On(day(18, 0, 0)))
+	if !space.Reserved || space.ReservedById != testOtherId {
+		t.Fatalf("reservation removed by weekend reset: %+v", space.ReservedProps)
+	}
+
+	m.reset(testReset.On(day(19, 0, 0)))
+	if space.Reserved {
+		t.Errorf("reservation not removed by monday reset: %+v", space.ReservedProps)
+	}
+}
+
+func TestCatchUpResetUsesScheduledTime(t *testing.T) {
+	m, clk := newTestManager(t, day(13, 9, 0))
+	// release ends on friday
+	m.addTestRelease(t, 13, 16)
+	space := m.testSpace()
+
+	for _, d := range []int{13, 14} {
+		clk.Set(testReset.On(day(d, 0, 0)))
+		m.reset(testReset.On(day(d, 0, 0)))
+	}
+
+	// thursday reset is missed & caught up on friday morning
+	clk.Set(day(16, 8, 0))
+	m.Consume(&event.TimerDone{
+		Label:     ResetParking,
+		Time:      day(16, 8, 0),
+		Scheduled: testReset.On(day(15, 0, 0)),
+		CatchUp:   true,
+	})
+	if space.Reserved {
+		t.Fatalf("space returned to owner before the release ended: %+v", space.ReservedProps)
+	}
+
+	m.reset(testReset.On(day(16, 0, 0)))
+	if !space.Reserved || space.ReservedById != testOwnerId {
+		t.Errorf("space not returned to owner at friday reset: %+v", space.ReservedProps)
+	}
+}
+
+func TestEditRelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		releasedAt time.Time
+		start, end int
+		editAt     time.Time
+		newStart   int
+		newEnd     int
+		wantErr    bool
+		// wantReleased Space is free right after the edit
+		wantReleased bool
+	}{
+		{
+			name:       "scheduled release is moved",
+			releasedAt: day(13, 10, 0),
+			start:      15,
+			end:        16,
+			editAt:     day(13, 11, 0),
+			newStart:   19,
+			newEnd:     21,
+		},
+		{
+			name:         "scheduled release moved to today is activated",
+			releasedAt:   day(13, 10, 0),
+			start:        15,
+			end:          16,
+			editAt:       day(13, 11, 0),
+			newStart:     13,
+			newEnd:       16,
+			wantReleased: true,
+		},
+		{
+			name:       "scheduled release can't start in the past",
+			releasedAt: day(13, 10, 0),
+			start:      15,
+			end:        16,
+			editAt:     day(13, 11, 0),
+			newStart:   12,
+			newEnd:     16,
+			wantErr:    true,
+		},
+		{
+			name:         "active release is extended",
+			releasedAt:   day(13, 9, 0),
+			start:        13,
+			end:          14,
+			editAt:       day(13, 11, 0),
+			newStart:     13,
+			newEnd:       21,
+			wantReleased: true,
+		},
+		{
+			name:       "start of active release can't be changed",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        14,
+			editAt:     day(13, 11, 0),
+			newStart:   14,
+			newEnd:     21,
+			wantErr:    true,
+		},
+		{
+			name:       "active release can't end before booking date",
+			releasedAt: day(13, 9, 0),
+			start:      13,
+			end:        16,
+			editAt:     day(14, 17, 30),
+			newStart:   13,
+			newEnd:     14,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clk := newTestManager(t, tt.releasedAt)
+			release := m.addTestRelease(t, tt.start, tt.end)
+			clk.Set(tt.editAt)
+
+			edited, errTxt := m.changeReleaseDates(
+				release,
+				day(tt.newStart, 0, 0),
+				day(tt.newEnd, 0, 0),
+				testOwner,
+				testOwnerId,
+			)
+			stored, err := m.data.ParkingLot.ToBeReleased.Get(release.SpaceKey, release.UniqueId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantErr {
+				if errTxt == "" {
+					t.Fatalf("expected edit to fail, got %v", edited)
+				}
+				if stored.DateRange() != release.DateRange() {
+					t.Errorf("release changed after failed edit: %v", stored)
+				}
+				return
+			}
+			if errTxt != "" {
+				t.Fatalf("edit failed: %s", errTxt)
+			}
+
+			if stored.StartDate.Day() != tt.newStart || stored.EndDate.Day() != tt.newEnd {
+				t.Errorf("release = %v; want %d -> %d", stored.DateRange(), tt.newStart, tt.newEnd)
+			}
+			if m.testSpace().Reserved == tt.wantReleased {
+				t.Errorf("space reserved = %v; want released = %v", m.testSpace().Reserved, tt.wantReleased)
+			}
+			if stored.Active != tt.wantReleased {
+				t.Errorf("release active = %v; want %v", stored.Active, tt.wantReleased)
+			}
+		})
+	}
+}
+
+func TestEditReleaseOverlap(t *testing.T) {
+	m, _ := newTestManager(t, day(13, 10, 0))
+	first := m.addTestRelease(t, 15, 15)
+	m.addTestRelease(t, 19, 20)
+
+	_, errTxt := m.changeReleaseDates(first, day(15, 0, 0), day(19, 0, 0), testOwner, testOwnerId)
+	if errTxt == "" {
+		t.Fatalf("expected overlap with the second release")
+	}
+
+	_, errTxt = m.changeReleaseDates(first, day(14, 0, 0), day(16, 0, 0), testOwner, testOwnerId)
+	if errTxt != "" {
+		t.Fatalf("expected edit overlapping only itself to succeed: %s", errTxt)
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
 )
 
 var (
@@ -10,16 +11,13 @@ var (
 	ShowTakenOption = ShowOptions[1]
 )
 
-const (
-	ResetHour = 17
-	ResetMin  = 0
-)
-
 type ParkingData struct {
 	*model.Data
 	SelectedFloor     map[string]string
 	SelectedShowTaken map[string]bool
-	DefaultFloor      string
+	// SelectedAttributes Attributes that shown spaces must have
+	SelectedAttributes map[string][]spaces.Attribute
+	DefaultFloor       string
 }
 
 func NewParkingData(data *model.Data) *ParkingData {
@@ -29,10 +27,11 @@ func NewParkingData(data *model.Data) *ParkingData {
 		defaultFloor = allFloors[0]
 	}
 	return &ParkingData{
-		Data:              data,
-		SelectedFloor:     map[string]string{},
-		SelectedShowTaken: map[string]bool{},
-		DefaultFloor:      defaultFloor,
+		Data:               data,
+		SelectedFloor:      map[string]string{},
+		SelectedShowTaken:  map[string]bool{},
+		SelectedAttributes: map[string][]spaces.Attribute{},
+		DefaultFloor:       defaultFloor,
 	}
 }
 
@@ -0,0 +1,27 @@
+package parking_spaces
+
+import (
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+)
+
+// checkReservationAllowed Returns an error if booking rights of the user are
+// limited or a new reservation of the space would exceed the user's quota
+func (m *Manager) checkReservationAllowed(userId string, spaceKey spaces.SpaceKey) error {
+	now := m.data.Clock.Now()
+	err := m.data.UserManager.CheckBookingAllowed(userId, now)
+	if err != nil {
+		return err
+	}
+
+	space := m.data.ParkingLot.GetSpace(spaceKey)
+	if space == nil {
+		return nil
+	}
+	resetTime := m.data.ParkingLot.ResetTime(spaces.MakeFloorStr(space.Floor))
+	return m.data.ParkingLot.CheckQuota(
+		userId,
+		m.data.UserManager.GetQuota(userId),
+		m.data.Calendar.BookingDate(now, resetTime),
+		m.data.Calendar,
+	)
+}
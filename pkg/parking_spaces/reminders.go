@@ -0,0 +1,132 @@
+package parking_spaces
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+)
+
+// handleReleaseReminders Reminds owners about their releases that start or
+// end on the next working day
+func (m *Manager) handleReleaseReminders(data *event.TimerDone) {
+	// NOTE: releases only start & end at the reset which is skipped on
+	// non-working days
+	if !m.data.Calendar.IsWorkingDay(data.Scheduled) {
+		slog.Info("Skip RemindReleases: not a working day", "scheduled", data.Scheduled)
+		return
+	}
+
+	var actions []event.ResponseAction
+	for _, reminder := range m.data.ParkingLot.ReleaseReminders(data.Scheduled, m.data.Calendar) {
+		txt, blocks := m.extendView.GenerateReminderBlocks(reminder)
+		actions = append(
+			actions,
+			common.NewPostBlocksAction(reminder.Release.OwnerId, txt, blocks...),
+		)
+	}
+	slog.Info("RemindReleases", "reminders", len(actions))
+	if len(actions) == 0 {
+		return
+	}
+
+	m.eventManager.Publish(common.NewResponseEvent("Parking RemindReleases Timer", actions...))
+}
+
+func (m *Manager) isOwnRelease(release spaces.ReleaseInfo, userId string) bool {
+	return release.InUse && release.Submitted && !release.Cancelled && release.OwnerId == userId
+}
+
+// ownRelease Returns release of the user referred to by a reminder message
+// or an extend modal
+func (m *Manager) ownRelease(
+	userId string,
+	actionValues views.ActionValues,
+) (spaces.ReleaseInfo, error) {
+	release, err := m.data.ParkingLot.ToBeReleased.Get(actionValues.SpaceKey, actionValues.ReleaseId)
+	if err != nil || !m.isOwnRelease(release, userId) {
+		return spaces.EmptyRelease, fmt.Errorf(
+			"the release of space %s no longer exists",
+			actionValues.SpaceKey,
+		)
+	}
+	return release, nil
+}
+
+func (m *Manager) handleExtendRelease(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+) []event.ResponseAction {
+	release, err := m.ownRelease(data.UserId, actionValues)
+	if err != nil {
+		txt := fmt.Sprintf(":warning: %v", err)
+		return []event.ResponseAction{
+			common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, txt),
+		}
+	}
+
+	extendModal := m.extendView.Generate(release)
+	return []event.ResponseAction{common.NewOpenViewAction(data.TriggerId, extendModal)}
+}
+
+func (m *Manager) handleExtendSubmission(data *slackApi.ViewSubmission) *common.Response {
+	release, errTxt := m.extendRelease(data)
+	if errTxt != "" {
+		slog.Error("Failed to extend release", "user", data.UserName, "err", errTxt)
+		errTxt = fmt.Sprintf("Release was not extended: %s", errTxt)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+
+	txt := fmt.Sprintf(
+		":white_check_mark: The release of space *%s* is extended (%s).",
+		release.SpaceKey,
+		release.DateRange(),
+	)
+	return common.NewResponseEvent(
+		data.UserName,
+		common.NewPostAction(data.UserId, txt, false),
+	)
+}
+
+// extendRelease Validates extend modal & moves the end date of the release
+func (m *Manager) extendRelease(data *slackApi.ViewSubmission) (*spaces.ReleaseInfo, string) {
+	actionValues := views.ActionValues{}.Decode(data.PrivateMetadata)
+	release, err := m.ownRelease(data.UserId, actionValues)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	endDate, err := parseDate(
+		data.IValueString(views.ExtendEndDateBlockId, views.ExtendEndDateActionId),
+		m.data.Clock.Now().Location(),
+	)
+	if err != nil {
+		return nil, err.Error()
+	}
+	if !endDate.After(common.DateOf(*release.EndDate)) {
+		return nil, fmt.Sprintf(
+			"the release already lasts until %s",
+			release.EndDate.Format("2006-01-02"),
+		)
+	}
+
+	extended, errTxt := m.changeReleaseDates(
+		release,
+		*release.StartDate,
+		endDate,
+		data.UserName,
+		data.UserId,
+	)
+	if errTxt != "" {
+		return nil, errTxt
+	}
+	slog.Info("Release extended", "user", data.UserName, "release", extended)
+	return extended, ""
+}
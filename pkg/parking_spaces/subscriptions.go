@@ -0,0 +1,137 @@
+package parking_spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+)
+
+// FreeSpaceNotifications Notifies subscribers about parking spaces that
+// became free since the last call. It is exported so that managers which
+// release spaces on their own (HCM & BSS syncs) can notify subscribers too.
+func FreeSpaceNotifications(data *model.Data, throttle time.Duration) []event.ResponseAction {
+	notifications := data.ParkingLot.FreeSpaceNotifications(
+		data.Clock.Now(),
+		throttle,
+		data.Calendar,
+		data.UserManager.GetGroups,
+	)
+
+	var actions []event.ResponseAction
+	for _, notification := range notifications {
+		txt, blocks := views.GenerateFreeSpaceBlocks(Identifier, notification)
+		actions = append(actions, common.NewPostBlocksAction(notification.UserId, txt, blocks...))
+	}
+	return actions
+}
+
+func (m *Manager) handleSubscribe(data *slackApi.BlockAction) []event.ResponseAction {
+	subscriptionModal := m.subscriptionView.Generate(data.UserId, "")
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, subscriptionModal)}
+}
+
+func (m *Manager) handleRemoveSubscription(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+) []event.ResponseAction {
+	errorTxt := ""
+	subscription, err := m.data.ParkingLot.RemoveSubscription(
+		data.UserId,
+		actionValues.SubscriptionId,
+	)
+	if err != nil {
+		errorTxt = fmt.Sprintf("Failed to remove subscription: %v", err)
+	} else {
+		slog.Info("Removed subscription", "user", data.UserName, "subscription", subscription)
+	}
+
+	subscriptionModal := m.subscriptionView.Generate(data.UserId, errorTxt)
+	return []event.ResponseAction{
+		common.NewUpdateViewAction(data.TriggerId, data.ViewId, subscriptionModal, errorTxt),
+	}
+}
+
+func (m *Manager) handleSubscriptionSubmission(data *slackApi.ViewSubmission) *common.Response {
+	subscription, errTxt := m.subscribe(data)
+	if errTxt != "" {
+		slog.Error("Failed subscription", "user", data.UserName, "err", errTxt)
+		errTxt = fmt.Sprintf("Subscription failed: %s", errTxt)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+
+	txt := fmt.Sprintf(
+		":bell: You will be notified when a parking space matching %s becomes free.",
+		subscription,
+	)
+	return common.NewResponseEvent(
+		data.UserName,
+		common.NewPostAction(data.UserId, txt, false),
+	)
+}
+
+// subscribe Validates subscription modal & stores the subscription
+func (m *Manager) subscribe(data *slackApi.ViewSubmission) (*spaces.Subscription, string) {
+	subscription := spaces.Subscription{
+		UserId:   data.UserId,
+		UserName: data.UserName,
+	}
+
+	floorStr := data.IValueString(views.SubscriptionFloorBlockId, views.SubscriptionFloorActionId)
+	if floorStr != "" {
+		floor, err := strconv.Atoi(floorStr)
+		if err != nil {
+			return nil, fmt.Sprintf("invalid floor %q", floorStr)
+		}
+		subscription.Floor = spaces.MakeFloorStr(floor)
+
+		numberStr := strings.TrimSpace(
+			data.IValueString(views.SubscriptionNumberBlockId, views.SubscriptionNumberActionId),
+		)
+		if numberStr != "" {
+			number, err := strconv.Atoi(numberStr)
+			if err != nil {
+				return nil, fmt.Sprintf("invalid space number %q", numberStr)
+			}
+			subscription.SpaceKey = spaces.MakeSpaceKey(number, floor)
+		}
+	} else if data.IValueString(views.SubscriptionNumberBlockId, views.SubscriptionNumberActionId) != "" {
+		return nil, "select the floor of the space"
+	}
+
+	attrs, err := spaces.ParseAttributes(
+		data.IValue(views.SubscriptionAttrBlockId, views.SubscriptionAttrActionId),
+	)
+	if err != nil {
+		return nil, err.Error()
+	}
+	subscription.Attributes = attrs
+
+	stored, err := m.data.ParkingLot.AddSubscription(subscription)
+	if err != nil {
+		return nil, err.Error()
+	}
+	slog.Info("Subscribed to free spaces", "user", data.UserName, "subscription", stored)
+	return stored, ""
+}
+
+// publishFreeSpaceNotifications Notifies subscribers about spaces that were
+// freed while handling an event
+func (m *Manager) publishFreeSpaceNotifications(user string) {
+	actions := FreeSpaceNotifications(m.data.Data, m.notificationThrottle)
+	if len(actions) == 0 {
+		return
+	}
+	m.eventManager.Publish(common.NewResponseEvent(user, actions...))
+}
@@ -0,0 +1,232 @@
+package parking_spaces
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+)
+
+func (m *Manager) handleSwap(data *slackApi.BlockAction) []event.ResponseAction {
+	swapModal := m.swapView.Generate(data.UserId)
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, swapModal)}
+}
+
+func (m *Manager) handleSwapSubmission(data *slackApi.ViewSubmission) *common.Response {
+	request, errTxt := m.requestSwap(data)
+	if errTxt != "" {
+		slog.Error("Failed swap request", "user", data.UserName, "err", errTxt)
+		errTxt = fmt.Sprintf("Swap request was not sent: %s", errTxt)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+
+	space := m.data.ParkingLot.HandOverSpace(data.UserId)
+	requestTxt := fmt.Sprintf(
+		":handshake: <@%s> would like to hand over their parking space *%s* to you%s.",
+		request.FromId,
+		space.Key(),
+		swapDatesText(request),
+	)
+	if received := m.data.ParkingLot.HandOverSpace(request.ToId); received != nil {
+		requestTxt += fmt.Sprintf(" In exchange they get your space *%s*.", received.Key())
+	}
+
+	confirmTxt := fmt.Sprintf(
+		"Swap request for *%s*%s was sent to <@%s>. You will be notified once they answer.",
+		space.Key(),
+		swapDatesText(request),
+		request.ToId,
+	)
+	return common.NewResponseEvent(
+		data.UserName,
+		common.NewPostBlocksAction(
+			request.ToId,
+			requestTxt,
+			m.swapView.GenerateRequestBlocks(request, requestTxt)...,
+		),
+		common.NewPostAction(data.UserId, confirmTxt, false),
+	)
+}
+
+// requestSwap Validates swap modal & stores the swap request
+func (m *Manager) requestSwap(data *slackApi.ViewSubmission) (*spaces.SwapRequest, string) {
+	targetId := data.IValueString(views.SwapUserBlockId, views.SwapUserActionId)
+	if targetId == "" {
+		return nil, "no colleague selected"
+	}
+	if targetId == data.UserId {
+		return nil, "you can't hand over a space to yourself"
+	}
+
+	if m.data.ParkingLot.HandOverSpace(data.UserId) == nil {
+		return nil, "you don't have a parking space that could be handed over"
+	}
+
+	request := spaces.SwapRequest{
+		FromId:   data.UserId,
+		FromName: data.UserName,
+		ToId:     targetId,
+		// NOTE: real name of the target is known once they answer
+		ToName: m.data.UserManager.GetNameFromId(targetId),
+	}
+
+	if m.data.ParkingLot.OwnsSpace(data.UserId) != nil {
+		now := m.data.Clock.Now()
+		startDate, err := parseDate(
+			data.IValueString(views.SwapStartDateBlockId, views.SwapStartDateActionId),
+			now.Location(),
+		)
+		if err != nil {
+			return nil, err.Error()
+		}
+		endDate, err := parseDate(
+			data.IValueString(views.SwapEndDateBlockId, views.SwapEndDateActionId),
+			now.Location(),
+		)
+		if err != nil {
+			return nil, err.Error()
+		}
+
+		errTxt := common.CheckDateRange(startDate, endDate, now)
+		if errTxt != "" {
+			return nil, errTxt
+		}
+		request.StartDate = &startDate
+		request.EndDate = &endDate
+	}
+
+	stored := m.data.ParkingLot.AddSwapRequest(request)
+	slog.Info("Swap requested", "request", stored)
+	return stored, ""
+}
+
+// handleSwapAnswer Applies or discards swap request once the target pressed
+// Accept/Decline in the request message
+func (m *Manager) handleSwapAnswer(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+	accepted bool,
+) []event.ResponseAction {
+	request, err := m.data.ParkingLot.TakeSwapRequest(data.UserId, actionValues.SwapId)
+	if err != nil {
+		txt := fmt.Sprintf(":warning: %v", err)
+		return []event.ResponseAction{
+			common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, txt),
+		}
+	}
+	request.ToName = data.UserName
+
+	if !accepted {
+		slog.Info("Swap declined", "request", request)
+		return []event.ResponseAction{
+			common.NewUpdateMessageAction(
+				data.ChannelId,
+				data.MessageTs,
+				fmt.Sprintf("You declined the swap request of <@%s>.", request.FromId),
+			),
+			common.NewPostAction(
+				request.FromId,
+				fmt.Sprintf(":x: <@%s> declined your swap request.", request.ToId),
+				false,
+			),
+		}
+	}
+
+	now := m.data.Clock.Now()
+	bookingDate := now
+	if space := m.data.ParkingLot.HandOverSpace(request.FromId); space != nil {
+		resetTime := m.data.ParkingLot.SpaceResetTime(space.Key())
+		bookingDate = m.data.Calendar.BookingDate(now, resetTime)
+	}
+
+	result, err := m.data.ParkingLot.ApplySwap(request, bookingDate, now)
+	if err != nil {
+		slog.Error("Swap failed", "request", request, "err", err)
+		txt := fmt.Sprintf(":warning: Swap of <@%s> & <@%s> failed: %v", request.FromId, request.ToId, err)
+		return []event.ResponseAction{
+			common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, txt),
+			common.NewPostAction(request.FromId, txt, false),
+		}
+	}
+	slog.Info("Swap accepted", "request", request)
+
+	dates := swapDatesText(request)
+	toTxt := fmt.Sprintf(
+		":white_check_mark: You accepted the swap request of <@%s>. You got space *%s*%s.",
+		request.FromId,
+		result.Given.Key(),
+		dates,
+	)
+	fromTxt := fmt.Sprintf(
+		":white_check_mark: <@%s> accepted your swap request. Your space *%s* is handed over to them%s.",
+		request.ToId,
+		result.Given.Key(),
+		dates,
+	)
+	if result.Received != nil {
+		toTxt += fmt.Sprintf(" Your space *%s* is handed over to them.", result.Received.Key())
+		fromTxt += fmt.Sprintf(" You got space *%s*.", result.Received.Key())
+	}
+
+	return []event.ResponseAction{
+		common.NewUpdateMessageAction(data.ChannelId, data.MessageTs, toTxt),
+		common.NewPostAction(request.FromId, fromTxt, false),
+	}
+}
+
+// cancelPairedRelease Cancels release of the other space of a swap of
+// permanent spaces & informs the other owner
+func (m *Manager) cancelPairedRelease(
+	releaseInfo spaces.ReleaseInfo,
+	userId string,
+) []event.ResponseAction {
+	paired, err := m.data.ParkingLot.ToBeReleased.GetPaired(releaseInfo)
+	if err != nil {
+		return nil
+	}
+
+	pairedSpace := m.data.ParkingLot.GetSpace(paired.SpaceKey)
+	if pairedSpace == nil {
+		return nil
+	}
+
+	slog.Info("Cancel paired swap release", "releaseInfo", paired)
+	m.cancelTempRelease(pairedSpace, paired)
+
+	txt := fmt.Sprintf(
+		":warning: <@%s> cancelled the swap of spaces *%s* & *%s* (%s). "+
+			"Your space is returned to you.",
+		userId,
+		releaseInfo.SpaceKey,
+		paired.SpaceKey,
+		paired.DateRange(),
+	)
+	return []event.ResponseAction{common.NewPostAction(paired.OwnerId, txt, false)}
+}
+
+func swapDatesText(request *spaces.SwapRequest) string {
+	if !request.IsPermanent() {
+		return ""
+	}
+	return fmt.Sprintf(
+		" from *%s* to *%s*",
+		request.StartDate.Format("2006-01-02"),
+		request.EndDate.Format("2006-01-02"),
+	)
+}
+
+func parseDate(value string, location *time.Location) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
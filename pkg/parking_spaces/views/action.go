@@ -8,9 +8,16 @@ import (
 )
 
 type ActionValues struct {
-	SpaceKey  spaces.SpaceKey `json:"space,omitempty"`
-	ModalType ModalType       `json:"modalType,omitempty"`
-	ReleaseId int             `json:"releaseId,omitempty"`
+	SpaceKey       spaces.SpaceKey `json:"space,omitempty"`
+	ModalType      ModalType       `json:"modalType,omitempty"`
+	ReleaseId      int             `json:"releaseId,omitempty"`
+	GuestId        int             `json:"guestId,omitempty"`
+	SwapId         int             `json:"swapId,omitempty"`
+	SubscriptionId int             `json:"subscriptionId,omitempty"`
+	// BookingId Workspace booking of an upcoming date
+	BookingId int `json:"bookingId,omitempty"`
+	// RootViewId View to refresh after a pushed view is submitted
+	RootViewId string `json:"rootViewId,omitempty"`
 }
 
 func (av ActionValues) Encode() string {
@@ -5,10 +5,12 @@ import (
 	"log"
 	"math"
 	"slices"
-	"time"
+	"strings"
 
 	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/floor_map"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/model"
 	"github.com/slack-go/slack"
 )
@@ -23,7 +25,12 @@ const (
 	PagingOptionId             = "pagingOptionId"
 	ShowActionId               = "showActionId"
 	ShowOptionId               = "showOptionId"
+	AttributesActionId         = "attributesActionId"
+	AttributesOptionId         = "attributesOptionId"
 	SwitchToPersonalViewId     = "switchToPersonalView"
+	// OpenBookingActionId Button in channel messages (i.e. digest) which
+	// opens the parking modal
+	OpenBookingActionId = "openBooking"
 )
 
 const (
@@ -35,13 +42,21 @@ type Booking struct {
 	Title string
 	data  *model.ParkingData
 	Type  ModalType
+	// floorMaps URLs of rendered floor maps (zero value means floor maps
+	// are not shown)
+	floorMaps floor_map.Links
 }
 
-func NewBooking(identifier string, managerData *model.ParkingData) *Booking {
+func NewBooking(
+	identifier string,
+	managerData *model.ParkingData,
+	floorMaps floor_map.Links,
+) *Booking {
 	return &Booking{
-		Title: identifier + "Booking",
-		data:  managerData,
-		Type:  BookingModal,
+		Title:     identifier + "Booking",
+		data:      managerData,
+		Type:      BookingModal,
+		floorMaps: floorMaps,
 	}
 }
 
@@ -84,14 +99,20 @@ func (b *Booking) generateParkingInfo(spaces spaces.SpacesInfo) []slack.Block {
 			)
 		}
 
+		restriction := ""
+		if !space.Reserved && space.IsRestricted() {
+			restriction = "\n\t\t" + b.data.ParkingLot.GetRestrictionText(space)
+		}
+
 		spaceProps := space.GetPropsText()
 		text := fmt.Sprintf(
-			"%s *%s* \t%s\t %s%s",
+			"%s *%s* \t%s\t %s%s%s",
 			emoji,
 			fmt.Sprint(space.Number),
 			spaceProps,
 			status,
 			releaseScheduled,
+			restriction,
 		)
 
 		sectionText := slack.NewTextBlockObject("mrkdwn", text, false, false)
@@ -109,10 +130,15 @@ func (b *Booking) generateParkingButtons(
 ) []slack.BlockElement {
 	var buttons []slack.BlockElement
 
-	isAdminUser := b.data.UserManager.IsAdminId(userId)
+	isAdminUser := b.data.UserManager.HasFloorPermission(
+		userId,
+		user.PermManageParking,
+		space.Floor,
+	)
 	hasPermanentParkingUser := b.data.UserManager.HasParkingById(userId)
+	isGuestHost := space.Guest != nil && space.Guest.HostId == userId
 
-	if space.Reserved && (space.ReservedById == userId || isAdminUser) {
+	if space.Reserved && (space.ReservedById == userId || isAdminUser || isGuestHost) {
 		// space reserved but hasn't yet been schedule for release
 		if isAdminUser {
 			permanentSpace := b.data.UserManager.HasParkingById(space.ReservedById)
@@ -135,7 +161,7 @@ func (b *Booking) generateParkingButtons(
 			}
 		}
 
-		if isAdminUser || !hasPermanentParkingUser {
+		if isAdminUser || isGuestHost || !hasPermanentParkingUser {
 			releaseButton := slack.NewButtonBlockElement(
 				ReleaseParkingActionId,
 				ActionValues{
@@ -149,8 +175,10 @@ func (b *Booking) generateParkingButtons(
 		}
 	} else if !space.Reserved &&
 		!b.data.ParkingLot.HasSpace(userId) &&
-		b.data.ParkingLot.HasTempRelease(userId) == nil {
+		b.data.ParkingLot.HasTempRelease(userId) == nil &&
+		b.data.ParkingLot.CanReserve(space, b.data.UserManager.GetGroups(userId)) {
 		// Only allow user to reserve space if he hasn't already reserved one
+		// & is allowed to reserve it
 		actionButtonText := "Reserve!"
 		reserveWithAutoButton := slack.NewButtonBlockElement(
 			ReserveParkingActionId,
@@ -166,7 +194,31 @@ func (b *Booking) generateParkingButtons(
 	return buttons
 }
 
-func (b *Booking) generateParkingPlanBlocks() []slack.Block {
+// generateFloorMapBlocks Rendered map of the selected floor with the legend.
+// Nothing is shown if floor maps are disabled or the floor has no map.
+func (b *Booking) generateFloorMapBlocks(userId, selectedFloor string) []slack.Block {
+	mapUrl := b.floorMaps.ParkingMap(b.data.ParkingLot, selectedFloor, userId)
+	if mapUrl == "" {
+		return nil
+	}
+
+	legend := fmt.Sprintf(
+		"%s Free   %s Taken   :large_blue_circle: Yours",
+		spaces.StatusEmoji(false),
+		spaces.StatusEmoji(true),
+	)
+	return []slack.Block{
+		slack.NewImageBlock(
+			mapUrl,
+			fmt.Sprintf("Map of %s parking spaces", selectedFloor),
+			"",
+			slack.NewTextBlockObject(slack.PlainTextType, selectedFloor+" map", false, false),
+		),
+		slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", legend, false, false)),
+	}
+}
+
+func (b *Booking) generateParkingPlanBlocks(userId, selectedFloor string) []slack.Block {
 	var allBlocks []slack.Block
 
 	description := slack.NewSectionBlock(
@@ -203,11 +255,12 @@ func (b *Booking) generateParkingPlanBlocks() []slack.Block {
 		))
 	}
 
-	now := time.Now()
+	allBlocks = append(allBlocks, b.generateFloorMapBlocks(userId, selectedFloor)...)
 
-	if now.Hour() >= model.ResetHour && now.Minute() > model.ResetMin {
-		now = now.Add(24 * time.Hour)
-	}
+	now := b.data.Calendar.BookingDate(
+		b.data.Clock.Now(),
+		b.data.ParkingLot.ResetTime(selectedFloor),
+	)
 
 	selectionEffectTime := slack.NewSectionBlock(
 		slack.NewTextBlockObject(
@@ -238,7 +291,7 @@ func (b *Booking) generateParkingInfoBlocks(
 ) []slack.Block {
 	allBlocks := []slack.Block{}
 
-	descriptionBlocks := b.generateParkingPlanBlocks()
+	descriptionBlocks := b.generateParkingPlanBlocks(userId, selectedFloor)
 	allBlocks = append(allBlocks, descriptionBlocks...)
 
 	floorOptionBlocks := b.generateFloorOptions(userId)
@@ -247,6 +300,24 @@ func (b *Booking) generateParkingInfoBlocks(
 	showOptionBlocks := b.generateFreeTakenOptions(userId)
 	allBlocks = append(allBlocks, showOptionBlocks...)
 
+	allBlocks = append(allBlocks, GenerateAttributesFilter(
+		spaces.ParkingAttributes,
+		b.data.SelectedAttributes[userId],
+	))
+	allBlocks = append(allBlocks, generateGuestButton(b.Type))
+	if b.data.ParkingLot.HandOverSpace(userId) != nil {
+		allBlocks = append(allBlocks, generateSwapButton(b.Type))
+	} else {
+		allBlocks = append(allBlocks, generateSubscribeButton(b.Type))
+	}
+
+	if quotaTxt := b.generateQuotaText(userId); quotaTxt != "" {
+		allBlocks = append(allBlocks, slack.NewContextBlock(
+			"",
+			slack.NewTextBlockObject("mrkdwn", quotaTxt, false, false),
+		))
+	}
+
 	if errorTxt != "" {
 		txt := fmt.Sprintf(`:warning: %s`, errorTxt)
 		errorSection := slack.NewSectionBlock(
@@ -269,7 +340,9 @@ func (b *Booking) generateParkingInfoBlocks(
 	if selectedShowTaken {
 		selectedSpaceType = spaces.SpaceTaken
 	}
-	spaces := b.data.ParkingLot.GetSpacesByFloor(userId, selectedFloor, selectedSpaceType)
+	spaces := b.data.ParkingLot.
+		GetSpacesByFloor(userId, selectedFloor, selectedSpaceType).
+		WithAttributes(userId, b.data.SelectedAttributes[userId])
 
 	if selectedPage < 1 {
 		log.Fatalf("unexpected page %d, page range [1, X]", selectedPage)
@@ -416,6 +489,41 @@ func (b *Booking) generateFreeTakenOptions(userId string) []slack.Block {
 	return allBlocks
 }
 
+// GenerateAttributesFilter Generates selector of attributes that shown
+// spaces must have
+func GenerateAttributesFilter(
+	attributes []spaces.Attribute,
+	selected []spaces.Attribute,
+) *slack.ActionBlock {
+	var optionBlocks []*slack.OptionBlockObject
+	var initialOptions []*slack.OptionBlockObject
+	for _, attr := range attributes {
+		optionBlock := slack.NewOptionBlockObject(
+			string(attr),
+			slack.NewTextBlockObject("plain_text", spaces.AttributeLabel(attr), true, false),
+			nil,
+		)
+		optionBlocks = append(optionBlocks, optionBlock)
+
+		if slices.Contains(selected, attr) {
+			initialOptions = append(initialOptions, optionBlock)
+		}
+	}
+
+	placeholder := slack.NewTextBlockObject("plain_text", "Filter by attributes", false, false)
+	multiSelect := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeStatic,
+		placeholder,
+		AttributesOptionId,
+		optionBlocks...,
+	)
+	if len(initialOptions) > 0 {
+		multiSelect.InitialOptions = initialOptions
+	}
+
+	return slack.NewActionBlock(AttributesActionId, multiSelect)
+}
+
 func generateSwitchPersonalButton(modalType ModalType) *slack.ActionBlock {
 	switchPersonalBtn := slack.NewButtonBlockElement(
 		SwitchToPersonalViewId,
@@ -469,3 +577,28 @@ func generatePagingButtons(numSpaces int) []slack.Block {
 
 	return allBlocks
 }
+
+// generateQuotaText Remaining reservations of the user or empty if the user
+// has a permanent space or the quota is unlimited
+func (b *Booking) generateQuotaText(userId string) string {
+	quota := b.data.UserManager.GetQuota(userId)
+	if b.data.UserManager.HasParkingById(userId) ||
+		(quota.PerWeek == 0 && quota.PerMonth == 0 && quota.ConsecutiveDays == 0) {
+		return ""
+	}
+
+	date := b.data.Calendar.BookingDate(b.data.Clock.Now(), b.data.ParkingLot.ResetTimes.Default)
+	usage := b.data.ParkingLot.QuotaUsage(userId, date)
+
+	var parts []string
+	if quota.PerWeek > 0 {
+		parts = append(parts, fmt.Sprintf("*%d/%d* left this week", max(quota.PerWeek-usage.Week, 0), quota.PerWeek))
+	}
+	if quota.PerMonth > 0 {
+		parts = append(parts, fmt.Sprintf("*%d/%d* left this month", max(quota.PerMonth-usage.Month, 0), quota.PerMonth))
+	}
+	if quota.ConsecutiveDays > 0 {
+		parts = append(parts, fmt.Sprintf("max *%d* consecutive days", quota.ConsecutiveDays))
+	}
+	return ":ticket: Your reservations: " + strings.Join(parts, " · ")
+}
@@ -0,0 +1,126 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/slack-go/slack"
+)
+
+const (
+	ExtendReleaseActionId   = "extendRelease"
+	EndReleaseEarlyActionId = "endReleaseEarly"
+	ExtendEndDateBlockId    = "extendEndDateBlockId"
+	ExtendEndDateActionId   = "extendEndDateActionId"
+)
+
+type Extend struct {
+	Title string
+	// reminderBlockId Block ID of buttons in release reminder messages.
+	// Messages are routed by block ID so it starts with the identifier of
+	// the manager.
+	reminderBlockId string
+}
+
+func NewExtend(identifier string) *Extend {
+	return &Extend{
+		Title:           identifier + "Extend release",
+		reminderBlockId: identifier + "releaseReminder",
+	}
+}
+
+// Generate NOTE: this is opened from a release reminder message so the
+// release is stored in the private metadata of the view
+func (e *Extend) Generate(release spaces.ReleaseInfo) slack.ModalViewRequest {
+	description := fmt.Sprintf(
+		"Your space *%s* is released from %s. Select the new last day of the release.",
+		release.SpaceKey,
+		releaseDates(release),
+	)
+
+	endDate := slack.NewDatePickerBlockElement(ExtendEndDateActionId)
+	endDate.InitialDate = release.EndDate.Format("2006-01-02")
+
+	allBlocks := []slack.Block{
+		createTextBlock(description),
+		slack.NewInputBlock(
+			ExtendEndDateBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Released until (including)", false, false),
+			nil,
+			endDate,
+		),
+	}
+
+	modal := common.GenerateModalRequest(e.Title, allBlocks)
+	modal.PrivateMetadata = ActionValues{
+		SpaceKey:  release.SpaceKey,
+		ReleaseId: release.UniqueId,
+	}.Encode()
+	return modal
+}
+
+// GenerateReminderBlocks Message reminding the owner about a release that
+// starts or ends on the next working day
+func (e *Extend) GenerateReminderBlocks(reminder spaces.ReleaseReminder) (string, []slack.Block) {
+	release := reminder.Release
+	date := reminder.Date.Format("Mon 2006-01-02")
+
+	var txt string
+	if reminder.Starting {
+		txt = fmt.Sprintf(
+			":calendar: Reminder: your space *%s* is released from *%s* (%s). "+
+				"Others can book it once the spaces are reset today.",
+			release.SpaceKey,
+			date,
+			releaseDates(release),
+		)
+	} else {
+		txt = fmt.Sprintf(
+			":calendar: Reminder: the release of your space *%s* (%s) ends "+
+				"after *%s*. You get the space back afterwards.",
+			release.SpaceKey,
+			releaseDates(release),
+			date,
+		)
+	}
+
+	values := ActionValues{
+		SpaceKey:  release.SpaceKey,
+		ReleaseId: release.UniqueId,
+	}.Encode()
+	extendBtn := slack.NewButtonBlockElement(
+		ExtendReleaseActionId,
+		values,
+		slack.NewTextBlockObject("plain_text", "Extend", true, false),
+	).WithStyle(slack.StylePrimary)
+
+	// NOTE: not active release can only be cancelled & active release can
+	// only be ended early (both are handled as cancellation)
+	stopBtn := slack.NewButtonBlockElement(
+		CancelTempReleaseParkingActionId,
+		values,
+		slack.NewTextBlockObject("plain_text", "Cancel", true, false),
+	)
+	if release.Active {
+		stopBtn = slack.NewButtonBlockElement(
+			EndReleaseEarlyActionId,
+			values,
+			slack.NewTextBlockObject("plain_text", "End early", true, false),
+		)
+	}
+	stopBtn = stopBtn.WithStyle(slack.StyleDanger)
+
+	return txt, []slack.Block{
+		createTextBlock(txt),
+		slack.NewActionBlock(e.reminderBlockId, extendBtn, stopBtn),
+	}
+}
+
+func releaseDates(release spaces.ReleaseInfo) string {
+	return fmt.Sprintf(
+		"*%s* -> *%s*",
+		release.StartDate.Format("Mon 2006-01-02"),
+		release.EndDate.Format("Mon 2006-01-02"),
+	)
+}
@@ -0,0 +1,183 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	parkingModel "github.com/AngelVI13/slack-bot/pkg/parking_spaces/model"
+	"github.com/slack-go/slack"
+)
+
+const (
+	GuestBookingActionId       = "guestBooking"
+	CancelGuestBookingActionId = "cancelGuestBooking"
+	GuestNameBlockId           = "guestNameBlockId"
+	GuestNameActionId          = "guestNameActionId"
+	GuestPlateBlockId          = "guestPlateBlockId"
+	GuestPlateActionId         = "guestPlateActionId"
+	GuestDateBlockId           = "guestDateBlockId"
+	GuestDateActionId          = "guestDateActionId"
+	GuestFloorBlockId          = "guestFloorBlockId"
+	GuestFloorActionId         = "guestFloorActionId"
+)
+
+type Guests struct {
+	Title string
+	data  *parkingModel.ParkingData
+	// limit Max guest bookings per host per week
+	limit int
+}
+
+func NewGuests(
+	identifier string,
+	managerData *parkingModel.ParkingData,
+	limit int,
+) *Guests {
+	return &Guests{
+		Title: identifier + "Guest",
+		data:  managerData,
+		limit: limit,
+	}
+}
+
+// Generate NOTE: this is pushed on top of booking or personal modal
+func (g *Guests) Generate(userId string, errorTxt string) slack.ModalViewRequest {
+	allBlocks := g.generateGuestBlocks(userId, errorTxt)
+	return common.GenerateModalRequest(g.Title, allBlocks)
+}
+
+func (g *Guests) generateGuestBlocks(userId, errorTxt string) []slack.Block {
+	description := fmt.Sprintf(
+		"Book a parking space for your guest. The space is assigned on the "+
+			"reset before the selected date & you will receive a message with "+
+			"the space and floor plan to forward to your guest.\n"+
+			"_You can make up to %d guest bookings per week._",
+		g.limit,
+	)
+	allBlocks := []slack.Block{createTextBlock(description)}
+
+	if errorTxt != "" {
+		allBlocks = append(allBlocks, createErrorTextBlock(errorTxt))
+	}
+
+	nameInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "John Smith", false, false),
+		GuestNameActionId,
+	)
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		GuestNameBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Guest name", false, false),
+		nil,
+		nameInput,
+	))
+
+	plateInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "ABC123", false, false),
+		GuestPlateActionId,
+	)
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		GuestPlateBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Car plate", false, false),
+		nil,
+		plateInput,
+	))
+
+	selectedFloor := g.data.DefaultFloor
+	if selected, ok := g.data.SelectedFloor[userId]; ok {
+		selectedFloor = selected
+	}
+	bookingDate := g.data.Calendar.BookingDate(
+		g.data.Clock.Now(),
+		g.data.ParkingLot.ResetTime(selectedFloor),
+	)
+
+	dateInput := slack.NewDatePickerBlockElement(GuestDateActionId)
+	dateInput.InitialDate = bookingDate.Format("2006-01-02")
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		GuestDateBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Date", false, false),
+		nil,
+		dateInput,
+	))
+
+	var floorOptions []*slack.OptionBlockObject
+	var initialFloor *slack.OptionBlockObject
+	for _, floor := range g.data.ParkingLot.GetAllFloorNumbers() {
+		floorStr := spaces.MakeFloorStr(floor)
+		option := slack.NewOptionBlockObject(
+			fmt.Sprint(floor),
+			slack.NewTextBlockObject(slack.PlainTextType, floorStr, false, false),
+			nil,
+		)
+		floorOptions = append(floorOptions, option)
+		if floorStr == selectedFloor {
+			initialFloor = option
+		}
+	}
+	floorSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Choose a parking floor", false, false),
+		GuestFloorActionId,
+		floorOptions...,
+	)
+	floorSelect.InitialOption = initialFloor
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		GuestFloorBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Floor", false, false),
+		nil,
+		floorSelect,
+	))
+
+	allBlocks = append(allBlocks, g.generateHostBookingsBlocks(userId)...)
+	return allBlocks
+}
+
+// generateHostBookingsBlocks Lists upcoming guest bookings of the host
+func (g *Guests) generateHostBookingsBlocks(userId string) []slack.Block {
+	yesterday := g.data.Clock.Now().AddDate(0, 0, -1)
+
+	var allBlocks []slack.Block
+	for _, booking := range g.data.ParkingLot.HostGuestBookings(userId) {
+		if !booking.Date.After(yesterday) {
+			continue
+		}
+
+		if len(allBlocks) == 0 {
+			allBlocks = append(allBlocks, slack.NewDividerBlock(), createTextBlock("*Your guests*"))
+		}
+
+		space := "_space not assigned yet_"
+		if booking.SpaceKey != "" {
+			space = fmt.Sprintf("space *%s*", booking.SpaceKey)
+		}
+		txt := fmt.Sprintf(
+			":bust_in_silhouette: *%s* (%s) on %s - %s",
+			booking.Name,
+			booking.Plate,
+			booking.Date.Format("2006-01-02"),
+			space,
+		)
+
+		cancelBtn := slack.NewButtonBlockElement(
+			CancelGuestBookingActionId,
+			ActionValues{GuestId: booking.Id}.Encode(),
+			slack.NewTextBlockObject("plain_text", "Cancel", true, false),
+		).WithStyle(slack.StyleDanger)
+		allBlocks = append(allBlocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", txt, false, false),
+			nil,
+			slack.NewAccessory(cancelBtn),
+		))
+	}
+	return allBlocks
+}
+
+func generateGuestButton(modalType ModalType) *slack.ActionBlock {
+	guestBtn := slack.NewButtonBlockElement(
+		GuestBookingActionId,
+		ActionValues{ModalType: modalType}.Encode(),
+		slack.NewTextBlockObject("plain_text", "Book for a Guest :bust_in_silhouette:", true, false),
+	)
+	return slack.NewActionBlock("", guestBtn)
+}
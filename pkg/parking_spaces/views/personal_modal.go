@@ -7,6 +7,7 @@ import (
 
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	parkingModel "github.com/AngelVI13/slack-bot/pkg/parking_spaces/model"
 	"github.com/slack-go/slack"
 )
@@ -14,6 +15,7 @@ import (
 const (
 	CancelActionValueSeparator       = "__"
 	CancelTempReleaseParkingActionId = "cancelTempReleaseParking"
+	EditTempReleaseParkingActionId   = "editTempReleaseParking"
 	SwitchToAllSpacesOverviewId      = "switchToAllSpacesOverview"
 )
 
@@ -82,6 +84,9 @@ func generateTemporaryReleaseBlock(
 		release.StartDate.Format("2006-01-02"),
 		release.EndDate.Format("2006-01-02"),
 	)
+	if release.ReservedForId != "" {
+		releaseScheduled += fmt.Sprintf(" (handed over to <@%s>)", release.ReservedForId)
+	}
 	sectionText := slack.NewTextBlockObject("mrkdwn", releaseScheduled, false, false)
 	sectionBlock := slack.NewSectionBlock(sectionText, nil, nil)
 	return sectionBlock
@@ -136,22 +141,38 @@ func generateSwitchOverviewButton(modalType ModalType) *slack.ActionBlock {
 	return actionBlock
 }
 
-func generateCancelReleaseButton(
+// generateReleaseButtons Edit & Cancel buttons of a release. Releases of
+// swapped spaces can only be cancelled.
+func generateReleaseButtons(
 	space *spaces.Space,
-	releaseId int,
+	release spaces.ReleaseInfo,
 	modalType ModalType,
 ) *slack.ActionBlock {
+	values := ActionValues{
+		SpaceKey:  space.Key(),
+		ModalType: modalType,
+		ReleaseId: release.UniqueId,
+	}.Encode()
+
+	var buttons []slack.BlockElement
+	if release.IsEditable() {
+		editBtn := slack.NewButtonBlockElement(
+			EditTempReleaseParkingActionId,
+			values,
+			slack.NewTextBlockObject("plain_text", "Edit", true, false),
+		)
+		buttons = append(buttons, editBtn)
+	}
+
 	cancelBtn := slack.NewButtonBlockElement(
 		CancelTempReleaseParkingActionId,
-		ActionValues{
-			SpaceKey:  space.Key(),
-			ModalType: modalType,
-			ReleaseId: releaseId,
-		}.Encode(),
+		values,
 		slack.NewTextBlockObject("plain_text", "Cancel", true, false),
 	)
 	cancelBtn = cancelBtn.WithStyle(slack.StyleDanger)
-	actionBlock := slack.NewActionBlock("", cancelBtn)
+	buttons = append(buttons, cancelBtn)
+
+	actionBlock := slack.NewActionBlock("", buttons...)
 	return actionBlock
 }
 
@@ -176,7 +197,7 @@ func ParseCancelActionValue(actionValue string) (spaces.SpaceKey, int, error) {
 }
 
 const personalModelDescription = `This is your personal parking space page.
-Here you can add/delete/cancel temporary releases of your parking space.
+Here you can add/edit/cancel temporary releases of your parking space.
 `
 
 // generatePersonalInfoBlocks Generates space block objects to be used as elements in modal
@@ -192,13 +213,16 @@ func (p *Personal) generatePersonalInfoBlocks(userId, errorTxt string) []slack.B
 
 	space, noSpaceErr := p.data.ParkingLot.GetOwnedSpaceByUserId(userId)
 
-	// If admin views this page add option to switch to overview.
+	// If admin/floor manager views this page add option to switch to overview.
 	// Additionally if somehow user who doesn't have space views
 	// this page -> add this button so he can go back to overview
-	if p.data.UserManager.IsAdminId(userId) || noSpaceErr != nil {
+	if p.data.UserManager.HasPermission(userId, user.PermManageParking) ||
+		p.data.UserManager.IsFloorManager(userId) ||
+		noSpaceErr != nil {
 		switchOverviewBtn := generateSwitchOverviewButton(p.Type)
 		allBlocks = append(allBlocks, switchOverviewBtn)
 	}
+	allBlocks = append(allBlocks, generateGuestButton(p.Type))
 
 	div := slack.NewDividerBlock()
 	allBlocks = append(allBlocks, div)
@@ -214,8 +238,9 @@ func (p *Personal) generatePersonalInfoBlocks(userId, errorTxt string) []slack.B
 
 	tempReleaseBtn := generateTempReleaseButton(space, p.Type)
 	allBlocks = append(allBlocks, tempReleaseBtn)
+	allBlocks = append(allBlocks, generateSwapButton(p.Type))
 
-	if p.data.UserManager.IsAdminId(userId) {
+	if p.data.UserManager.HasFloorPermission(userId, user.PermManageParking, space.Floor) {
 		allBlocks = append(allBlocks, generateReleaseButton(space, p.Type))
 	}
 
@@ -235,8 +260,8 @@ func (p *Personal) generatePersonalInfoBlocks(userId, errorTxt string) []slack.B
 		allBlocks = append(allBlocks, releaseBlock)
 
 		if !release.Cancelled {
-			cancelBtn := generateCancelReleaseButton(space, release.UniqueId, p.Type)
-			allBlocks = append(allBlocks, cancelBtn)
+			releaseBtns := generateReleaseButtons(space, release, p.Type)
+			allBlocks = append(allBlocks, releaseBtns)
 		}
 	}
 
@@ -33,24 +33,56 @@ func (r *Release) Generate(
 	space *spaces.Space,
 	errorTxt string,
 ) slack.ModalViewRequest {
-	allBlocks := generateReleaseModalBlocks(space, errorTxt)
+	allBlocks := generateReleaseModalBlocks(space, nil, errorTxt)
 	// NOTE: Since this is a modal thats pushed ontop of sth else,
 	// apparently the same title has to be used as the underneath modal.
 	return common.GenerateModalRequest(r.Title, allBlocks)
 }
 
+// GenerateEdit Release modal prefilled with dates of an existing release.
+// The release & the view to refresh after submission are stored in the
+// private metadata of the view.
+func (r *Release) GenerateEdit(
+	space *spaces.Space,
+	release spaces.ReleaseInfo,
+	rootViewId string,
+	modalType ModalType,
+) slack.ModalViewRequest {
+	allBlocks := generateReleaseModalBlocks(space, &release, "")
+	modal := common.GenerateModalRequest(r.Title, allBlocks)
+	modal.PrivateMetadata = ActionValues{
+		SpaceKey:   space.Key(),
+		ModalType:  modalType,
+		ReleaseId:  release.UniqueId,
+		RootViewId: rootViewId,
+	}.Encode()
+	return modal
+}
+
+// generateReleaseModalBlocks NOTE: release is nil unless an existing release
+// is edited
 func generateReleaseModalBlocks(
 	space *spaces.Space,
+	release *spaces.ReleaseInfo,
 	errorTxt string,
 ) []slack.Block {
+	descriptionTxt := fmt.Sprintf(
+		"Temporarily release space: %d (%d floor)",
+		space.Number,
+		space.Floor,
+	)
+	if release != nil {
+		descriptionTxt = fmt.Sprintf(
+			"Edit temporary release of space: %d (%d floor)",
+			space.Number,
+			space.Floor,
+		)
+		if release.Active {
+			descriptionTxt += "\n_The release already started so only the end date can be changed._"
+		}
+	}
 	description := slack.NewSectionBlock(
-		slack.NewTextBlockObject(
-			"mrkdwn",
-			fmt.Sprintf(
-				"Temporarily release space: %d (%d floor)",
-				space.Number,
-				space.Floor,
-			), false, false),
+		slack.NewTextBlockObject("mrkdwn", descriptionTxt, false, false),
 		nil,
 		nil,
 	)
@@ -71,6 +103,11 @@ func generateReleaseModalBlocks(
 		false,
 	)
 
+	if release != nil {
+		startDate.InitialDate = release.StartDate.Format("2006-01-02")
+		endDate.InitialDate = release.EndDate.Format("2006-01-02")
+	}
+
 	calendarsSection := slack.NewActionBlock(
 		ReleaseBlockId,
 		startDate,
@@ -0,0 +1,191 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	parkingModel "github.com/AngelVI13/slack-bot/pkg/parking_spaces/model"
+	"github.com/slack-go/slack"
+)
+
+const (
+	SubscribeActionId            = "subscribe"
+	RemoveSubscriptionActionId   = "removeSubscription"
+	SubscriptionFloorBlockId     = "subscriptionFloorBlockId"
+	SubscriptionFloorActionId    = "subscriptionFloorActionId"
+	SubscriptionAttrBlockId      = "subscriptionAttrBlockId"
+	SubscriptionAttrActionId     = "subscriptionAttrActionId"
+	SubscriptionNumberBlockId    = "subscriptionNumberBlockId"
+	SubscriptionNumberActionId   = "subscriptionNumberActionId"
+	freeSpaceNotificationBlockId = "freeSpace_"
+)
+
+type Subscriptions struct {
+	Title string
+	data  *parkingModel.ParkingData
+	// throttle Min time between two notifications sent to the same user
+	throttle time.Duration
+}
+
+func NewSubscriptions(
+	identifier string,
+	managerData *parkingModel.ParkingData,
+	throttle time.Duration,
+) *Subscriptions {
+	return &Subscriptions{
+		Title:    identifier + "Notify me",
+		data:     managerData,
+		throttle: throttle,
+	}
+}
+
+// Generate NOTE: this is pushed on top of booking modal
+func (s *Subscriptions) Generate(userId string, errorTxt string) slack.ModalViewRequest {
+	allBlocks := s.generateSubscriptionBlocks(userId, errorTxt)
+	return common.GenerateModalRequest(s.Title, allBlocks)
+}
+
+func (s *Subscriptions) generateSubscriptionBlocks(userId, errorTxt string) []slack.Block {
+	description := fmt.Sprintf(
+		"Get a message when a parking space becomes free instead of "+
+			"checking `/parking` all the time. Leave fields empty to match any "+
+			"space. To subscribe to a single space select its floor & enter its number.\n"+
+			"_You get at most one message every %d minutes & none while you have a space._",
+		int(s.throttle.Minutes()),
+	)
+	allBlocks := []slack.Block{createTextBlock(description)}
+
+	if errorTxt != "" {
+		allBlocks = append(allBlocks, createErrorTextBlock(errorTxt))
+	}
+
+	var floorOptions []*slack.OptionBlockObject
+	for _, floor := range s.data.ParkingLot.GetAllFloorNumbers() {
+		floorOptions = append(floorOptions, slack.NewOptionBlockObject(
+			fmt.Sprint(floor),
+			slack.NewTextBlockObject(slack.PlainTextType, spaces.MakeFloorStr(floor), false, false),
+			nil,
+		))
+	}
+	floorSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Any floor", false, false),
+		SubscriptionFloorActionId,
+		floorOptions...,
+	)
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		SubscriptionFloorBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Floor", false, false),
+		nil,
+		floorSelect,
+		true,
+	))
+
+	var attrOptions []*slack.OptionBlockObject
+	for _, attr := range spaces.ParkingAttributes {
+		attrOptions = append(attrOptions, slack.NewOptionBlockObject(
+			string(attr),
+			slack.NewTextBlockObject(slack.PlainTextType, spaces.AttributeLabel(attr), true, false),
+			nil,
+		))
+	}
+	attrSelect := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Any space", false, false),
+		SubscriptionAttrActionId,
+		attrOptions...,
+	)
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		SubscriptionAttrBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Features", false, false),
+		nil,
+		attrSelect,
+		true,
+	))
+
+	numberInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "12", false, false),
+		SubscriptionNumberActionId,
+	)
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		SubscriptionNumberBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Space number", false, false),
+		nil,
+		numberInput,
+		true,
+	))
+
+	allBlocks = append(allBlocks, s.generateUserSubscriptionsBlocks(userId)...)
+	return allBlocks
+}
+
+// generateUserSubscriptionsBlocks Lists subscriptions of the user
+func (s *Subscriptions) generateUserSubscriptionsBlocks(userId string) []slack.Block {
+	var allBlocks []slack.Block
+	for _, subscription := range s.data.ParkingLot.UserSubscriptions(userId) {
+		if len(allBlocks) == 0 {
+			allBlocks = append(
+				allBlocks,
+				slack.NewDividerBlock(),
+				createTextBlock("*Your subscriptions*"),
+			)
+		}
+
+		removeBtn := slack.NewButtonBlockElement(
+			RemoveSubscriptionActionId,
+			ActionValues{SubscriptionId: subscription.Id}.Encode(),
+			slack.NewTextBlockObject("plain_text", "Remove", true, false),
+		).WithStyle(slack.StyleDanger)
+		allBlocks = append(allBlocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", ":bell: "+subscription.String(), false, false),
+			nil,
+			slack.NewAccessory(removeBtn),
+		))
+	}
+	return allBlocks
+}
+
+// GenerateFreeSpaceBlocks Message informing a subscriber about free spaces
+// with a Reserve button for each space. Messages are routed by block ID so
+// it starts with the identifier of the manager.
+func GenerateFreeSpaceBlocks(
+	identifier string,
+	notification spaces.FreeSpaceNotification,
+) (string, []slack.Block) {
+	txt := ":bell: Parking spaces matching your subscriptions are free:"
+	allBlocks := []slack.Block{createTextBlock(txt)}
+
+	for _, free := range notification.Spaces {
+		spaceTxt := fmt.Sprintf(
+			"*%s* %s for *%s*",
+			free.Space.Key(),
+			free.Space.GetPropsText(),
+			free.Date.Format("Mon 2006-01-02"),
+		)
+		reserveBtn := slack.NewButtonBlockElement(
+			ReserveParkingActionId,
+			ActionValues{SpaceKey: free.Space.Key()}.Encode(),
+			slack.NewTextBlockObject("plain_text", "Reserve", true, false),
+		).WithStyle(slack.StylePrimary)
+		allBlocks = append(allBlocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", spaceTxt, false, false),
+			nil,
+			slack.NewAccessory(reserveBtn),
+			slack.SectionBlockOptionBlockID(
+				identifier+freeSpaceNotificationBlockId+string(free.Space.Key()),
+			),
+		))
+	}
+	return txt, allBlocks
+}
+
+func generateSubscribeButton(modalType ModalType) *slack.ActionBlock {
+	subscribeBtn := slack.NewButtonBlockElement(
+		SubscribeActionId,
+		ActionValues{ModalType: modalType}.Encode(),
+		slack.NewTextBlockObject("plain_text", "Notify me when free :bell:", true, false),
+	)
+	return slack.NewActionBlock("", subscribeBtn)
+}
@@ -0,0 +1,145 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	parkingModel "github.com/AngelVI13/slack-bot/pkg/parking_spaces/model"
+	"github.com/slack-go/slack"
+)
+
+const (
+	SwapActionId          = "swap"
+	AcceptSwapActionId    = "acceptSwap"
+	DeclineSwapActionId   = "declineSwap"
+	SwapUserBlockId       = "swapUserBlockId"
+	SwapUserActionId      = "swapUserActionId"
+	SwapStartDateBlockId  = "swapStartDateBlockId"
+	SwapStartDateActionId = "swapStartDateActionId"
+	SwapEndDateBlockId    = "swapEndDateBlockId"
+	SwapEndDateActionId   = "swapEndDateActionId"
+)
+
+type Swaps struct {
+	Title string
+	data  *parkingModel.ParkingData
+	// requestBlockId Block ID of buttons in swap request messages. Messages
+	// are routed by block ID so it starts with the identifier of the manager.
+	requestBlockId string
+}
+
+func NewSwaps(identifier string, managerData *parkingModel.ParkingData) *Swaps {
+	return &Swaps{
+		Title:          identifier + "Swap",
+		data:           managerData,
+		requestBlockId: identifier + "swapRequest",
+	}
+}
+
+// Generate NOTE: this is pushed on top of booking or personal modal
+func (s *Swaps) Generate(userId string) slack.ModalViewRequest {
+	space := s.data.ParkingLot.HandOverSpace(userId)
+	if space == nil {
+		errorBlock := createErrorTextBlock(
+			"You don't have a parking space that could be handed over or swapped",
+		)
+		return common.GenerateInfoModalRequest(s.Title, []slack.Block{errorBlock})
+	}
+
+	return common.GenerateModalRequest(s.Title, s.generateSwapBlocks(userId, space))
+}
+
+func (s *Swaps) generateSwapBlocks(userId string, space *spaces.Space) []slack.Block {
+	bookingDate := s.data.Calendar.BookingDate(
+		s.data.Clock.Now(),
+		s.data.ParkingLot.SpaceResetTime(space.Key()),
+	)
+	permanent := s.data.ParkingLot.OwnsSpace(userId) != nil
+
+	description := fmt.Sprintf(
+		"Your reservation of *%s* for *%s* will be handed over to the selected "+
+			"colleague. If they have a reservation as well you swap spaces.",
+		space.Key(),
+		bookingDate.Format("2006-01-02"),
+	)
+	if permanent {
+		description = fmt.Sprintf(
+			"Your space *%s* will be handed over to the selected colleague for "+
+				"the selected dates. If they own a space as well you get their "+
+				"space for the same dates.",
+			space.Key(),
+		)
+	}
+	description += "\n_The colleague has to accept the request first._"
+
+	userSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeUser,
+		slack.NewTextBlockObject(slack.PlainTextType, "Select colleague", false, false),
+		SwapUserActionId,
+	)
+	allBlocks := []slack.Block{
+		createTextBlock(description),
+		slack.NewInputBlock(
+			SwapUserBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Colleague", false, false),
+			nil,
+			userSelect,
+		),
+	}
+
+	if !permanent {
+		return allBlocks
+	}
+
+	startDate := slack.NewDatePickerBlockElement(SwapStartDateActionId)
+	startDate.InitialDate = bookingDate.Format("2006-01-02")
+	endDate := slack.NewDatePickerBlockElement(SwapEndDateActionId)
+	endDate.InitialDate = bookingDate.Format("2006-01-02")
+
+	allBlocks = append(allBlocks,
+		slack.NewInputBlock(
+			SwapStartDateBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "From", false, false),
+			nil,
+			startDate,
+		),
+		slack.NewInputBlock(
+			SwapEndDateBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "To (including)", false, false),
+			nil,
+			endDate,
+		),
+	)
+	return allBlocks
+}
+
+// GenerateRequestBlocks Message with Accept/Decline buttons sent to the
+// target of the swap request
+func (s *Swaps) GenerateRequestBlocks(request *spaces.SwapRequest, txt string) []slack.Block {
+	values := ActionValues{SwapId: request.Id}.Encode()
+	acceptBtn := slack.NewButtonBlockElement(
+		AcceptSwapActionId,
+		values,
+		slack.NewTextBlockObject("plain_text", "Accept", true, false),
+	).WithStyle(slack.StylePrimary)
+	declineBtn := slack.NewButtonBlockElement(
+		DeclineSwapActionId,
+		values,
+		slack.NewTextBlockObject("plain_text", "Decline", true, false),
+	).WithStyle(slack.StyleDanger)
+
+	return []slack.Block{
+		createTextBlock(txt),
+		slack.NewActionBlock(s.requestBlockId, acceptBtn, declineBtn),
+	}
+}
+
+func generateSwapButton(modalType ModalType) *slack.ActionBlock {
+	swapBtn := slack.NewButtonBlockElement(
+		SwapActionId,
+		ActionValues{ModalType: modalType}.Encode(),
+		slack.NewTextBlockObject("plain_text", "Hand Over / Swap :handshake:", true, false),
+	)
+	return slack.NewActionBlock("", swapBtn)
+}
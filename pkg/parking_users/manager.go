@@ -1,8 +1,11 @@
 package parking_users
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/AngelVI13/slack-bot/pkg/common"
@@ -50,6 +53,10 @@ func NewManager(
 }
 
 func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
 	switch e.Type() {
 	case event.SlashCmdEvent:
 		data := e.(*slackApi.Slash)
@@ -95,8 +102,22 @@ func (m *Manager) Context() string {
 	return Identifier
 }
 
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - users are written to file by model.Data
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
 func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
-	if !m.data.UserManager.IsAdminId(data.UserId) {
+	if !m.data.UserManager.HasAnyPermission(
+		data.UserId,
+		user.PermManageUsers,
+		user.PermManageHr,
+		user.PermAudit,
+	) {
 		errTxt := fmt.Sprintf(
 			"You don't have permission to execute '%s' command",
 			data.Command,
@@ -110,7 +131,7 @@ func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
 	if ok && selectedUser != nil {
 		selectedUserId = selectedUser.UserId
 	}
-	modal := m.generateUsersModalRequest(data, selectedUserId)
+	modal := m.generateUsersModalRequest(data, data.UserId, selectedUserId)
 
 	action := common.NewOpenViewAction(data.TriggerId, modal)
 	response := common.NewResponseEvent(data.UserName, action)
@@ -139,41 +160,57 @@ func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Respons
 			// Thats why we update the view with a clean modal
 			// and then just load the modal with actual data afterwards
 			errTxt := ""
-			clearedModal := m.generateUsersModalRequest(data, defaultUserOption)
+			clearedModal := m.generateUsersModalRequest(data, data.UserId, defaultUserOption)
 			actions = append(actions, common.NewUpdateViewAction(
 				data.TriggerId, data.ViewId, clearedModal, errTxt,
 			))
 
-			modalWithData := m.generateUsersModalRequest(data, selectedUserId)
+			modalWithData := m.generateUsersModalRequest(data, data.UserId, selectedUserId)
 			actions = append(actions, common.NewUpdateViewAction(
 				data.TriggerId, data.ViewId, modalWithData, errTxt,
 			))
-		case userOptionId:
-			isAdmin := user.STANDARD
-			hasParkingSpace := false
+		case userOptionId, userRolesOptionId:
+			selectedUser := m.selectedUser[data.UserId]
+			if selectedUser == nil {
+				continue
+			}
 
-			for _, option := range action.SelectedOptions {
-				if option.Value == userRightsOption {
-					isAdmin = user.ADMIN
-				}
-				if option.Value == userPermanentSpaceOption {
-					hasParkingSpace = true
-				}
+			if !m.data.UserManager.HasPermission(data.UserId, user.PermManageUsers) {
+				actions = append(actions, m.permissionDeniedAction(data, selectedUser.UserId)...)
+				continue
 			}
 
-			selectedUser := m.selectedUser[data.UserId]
 			if !m.data.UserManager.Exists(selectedUser.UserId) {
 				m.data.UserManager.
 					InsertUser(selectedUser.UserId, selectedUser.UserName)
 			}
 
-			m.data.UserManager.SetAccessRights(selectedUser.UserId, isAdmin)
-			m.data.UserManager.
-				SetParkingPermission(selectedUser.UserId, hasParkingSpace)
+			if action.ActionID == userOptionId {
+				hasParkingSpace := false
+				for _, option := range action.SelectedOptions {
+					if option.Value == userPermanentSpaceOption {
+						hasParkingSpace = true
+					}
+				}
+				m.data.UserManager.
+					SetParkingPermission(selectedUser.UserId, hasParkingSpace)
+			} else {
+				var roles []user.Role
+				for _, option := range action.SelectedOptions {
+					roles = append(roles, user.Role(option.Value))
+				}
+				slog.Info(
+					"Changing user roles",
+					"requestor", data.UserName,
+					"user", selectedUser.UserName,
+					"roles", roles,
+				)
+				m.data.UserManager.SetRoles(selectedUser.UserId, roles)
+			}
 			m.data.UserManager.SynchronizeToFile()
 
 			errTxt := ""
-			modal := m.generateUsersModalRequest(data, selectedUser.UserId)
+			modal := m.generateUsersModalRequest(data, data.UserId, selectedUser.UserId)
 			actions = append(actions, common.NewUpdateViewAction(
 				data.TriggerId, data.ViewId, modal, errTxt,
 			))
@@ -191,27 +228,90 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 	var actions []event.ResponseAction
 
 	// Reset selected user
+	selected := m.selectedUser[data.UserId]
 	m.selectedUser[data.UserId] = nil
+	if selected == nil {
+		return nil
+	}
+
+	// NOTE: For parking rights & roles changes take place as soon as user
+	// clicks checkbox so we don't need to handle those on view submission
+
+	canManageUsers := m.data.UserManager.HasPermission(data.UserId, user.PermManageUsers)
+	if canManageUsers && m.data.UserManager.Exists(selected.UserId) {
+		var managedFloors []int
+		for _, floorStr := range data.IValue(managedFloorsBlockId, managedFloorsActionId) {
+			floor, err := strconv.Atoi(floorStr)
+			if err != nil {
+				slog.Error("Failed to parse managed floor", "floor", floorStr, "err", err)
+				continue
+			}
+			managedFloors = append(managedFloors, floor)
+		}
 
-	// NOTE: For parking rights changes take place as soon as user clicks checkbox
-	// so we don't need to handle those on view submission
+		groups := user.ParseGroups(data.IValueString(groupsBlockId, groupsActionId))
+		plates := user.ParsePlates(data.IValueString(platesBlockId, platesActionId))
+
+		managedFloorsChanged := !slices.Equal(
+			managedFloors,
+			m.data.UserManager.GetManagedFloors(selected.UserId),
+		)
+		groupsChanged := !slices.Equal(groups, m.data.UserManager.GetGroups(selected.UserId))
+		if managedFloorsChanged {
+			m.data.UserManager.SetManagedFloors(selected.UserId, managedFloors)
+		}
+		if groupsChanged {
+			slog.Info(
+				"Changing user groups",
+				"requestor", data.UserName,
+				"user", selected.UserName,
+				"groups", groups,
+			)
+			m.data.UserManager.SetGroups(selected.UserId, groups)
+		}
+		platesChanged := !slices.Equal(plates, m.data.UserManager.GetPlates(selected.UserId))
+		if platesChanged {
+			err := m.data.UserManager.SetPlates(selected.UserId, plates)
+			if err != nil {
+				errTxt := fmt.Sprintf("Car plates were not changed: %v", err)
+				actions = append(actions, common.NewPostAction(data.UserId, errTxt, false))
+				platesChanged = false
+			} else {
+				slog.Info(
+					"Changing user car plates",
+					"requestor", data.UserName,
+					"user", selected.UserName,
+					"plates", plates,
+				)
+			}
+		}
+		quotaChanged := m.changeQuota(data, selected)
+		if managedFloorsChanged || groupsChanged || platesChanged || quotaChanged {
+			m.data.UserManager.SynchronizeToFile()
+		}
+		actions = append(actions, m.changeBookingLimit(data, selected)...)
+	}
 
 	qdevBss := strings.TrimSpace(data.IValueString(qdevBssBlockId, qdevBssActionId))
 	quadBss := strings.TrimSpace(data.IValueString(quadBssBlockId, quadBssActionId))
 
 	// TODO: currently we don't have possibility to remove the BSS ID, do we need this?
-	if qdevBss != "" || quadBss != "" {
-		if !m.data.UserManager.Exists(data.UserId) {
+	if (qdevBss != "" || quadBss != "") &&
+		!m.data.UserManager.HasPermission(data.UserId, user.PermManageHr) {
+		errTxt := "BSS numbers were not changed - you don't have permission to change them"
+		actions = append(actions, common.NewPostAction(data.UserId, errTxt, false))
+	} else if qdevBss != "" || quadBss != "" {
+		if !m.data.UserManager.Exists(selected.UserId) {
 			m.data.UserManager.
-				InsertUser(data.UserId, data.UserName)
+				InsertUser(selected.UserId, selected.UserName)
 		}
 
 		if qdevBss != "" {
-			m.data.UserManager.SetBssId(data.UserName, qdevBss, user.Qdev)
+			m.data.UserManager.SetBssId(selected.UserName, qdevBss, user.Qdev)
 		}
 
 		if quadBss != "" {
-			m.data.UserManager.SetBssId(data.UserName, quadBss, user.Quad)
+			m.data.UserManager.SetBssId(selected.UserName, quadBss, user.Quad)
 		}
 
 		m.data.UserManager.SynchronizeToFile()
@@ -220,6 +320,8 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 		"USERS ViewSubmission",
 		"name",
 		data.UserName,
+		"user",
+		selected.UserName,
 		"qdev",
 		qdevBss,
 		"quad",
@@ -232,3 +334,17 @@ func (m *Manager) handleViewSubmission(data *slackApi.ViewSubmission) *common.Re
 
 	return common.NewResponseEvent(data.UserName, actions...)
 }
+
+// permissionDeniedAction Informs user that they can't change user settings &
+// resets the modal to the current settings
+func (m *Manager) permissionDeniedAction(
+	data *slackApi.BlockAction,
+	selectedUserId string,
+) []event.ResponseAction {
+	errTxt := "You don't have permission to change user settings"
+	modal := m.generateUsersModalRequest(data, data.UserId, selectedUserId)
+	return []event.ResponseAction{
+		common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errTxt),
+		common.NewPostAction(data.UserId, errTxt, false),
+	}
+}
@@ -0,0 +1,166 @@
+package parking_users
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+const (
+	bookingLimitBlockId  = userPreffix + "BookingLimitBlockId"
+	bookingLimitActionId = userPreffix + "BookingLimitActionId"
+
+	bookingLimitNone = "none"
+	bookingLimitKeep = "keep"
+
+	// noShowDays Period for which no-shows are shown
+	noShowDays = 30
+	// repeatNoShows Min number of no-shows in noShowDays after which the user
+	// is listed as a repeat offender
+	repeatNoShows = 2
+)
+
+// bookingLimitDays Lengths of booking limits admins can choose from
+var bookingLimitDays = []int{7, 14, 28}
+
+func (m *Manager) noShowsSince() time.Time {
+	return m.data.Clock.Now().AddDate(0, 0, -noShowDays)
+}
+
+// generateRepeatNoShowsBlock Lists users with repeated no-shows so that admins
+// can limit their booking rights
+func (m *Manager) generateRepeatNoShowsBlock() slack.Block {
+	lines := []string{fmt.Sprintf("*Repeated no-shows (last %d days)*", noShowDays)}
+	for _, offender := range m.data.UserManager.RepeatNoShows(m.noShowsSince(), repeatNoShows) {
+		line := fmt.Sprintf("• <@%s> - %d", offender.UserId, offender.Count)
+		if until := m.data.UserManager.BookingLimitedUntil(offender.UserId); until != nil {
+			line += fmt.Sprintf(" (limited until %s)", until.Format("Mon 2006-01-02"))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 1 {
+		lines = append(lines, "_No repeated no-shows_")
+	}
+
+	text := slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false)
+	return slack.NewSectionBlock(text, nil, nil)
+}
+
+// generateNoShowsBlocks No-shows of the selected user & the limit of their
+// booking rights
+func (m *Manager) generateNoShowsBlocks(selectedUserId string) []slack.Block {
+	noShows := m.data.UserManager.GetNoShows(selectedUserId, m.noShowsSince())
+	lines := []string{fmt.Sprintf("*No-shows (last %d days)*: %d", noShowDays, len(noShows))}
+	for _, noShow := range noShows {
+		lines = append(lines, fmt.Sprintf("• %s - %s", noShow.Date.Format("Mon 2006-01-02"), noShow.Space))
+	}
+	text := slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false)
+
+	noLimitOption := slack.NewOptionBlockObject(
+		bookingLimitNone,
+		slack.NewTextBlockObject(slack.PlainTextType, "Not limited", false, false),
+		nil,
+	)
+	options := []*slack.OptionBlockObject{noLimitOption}
+	initialOption := noLimitOption
+
+	until := m.data.UserManager.BookingLimitedUntil(selectedUserId)
+	if until != nil && m.data.UserManager.CheckBookingAllowed(selectedUserId, m.data.Clock.Now()) != nil {
+		keepOption := slack.NewOptionBlockObject(
+			bookingLimitKeep,
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				fmt.Sprintf("Until %s", until.Format("Mon 2006-01-02")),
+				false,
+				false,
+			),
+			nil,
+		)
+		options = append(options, keepOption)
+		initialOption = keepOption
+	}
+	for _, days := range bookingLimitDays {
+		options = append(options, slack.NewOptionBlockObject(
+			strconv.Itoa(days),
+			slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("For %d days", days), false, false),
+			nil,
+		))
+	}
+
+	limitSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		nil,
+		bookingLimitActionId,
+		options...,
+	)
+	limitSelect.InitialOption = initialOption
+
+	return []slack.Block{
+		slack.NewSectionBlock(text, nil, nil),
+		common.NewInputBlock(
+			bookingLimitBlockId,
+			slack.NewTextBlockObject(slack.PlainTextType, "Limit booking", false, false),
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"User can't reserve spaces while limited (owners can still use their permanent space). Saved on submit.",
+				false,
+				false,
+			),
+			limitSelect,
+			true,
+		),
+	}
+}
+
+// changeBookingLimit Applies the booking limit selected in the submitted
+// modal. Returns message for the user whose booking rights changed.
+func (m *Manager) changeBookingLimit(
+	data *slackApi.ViewSubmission,
+	selected *selectedUser,
+) []event.ResponseAction {
+	value := data.IValueString(bookingLimitBlockId, bookingLimitActionId)
+	current := m.data.UserManager.BookingLimitedUntil(selected.UserId)
+
+	var until *time.Time
+	switch value {
+	case "", bookingLimitKeep:
+		return nil
+	case bookingLimitNone:
+		if current == nil {
+			return nil
+		}
+	default:
+		days, err := strconv.Atoi(value)
+		if err != nil {
+			slog.Error("Failed to parse booking limit", "value", value, "err", err)
+			return nil
+		}
+		date := common.DateOf(m.data.Clock.Now()).AddDate(0, 0, days-1)
+		until = &date
+	}
+
+	slog.Info(
+		"Changing booking limit",
+		"requestor", data.UserName,
+		"user", selected.UserName,
+		"until", until,
+	)
+	m.data.UserManager.SetBookingLimitedUntil(selected.UserId, until)
+	m.data.UserManager.SynchronizeToFile()
+
+	txt := ":white_check_mark: Your booking rights are no longer limited."
+	if until != nil {
+		txt = fmt.Sprintf(
+			":no_entry: Your booking rights are limited until *%s* because of missed check-ins.",
+			until.Format("Mon 2006-01-02"),
+		)
+	}
+	return []event.ResponseAction{common.NewPostAction(selected.UserId, txt, false)}
+}
@@ -0,0 +1,118 @@
+package parking_users
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+const (
+	quotaPerWeekBlockId  = userPreffix + "QuotaPerWeekBlockId"
+	quotaPerMonthBlockId = userPreffix + "QuotaPerMonthBlockId"
+	quotaConsecutiveId   = userPreffix + "QuotaConsecutiveBlockId"
+	quotaHorizonBlockId  = userPreffix + "QuotaHorizonBlockId"
+	quotaValueActionId   = userPreffix + "QuotaValueActionId"
+	quotaMaxValue        = "365"
+)
+
+// quotaInput Quota value which can be overridden per user
+type quotaInput struct {
+	blockId  string
+	label    string
+	override **int
+	value    int
+}
+
+func quotaInputs(override *user.QuotaOverride, quota user.Quota) []quotaInput {
+	return []quotaInput{
+		{quotaPerWeekBlockId, "Parking reservations per week", &override.PerWeek, quota.PerWeek},
+		{quotaPerMonthBlockId, "Parking reservations per month", &override.PerMonth, quota.PerMonth},
+		{quotaConsecutiveId, "Consecutive parking days", &override.ConsecutiveDays, quota.ConsecutiveDays},
+		{quotaHorizonBlockId, "Booking horizon (days ahead)", &override.HorizonDays, quota.HorizonDays},
+	}
+}
+
+func quotaValueStr(value int) string {
+	if value == 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(value)
+}
+
+// generateQuotaBlocks Inputs for quota values of the selected user. Empty
+// inputs use the default quota.
+func (m *Manager) generateQuotaBlocks(selectedUserId string) []slack.Block {
+	quotaText := slack.NewTextBlockObject(
+		"mrkdwn",
+		"*Quota* (leave empty to use the default, 0 means unlimited)",
+		false,
+		false,
+	)
+	allBlocks := []slack.Block{slack.NewSectionBlock(quotaText, nil, nil)}
+
+	override := m.data.UserManager.GetQuotaOverride(selectedUserId)
+	for _, input := range quotaInputs(&override, m.data.UserManager.DefaultQuota()) {
+		numberInput := slack.NewNumberInputBlockElement(
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				fmt.Sprintf("Default: %s", quotaValueStr(input.value)),
+				false,
+				false,
+			),
+			quotaValueActionId,
+			false,
+		)
+		numberInput.MinValue = "0"
+		numberInput.MaxValue = quotaMaxValue
+		if *input.override != nil {
+			numberInput.InitialValue = strconv.Itoa(**input.override)
+		}
+
+		block := slack.NewInputBlock(
+			input.blockId,
+			slack.NewTextBlockObject(slack.PlainTextType, input.label, false, false),
+			nil,
+			numberInput,
+		)
+		block.Optional = true
+		allBlocks = append(allBlocks, block)
+	}
+	return allBlocks
+}
+
+// changeQuota Applies quota values of the submitted modal. Returns true if
+// the quota of the user changed.
+func (m *Manager) changeQuota(data *slackApi.ViewSubmission, selected *selectedUser) bool {
+	var override user.QuotaOverride
+	for _, input := range quotaInputs(&override, user.Quota{}) {
+		valueStr := strings.TrimSpace(data.IValueString(input.blockId, quotaValueActionId))
+		if valueStr == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value < 0 {
+			slog.Error("Failed to parse quota", "block", input.blockId, "value", valueStr, "err", err)
+			continue
+		}
+		*input.override = &value
+	}
+
+	if override.Equal(m.data.UserManager.GetQuotaOverride(selected.UserId)) {
+		return false
+	}
+
+	slog.Info(
+		"Changing user quota",
+		"requestor", data.UserName,
+		"user", selected.UserName,
+		"quota", override.Apply(m.data.UserManager.DefaultQuota()),
+	)
+	m.data.UserManager.SetQuotaOverride(selected.UserId, override)
+	return true
+}
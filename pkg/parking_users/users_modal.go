@@ -2,9 +2,13 @@ package parking_users
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
 	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	"github.com/slack-go/slack"
 )
@@ -15,8 +19,15 @@ const (
 	userBlockId              = userPreffix + "BlockId"
 	userOptionId             = userPreffix + "OptionId"
 	userCheckboxActionId     = userPreffix + "CheckboxActionId"
-	userRightsOption         = userPreffix + "RightsOption"
 	userPermanentSpaceOption = userPreffix + "PermanentSpaceOption"
+	userRolesActionId        = userPreffix + "RolesActionId"
+	userRolesOptionId        = userPreffix + "RolesOptionId"
+	managedFloorsBlockId     = userPreffix + "ManagedFloorsBlockId"
+	managedFloorsActionId    = userPreffix + "ManagedFloorsActionId"
+	groupsBlockId            = userPreffix + "GroupsBlockId"
+	groupsActionId           = userPreffix + "GroupsActionId"
+	platesBlockId            = userPreffix + "PlatesBlockId"
+	platesActionId           = userPreffix + "PlatesActionId"
 
 	bssBlockIdSuffix string = "BssBlockId"
 	qdevBssBlockId   string = string(user.Qdev) + bssBlockIdSuffix
@@ -31,13 +42,14 @@ var usersManagementTitle = Identifier + "Settings"
 
 func (m *Manager) generateUsersModalRequest(
 	command event.Event,
+	viewerId string,
 	selectedUserId string,
 ) slack.ModalViewRequest {
-	sectionBlocks := m.generateUsersBlocks(selectedUserId)
+	sectionBlocks := m.generateUsersBlocks(viewerId, selectedUserId)
 	return common.GenerateModalRequest(usersManagementTitle, sectionBlocks)
 }
 
-func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
+func (m *Manager) generateUsersBlocks(viewerId, selectedUserId string) []slack.Block {
 	allBlocks := []slack.Block{}
 
 	text := "Select user for which to change settings"
@@ -64,6 +76,8 @@ func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
 
 	// Do not add checkboxes if user is not selected
 	if selectedUserId == defaultUserOption {
+		allBlocks = append(allBlocks, slack.NewDividerBlock())
+		allBlocks = append(allBlocks, m.generateRepeatNoShowsBlock())
 		return allBlocks
 	}
 
@@ -75,16 +89,6 @@ func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
 
 	var sectionBlocks []*slack.OptionBlockObject
 
-	adminOptionSectionBlock := slack.NewOptionBlockObject(
-		userRightsOption,
-		slack.NewTextBlockObject("mrkdwn", "Admin", false, false),
-		slack.NewTextBlockObject(
-			"mrkdwn",
-			"Select to assign Admin rights.",
-			false,
-			false,
-		),
-	)
 	hasParkingOptionSectionBlock := slack.NewOptionBlockObject(
 		userPermanentSpaceOption,
 		slack.NewTextBlockObject("mrkdwn", "Permanent Space", false, false),
@@ -98,7 +102,6 @@ func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
 
 	sectionBlocks = append(
 		sectionBlocks,
-		adminOptionSectionBlock,
 		hasParkingOptionSectionBlock,
 	)
 
@@ -106,13 +109,6 @@ func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
 		userOptionId,
 		sectionBlocks...)
 
-	if selectedUserId != defaultUserOption &&
-		m.data.UserManager.IsAdminId(selectedUserId) {
-		deviceCheckboxGroup.InitialOptions = append(
-			deviceCheckboxGroup.InitialOptions, adminOptionSectionBlock,
-		)
-	}
-
 	if selectedUserId != defaultUserOption &&
 		m.data.UserManager.HasParkingById(selectedUserId) {
 		deviceCheckboxGroup.InitialOptions = append(
@@ -123,6 +119,12 @@ func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
 	actionBlock := slack.NewActionBlock(userCheckboxActionId, deviceCheckboxGroup)
 	allBlocks = append(allBlocks, actionBlock)
 
+	allBlocks = append(allBlocks, m.generateRolesBlocks(selectedUserId)...)
+
+	if !m.data.UserManager.HasAnyPermission(viewerId, user.PermManageHr, user.PermAudit) {
+		return allBlocks
+	}
+
 	bssInfo := m.data.UserManager.GetBssInfoFromUserId(selectedUserId)
 
 	for _, bss := range bssInfo {
@@ -133,6 +135,132 @@ func (m *Manager) generateUsersBlocks(selectedUserId string) []slack.Block {
 	return allBlocks
 }
 
+func (m *Manager) generateRolesBlocks(selectedUserId string) []slack.Block {
+	rolesText := slack.NewTextBlockObject("mrkdwn", "*Roles*", false, false)
+	allBlocks := []slack.Block{slack.NewSectionBlock(rolesText, nil, nil)}
+
+	userRoles := m.data.UserManager.GetRoles(selectedUserId)
+
+	var roleOptions []*slack.OptionBlockObject
+	var initialRoles []*slack.OptionBlockObject
+	for _, role := range user.AllRoles {
+		option := slack.NewOptionBlockObject(
+			string(role),
+			slack.NewTextBlockObject("mrkdwn", user.RoleNameMap[role], false, false),
+			slack.NewTextBlockObject("mrkdwn", user.RoleDescriptionMap[role], false, false),
+		)
+		roleOptions = append(roleOptions, option)
+
+		if slices.Contains(userRoles, role) {
+			initialRoles = append(initialRoles, option)
+		}
+	}
+
+	rolesCheckboxGroup := slack.NewCheckboxGroupsBlockElement(
+		userRolesOptionId,
+		roleOptions...,
+	)
+	rolesCheckboxGroup.InitialOptions = initialRoles
+	allBlocks = append(allBlocks, slack.NewActionBlock(userRolesActionId, rolesCheckboxGroup))
+
+	floors := m.data.ParkingLot.GetAllFloorNumbers()
+	for _, floor := range m.data.WorkspacesLot.GetAllFloorNumbers() {
+		if !slices.Contains(floors, floor) {
+			floors = append(floors, floor)
+		}
+	}
+	slices.Sort(floors)
+
+	managedFloors := m.data.UserManager.GetManagedFloors(selectedUserId)
+
+	var floorOptions []*slack.OptionBlockObject
+	var initialFloors []*slack.OptionBlockObject
+	for _, floor := range floors {
+		option := slack.NewOptionBlockObject(
+			strconv.Itoa(floor),
+			slack.NewTextBlockObject(slack.PlainTextType, spaces.MakeFloorStr(floor), false, false),
+			nil,
+		)
+		floorOptions = append(floorOptions, option)
+
+		if slices.Contains(managedFloors, floor) {
+			initialFloors = append(initialFloors, option)
+		}
+	}
+
+	floorsSelect := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Select floors", false, false),
+		managedFloorsActionId,
+		floorOptions...,
+	)
+	if len(initialFloors) > 0 {
+		floorsSelect.InitialOptions = initialFloors
+	}
+
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		managedFloorsBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Managed floors", false, false),
+		slack.NewTextBlockObject(
+			slack.PlainTextType,
+			"Only used for Floor Manager role. Saved on submit.",
+			false,
+			false,
+		),
+		floorsSelect,
+		true,
+	))
+
+	allBlocks = append(allBlocks, m.generateGroupsInput(selectedUserId))
+	allBlocks = append(allBlocks, m.generatePlatesInput(selectedUserId))
+	allBlocks = append(allBlocks, m.generateNoShowsBlocks(selectedUserId)...)
+	allBlocks = append(allBlocks, m.generateQuotaBlocks(selectedUserId)...)
+
+	return allBlocks
+}
+
+func (m *Manager) generateGroupsInput(selectedUserId string) *slack.InputBlock {
+	groupsInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "management, visitors", false, false),
+		groupsActionId,
+	)
+	groupsInput.InitialValue = strings.Join(m.data.UserManager.GetGroups(selectedUserId), ", ")
+
+	hint := "Comma separated groups used to restrict who can reserve spaces. Saved on submit."
+	if groups := m.data.UserManager.AllGroups(); len(groups) > 0 {
+		hint += fmt.Sprintf(" Existing groups: %s", strings.Join(groups, ", "))
+	}
+
+	return common.NewInputBlock(
+		groupsBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Groups", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, hint, false, false),
+		groupsInput,
+		true,
+	)
+}
+
+func (m *Manager) generatePlatesInput(selectedUserId string) *slack.InputBlock {
+	platesInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "ABC123, XYZ987", false, false),
+		platesActionId,
+	)
+	platesInput.InitialValue = strings.Join(m.data.UserManager.GetPlates(selectedUserId), ", ")
+
+	return common.NewInputBlock(
+		platesBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Car plates", false, false),
+		slack.NewTextBlockObject(
+			slack.PlainTextType,
+			"Comma separated licence plates of user's cars. Saved on submit.",
+			false,
+			false,
+		),
+		platesInput,
+		true,
+	)
+}
+
 func (m *Manager) generateBssNrInput(
 	bss user.CompanyInfo[string],
 ) *slack.InputBlock {
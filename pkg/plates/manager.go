@@ -0,0 +1,218 @@
+package plates
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/config"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+)
+
+const (
+	Identifier   = "Plates: "
+	SlashCmd     = "/plate"
+	TestSlashCmd = "/test-plate"
+)
+
+// Manager Handles car plates registry. `/plate` opens modal where users
+// register their plates, `/plate <plate>` looks up owner of the car.
+type Manager struct {
+	eventManager  *event.EventManager
+	data          *model.Data
+	testingActive bool
+}
+
+func NewManager(
+	eventManager *event.EventManager,
+	data *model.Data,
+	conf *config.Config,
+) *Manager {
+	profileTitle = common.MakeTitle(profileTitle, conf.TestingActive)
+	return &Manager{
+		eventManager:  eventManager,
+		data:          data,
+		testingActive: conf.TestingActive,
+	}
+}
+
+func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
+	switch e.Type() {
+	case event.SlashCmdEvent:
+		data := e.(*slackApi.Slash)
+		if !common.ShouldProcessSlash(
+			data.Command,
+			SlashCmd,
+			TestSlashCmd,
+			m.testingActive,
+		) {
+			return
+		}
+
+		response := m.handleSlashCmd(data)
+		m.eventManager.Publish(response)
+	case event.ViewSubmissionEvent:
+		data := e.(*slackApi.ViewSubmission)
+		if data.Title != profileTitle {
+			return
+		}
+
+		response := m.handleProfileSubmission(data)
+		m.eventManager.Publish(response)
+	}
+}
+
+func (m *Manager) Context() string {
+	return Identifier
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - plates are written to file on submission
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
+	query := strings.TrimSpace(data.Text)
+	if query != "" {
+		return m.handleLookup(data, query)
+	}
+
+	modal := m.generateProfileModalRequest(data.UserId)
+	action := common.NewOpenViewAction(data.TriggerId, modal)
+	return common.NewResponseEvent(data.UserName, action)
+}
+
+func (m *Manager) handleProfileSubmission(data *slackApi.ViewSubmission) *common.Response {
+	plates := user.ParsePlates(data.IValueString(platesBlockId, platesActionId))
+
+	if !m.data.UserManager.Exists(data.UserId) {
+		m.data.UserManager.InsertUser(data.UserId, data.UserName)
+	}
+
+	txt := fmt.Sprintf("Your car plates: %s", formatPlates(plates))
+	err := m.data.UserManager.SetPlates(data.UserId, plates)
+	if err != nil {
+		slog.Error("Failed to set car plates", "user", data.UserName, "err", err)
+		txt = fmt.Sprintf("Car plates were not changed: %v", err)
+	} else {
+		slog.Info("Changed car plates", "user", data.UserName, "plates", plates)
+		m.data.UserManager.SynchronizeToFile()
+	}
+
+	action := common.NewPostAction(data.UserId, txt, false)
+	return common.NewResponseEvent(data.UserName, action)
+}
+
+func (m *Manager) handleLookup(data *slackApi.Slash, query string) *common.Response {
+	if !m.data.UserManager.CanLookupPlates(data.UserId) {
+		errTxt := fmt.Sprintf(
+			"You don't have permission to look up car owners with '%s %s'. "+
+				"Use '%s' without arguments to register your own plates.",
+			data.Command,
+			query,
+			data.Command,
+		)
+		action := common.NewPostAction(data.UserId, errTxt, false)
+		return common.NewResponseEvent(data.UserName, action)
+	}
+
+	slog.Info("Car plate lookup", "requestor", data.UserName, "query", query)
+
+	var lines []string
+	for _, match := range m.data.UserManager.FindPlates(query) {
+		lines = append(lines, m.describeOwner(match))
+	}
+	lines = append(lines, m.describeGuests(query)...)
+
+	txt := fmt.Sprintf("No car with plate matching *%s* found.", user.NormalizePlate(query))
+	if len(lines) > 0 {
+		txt = fmt.Sprintf(
+			"Cars with plate matching *%s*:\n%s",
+			user.NormalizePlate(query),
+			strings.Join(lines, "\n"),
+		)
+	}
+
+	action := common.NewPostAction(data.UserId, txt, false)
+	return common.NewResponseEvent(data.UserName, action)
+}
+
+// describeOwner Describes owner of the plate, their own parking space & their
+// reservations for today
+func (m *Manager) describeOwner(match user.PlateMatch) string {
+	txt := fmt.Sprintf(":car: *%s* - <@%s>", match.Plate, match.UserId)
+
+	owned := m.data.ParkingLot.OwnsSpace(match.UserId)
+	if owned != nil {
+		txt += fmt.Sprintf("\n\tOwn parking space: *%s*", owned.Key())
+		if owned.ReservedById != match.UserId {
+			txt += " (released today)"
+		}
+	}
+
+	var today []string
+	parking := m.data.ParkingLot.GetReservedSpace(match.UserId)
+	if parking != nil {
+		today = append(today, fmt.Sprintf("parking space *%s*", parking.Key()))
+	}
+	workspace := m.data.WorkspacesLot.GetReservedSpace(match.UserId)
+	if workspace != nil {
+		today = append(today, fmt.Sprintf("workspace *%s*", workspace.Key()))
+	}
+	if len(today) == 0 {
+		today = append(today, "_no reservation_")
+	}
+	txt += fmt.Sprintf("\n\tToday: %s", strings.Join(today, ", "))
+	return txt
+}
+
+// describeGuests Describes guests with matching plates that have a parking
+// space reserved
+func (m *Manager) describeGuests(query string) []string {
+	query = user.NormalizePlate(query)
+
+	var guestSpaces spaces.SpacesInfo
+	for _, space := range m.data.ParkingLot.UnitSpaces {
+		if space.Reserved && space.Guest != nil &&
+			strings.Contains(user.NormalizePlate(space.Guest.Plate), query) {
+			guestSpaces = append(guestSpaces, space)
+		}
+	}
+	slices.SortFunc(guestSpaces, func(a, b *spaces.Space) int {
+		return strings.Compare(a.Guest.Plate, b.Guest.Plate)
+	})
+
+	var lines []string
+	for _, space := range guestSpaces {
+		lines = append(lines, fmt.Sprintf(
+			":bust_in_silhouette: *%s* - guest %s of <@%s>\n\tToday: parking space *%s*",
+			user.NormalizePlate(space.Guest.Plate),
+			space.Guest.Name,
+			space.Guest.HostId,
+			space.Key(),
+		))
+	}
+	return lines
+}
+
+func formatPlates(plates []string) string {
+	if len(plates) == 0 {
+		return "_none_"
+	}
+	return strings.Join(plates, ", ")
+}
@@ -0,0 +1,51 @@
+package plates
+
+import (
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/slack-go/slack"
+)
+
+const (
+	platesBlockId  = "platesBlockId"
+	platesActionId = "platesActionId"
+)
+
+var profileTitle = Identifier + "My car"
+
+func (m *Manager) generateProfileModalRequest(userId string) slack.ModalViewRequest {
+	return common.GenerateModalRequest(profileTitle, m.generateProfileBlocks(userId))
+}
+
+func (m *Manager) generateProfileBlocks(userId string) []slack.Block {
+	description := "Register licence plates of your car(s) so that colleagues " +
+		"& security can reach you if your car is blocking someone."
+	allBlocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", description, false, false),
+			nil,
+			nil,
+		),
+	}
+
+	platesInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "ABC123, XYZ987", false, false),
+		platesActionId,
+	)
+	platesInput.InitialValue = strings.Join(m.data.UserManager.GetPlates(userId), ", ")
+
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		platesBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Car plates", false, false),
+		slack.NewTextBlockObject(
+			slack.PlainTextType,
+			"Comma separated. Leave empty to remove all plates.",
+			false,
+			false,
+		),
+		platesInput,
+		true,
+	))
+	return allBlocks
+}
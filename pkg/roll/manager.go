@@ -1,6 +1,7 @@
 package roll
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 
@@ -50,6 +51,15 @@ func (m *Manager) Context() string {
 	return Identifier
 }
 
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - roll manager is stateless
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
 func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
 	roll := rand.Intn(100) + 1
 	text := fmt.Sprintf("%s rolled %d", data.UserName, roll)
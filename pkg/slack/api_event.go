@@ -29,6 +29,25 @@ func (m *Mention) HasContext(c string) bool {
 	return true
 }
 
+// AppHomeOpened User opened the home tab of the app
+type AppHomeOpened struct {
+	BaseEvent
+}
+
+func (a *AppHomeOpened) Type() event.EventType {
+	return event.AppHomeOpenedEvent
+}
+
+func (a *AppHomeOpened) Info() map[string]any {
+	return map[string]any{
+		"userId": a.UserId,
+	}
+}
+
+func (a *AppHomeOpened) HasContext(c string) bool {
+	return true
+}
+
 // handleApiEvent will take an event and handle it properly based on the type of event
 func handleApiEvent(socketEvent socketmode.Event, client *Client) event.Event {
 	// The Event sent on the channel is not the same as the EventAPI events so we need to type cast it
@@ -66,6 +85,21 @@ func handleApiEvent(socketEvent socketmode.Event, client *Client) event.Event {
 				Timestamp: ev.TimeStamp,
 			}
 			return processedEvent
+		case *slackevents.AppHomeOpenedEvent:
+			// NOTE: messages tab of the app is opened as well
+			if ev.Tab != "home" {
+				return nil
+			}
+			user, err := client.socket.GetUserInfo(ev.User)
+			if err != nil {
+				return nil
+			}
+			return &AppHomeOpened{
+				BaseEvent: BaseEvent{
+					UserName: user.Name,
+					UserId:   user.ID,
+				},
+			}
 		default:
 			slog.Error("unsupported callback event type", "innerEvent.Data", innerEvent.Data)
 			return nil
@@ -1,17 +1,19 @@
 package slack
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
 
+	"github.com/AngelVI13/slack-bot/pkg/clock"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
@@ -21,9 +23,17 @@ type Client struct {
 	socket         *socketmode.Client
 	eventManager   *event.EventManager
 	reportPersonId string
+	clock          clock.Clock
+
+	// listening is closed once the Listen loop has exited
+	listening chan struct{}
 }
 
-func NewClient(config *config.Config, eventManager *event.EventManager) *Client {
+func NewClient(
+	config *config.Config,
+	eventManager *event.EventManager,
+	clk clock.Clock,
+) *Client {
 	client := slack.New(
 		config.SlackAuthToken,
 		// TODO: this spams the output too much, do i need it ?
@@ -47,16 +57,50 @@ func NewClient(config *config.Config, eventManager *event.EventManager) *Client
 		socket:         socketClient,
 		eventManager:   eventManager,
 		reportPersonId: config.ReportPersonId,
+		clock:          clk,
+		listening:      make(chan struct{}),
 	}
+	return c
+}
+
+// Start Connects to slack and starts listening for incoming events. Both stop
+// once ctx is cancelled.
+func (c *Client) Start(ctx context.Context) error {
 	// This actually performs the connection to slack (its blocking)
-	go c.socket.Run()
+	go func() {
+		err := c.socket.RunContext(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Slack socket mode client stopped", "err", err)
+		}
+	}()
 
-	return c
+	go c.Listen(ctx)
+	return nil
 }
 
-// Listen Listen on incomming slack events
-func (c *Client) Listen() {
-	for socketEvent := range c.socket.Events {
+// Stop Waits for the listener to stop so that no new events are published
+func (c *Client) Stop(ctx context.Context) error {
+	select {
+	case <-c.listening:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Listen Listen on incomming slack events until ctx is cancelled
+func (c *Client) Listen(ctx context.Context) {
+	defer close(c.listening)
+
+	for {
+		var socketEvent socketmode.Event
+		select {
+		case <-ctx.Done():
+			slog.Info("Slack listener stopped")
+			return
+		case socketEvent = <-c.socket.Events:
+		}
+
 		var processedEvent event.Event
 		// We have a new Events, let's type switch the event
 		// Add more use cases here if you want to listen to other events.
@@ -84,7 +128,7 @@ func (c *Client) Listen() {
 }
 
 func (c *Client) ReportError(msg string) {
-	timestamp := time.Now()
+	timestamp := c.clock.Now()
 	filename := fmt.Sprintf(
 		"report_%s.txt",
 		timestamp.Format("2006_01_02_15_04_05"),
@@ -235,6 +279,49 @@ func (c *Client) Consume(e event.Event) {
 				)
 				c.ReportError(msgTxt)
 			}
+		case event.UpdateMessage:
+			update := action.(*common.UpdateMessageAction)
+			_, _, _, err := c.socket.UpdateMessage(
+				update.ChannelId,
+				update.Timestamp,
+				update.MsgOption,
+			)
+			if err != nil {
+				msgTxt := fmt.Sprintf(
+					"Slack update message error.\nUser: %s\nTimestamp: %s\nError:%s\nTxt: %s\nChannelId: %s\n",
+					e.User(),
+					update.Timestamp,
+					err,
+					update.Txt,
+					update.ChannelId,
+				)
+				c.ReportError(msgTxt)
+			}
+		case event.PublishHome:
+			publish := action.(*common.PublishHomeAction)
+			_, err := c.socket.PublishView(publish.UserId, publish.View, "")
+			if err != nil {
+				msgTxt := fmt.Sprintf(
+					"Slack publish home error.\nUser: %s\nError:%s\nUserId: %s\n",
+					e.User(),
+					err,
+					publish.UserId,
+				)
+				c.ReportError(msgTxt)
+			}
+		case event.UploadFile:
+			upload := action.(*common.UploadFileAction)
+			err := c.uploadFile(upload)
+			if err != nil {
+				msgTxt := fmt.Sprintf(
+					"Slack upload file error.\nUser: %s\nFilename: %s\nError:%s\nChannelId: %s\n",
+					e.User(),
+					upload.Filename,
+					err,
+					upload.ChannelId,
+				)
+				c.ReportError(msgTxt)
+			}
 		default:
 			slog.Error("Unsupported action", "action", action.Action())
 			c.ReportError(
@@ -247,3 +334,28 @@ func (c *Client) Consume(e event.Event) {
 		}
 	}
 }
+
+// uploadFile Uploads file to a channel. Files can't be uploaded to a user ID
+// directly so for users a direct message channel is opened first.
+func (c *Client) uploadFile(upload *common.UploadFileAction) error {
+	channelId := upload.ChannelId
+	if strings.HasPrefix(channelId, "U") || strings.HasPrefix(channelId, "W") {
+		channel, _, _, err := c.socket.OpenConversation(
+			&slack.OpenConversationParameters{Users: []string{channelId}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to open conversation: %w", err)
+		}
+		channelId = channel.ID
+	}
+
+	_, err := c.socket.UploadFileV2(slack.UploadFileV2Parameters{
+		Reader:         bytes.NewReader(upload.Content),
+		FileSize:       len(upload.Content),
+		Filename:       upload.Filename,
+		Title:          upload.Filename,
+		InitialComment: upload.Comment,
+		Channel:        channelId,
+	})
+	return err
+}
@@ -1,6 +1,8 @@
 package slack
 
 import (
+	"bytes"
+	"fmt"
 	"log"
 	"log/slog"
 	"strings"
@@ -16,10 +18,31 @@ type Interaction struct {
 	Actions   []*slack.BlockAction
 	TriggerId string
 	ViewId    string
-	Title     string
+	// ViewType Type of the view which contains the action (i.e. modal or
+	// home)
+	ViewType slack.ViewType
+	Title    string
+	// ChannelId & MessageTs Message which contains the action (empty for
+	// actions in views)
+	ChannelId string
+	MessageTs string
+	// PrivateMetadata Data stored in the view when it was opened (i.e. which
+	// item the view refers to)
+	PrivateMetadata string
 }
 
 func (i *Interaction) HasContext(c string) bool {
+	// NOTE: actions in messages & in the app home don't have a view title ->
+	// they are routed by block ID which has to start with the identifier of
+	// the manager that posted the message
+	if i.ViewId == "" || i.ViewType == slack.VTHomeTab {
+		for _, action := range i.Actions {
+			if strings.HasPrefix(action.BlockID, c) {
+				return true
+			}
+		}
+		return false
+	}
 	return strings.Contains(i.Title, c)
 }
 
@@ -106,8 +129,27 @@ func (i *Interaction) ActionInfo() map[string]string {
 	return out
 }
 
+// UploadedFile File uploaded through a file input of a submitted modal
+type UploadedFile struct {
+	ActionId string
+	Name     string
+	Content  []byte
+}
+
 type ViewSubmission struct {
 	Interaction
+	Files []UploadedFile
+	// FilesErr Error while downloading uploaded files (if any)
+	FilesErr error
+}
+
+func (v *ViewSubmission) File(actionId string) (UploadedFile, bool) {
+	for _, file := range v.Files {
+		if file.ActionId == actionId {
+			return file, true
+		}
+	}
+	return UploadedFile{}, false
 }
 
 func (v *ViewSubmission) Type() event.EventType {
@@ -190,16 +232,32 @@ func handleInteractionEvent(socketEvent socketmode.Event, c *Client) event.Event
 			UserName: interactionCb.User.Name,
 			UserId:   interactionCb.User.ID,
 		},
-		Values:    interactionCb.View.State.Values,
 		Actions:   interactionCb.ActionCallback.BlockActions,
 		TriggerId: interactionCb.TriggerID,
 		ViewId:    interactionCb.View.ID,
-		Title:     interactionCb.View.Title.Text,
+		ViewType:  interactionCb.View.Type,
+		ChannelId: interactionCb.Container.ChannelID,
+		MessageTs: interactionCb.Container.MessageTs,
+
+		PrivateMetadata: interactionCb.View.PrivateMetadata,
+	}
+	// NOTE: actions in messages are not part of any view -> view has no state
+	// & no title
+	if interactionCb.View.State != nil {
+		interaction.Values = interactionCb.View.State.Values
+	}
+	if interactionCb.View.Title != nil {
+		interaction.Title = interactionCb.View.Title.Text
 	}
 
 	switch interactionCb.Type {
 	case slack.InteractionTypeViewSubmission:
-		event = &ViewSubmission{interaction}
+		files, err := downloadFiles(interaction, c)
+		event = &ViewSubmission{
+			Interaction: interaction,
+			Files:       files,
+			FilesErr:    err,
+		}
 	case slack.InteractionTypeBlockActions:
 		event = &BlockAction{
 			Interaction:      interaction,
@@ -233,3 +291,40 @@ func userNameForSelectedUser(interaction Interaction, c *Client) string {
 	}
 	return userData.Name
 }
+
+// maxUploadedFileSize Files uploaded to modals are downloaded in memory so
+// their size is limited
+const maxUploadedFileSize = 5 * 1024 * 1024
+
+// downloadFiles Downloads files uploaded through file inputs of the modal
+func downloadFiles(interaction Interaction, c *Client) ([]UploadedFile, error) {
+	var files []UploadedFile
+	for _, actions := range interaction.Values {
+		for actionId, action := range actions {
+			for _, file := range action.Files {
+				if file.Size > maxUploadedFileSize {
+					return nil, fmt.Errorf(
+						"file %q is too large (%d bytes), max size is %d bytes",
+						file.Name,
+						file.Size,
+						maxUploadedFileSize,
+					)
+				}
+
+				var buf bytes.Buffer
+				err := c.socket.GetFile(file.URLPrivateDownload, &buf)
+				if err != nil {
+					slog.Error("Failed to download file", "file", file.Name, "err", err)
+					return nil, fmt.Errorf("failed to download file %q: %w", file.Name, err)
+				}
+
+				files = append(files, UploadedFile{
+					ActionId: actionId,
+					Name:     file.Name,
+					Content:  buf.Bytes(),
+				})
+			}
+		}
+	}
+	return files, nil
+}
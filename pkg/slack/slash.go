@@ -10,7 +10,9 @@ import (
 
 type Slash struct {
 	BaseEvent
-	Command     string
+	Command string
+	// Text Arguments of the command
+	Text        string
 	TriggerId   string
 	ChannelName string
 	ChannelId   string
@@ -45,6 +47,7 @@ func handleSlashCommand(socketEvent socketmode.Event) event.Event {
 			UserId:   command.UserID,
 		},
 		Command:     command.Command,
+		Text:        command.Text,
 		TriggerId:   command.TriggerID,
 		ChannelName: command.ChannelName,
 		ChannelId:   command.ChannelID,
@@ -5,11 +5,12 @@ import (
 	"log"
 	"slices"
 	"strings"
-	"time"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/event"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
 	"github.com/slack-go/slack"
 )
@@ -21,12 +22,16 @@ const (
 	releaseWorkspaceActionId = "releaseWorkspace"
 	showActionId             = "showActionId"
 	showOptionId             = "showOptionId"
+	slotsActionId            = "workspaceSlotsActionId"
+	slotsOptionId            = "workspaceSlotsOptionId"
+	releaseSlotsActionId     = "releaseWorkspaceSlots"
 )
 
 var (
-	showOptions     = [2]string{"Free", "Taken"}
+	showOptions     = [3]string{"Free", "Taken", "Week"}
 	showFreeOption  = showOptions[0]
 	showTakenOption = showOptions[1]
+	showWeekOption  = showOptions[2]
 )
 
 var workspaceBookingTitle = Identifier + "Booking"
@@ -48,15 +53,22 @@ func (m *Manager) generateSpacesInfo(spaces spaces.SpacesInfo) []slack.Block {
 	var sections []slack.Block
 	for _, space := range spaces {
 		status := space.GetStatusDescription()
-		emoji := space.GetStatusEmoji()
+		emoji := m.getStatusEmoji(space)
+
+		restriction := ""
+		if !space.Reserved && space.IsRestricted() {
+			restriction = "\n\t\t" + m.data.WorkspacesLot.GetRestrictionText(space)
+		}
 
 		spaceProps := space.GetPropsText()
 		text := fmt.Sprintf(
-			"%s *%s* \t%s\t %s",
+			"%s *%s* \t%s\t %s%s%s",
 			emoji,
 			fmt.Sprint(space.Number),
 			spaceProps,
 			status,
+			restriction,
+			m.generateSlotsStatus(space),
 		)
 
 		sectionText := slack.NewTextBlockObject("mrkdwn", text, false, false)
@@ -68,13 +80,75 @@ func (m *Manager) generateSpacesInfo(spaces spaces.SpacesInfo) []slack.Block {
 	return sections
 }
 
+// getStatusEmoji NOTE: space is taken only when it is reserved for the whole
+// day or all of its time slots are reserved
+func (m *Manager) getStatusEmoji(space *spaces.Space) string {
+	return spaces.StatusEmoji(m.data.WorkspacesLot.IsTaken(space))
+}
+
+// generateSlotsStatus Status of every time slot of a space that is not
+// reserved for the whole day
+func (m *Manager) generateSlotsStatus(space *spaces.Space) string {
+	if space.Reserved {
+		return ""
+	}
+
+	var lines []string
+	for _, slot := range m.data.WorkspacesLot.TimeSlots() {
+		status := "free"
+		reservation := space.SlotReservation(slot)
+		if reservation != nil {
+			status = fmt.Sprintf("<@%s>", reservation.ReservedById)
+		}
+		lines = append(lines, fmt.Sprintf(
+			"\n\t\t%s %s %s",
+			spaces.StatusEmoji(reservation != nil),
+			slot,
+			status,
+		))
+	}
+	return strings.Join(lines, "")
+}
+
+// canReserveSlots Checks if all selected slots of the space are free & the
+// user didn't reserve them elsewhere
+func (m *Manager) canReserveSlots(
+	space *spaces.Space,
+	userId string,
+	slots []calendar.TimeSlot,
+) bool {
+	for _, slot := range slots {
+		if space.SlotReservation(slot) != nil {
+			return false
+		}
+		if m.data.WorkspacesLot.SlotSpace(userId, slot) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// hasOthersSlots Checks if someone else reserved any slot of the space
+func hasOthersSlots(space *spaces.Space, userId string) bool {
+	for _, reservation := range space.Slots {
+		if reservation.ReservedById != userId {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) generateWorkspaceButtons(
 	space *spaces.Space,
 	userId string,
 ) []slack.BlockElement {
 	var buttons []slack.BlockElement
 
-	isAdminUser := m.data.UserManager.IsAdminId(userId)
+	isAdminUser := m.data.UserManager.HasFloorPermission(
+		userId,
+		user.PermManageWorkspaces,
+		space.Floor,
+	)
 
 	if space.Reserved && (space.ReservedById == userId || isAdminUser) {
 		releaseButton := slack.NewButtonBlockElement(
@@ -84,10 +158,35 @@ func (m *Manager) generateWorkspaceButtons(
 		)
 		releaseButton = releaseButton.WithStyle(slack.StyleDanger)
 		buttons = append(buttons, releaseButton)
-	} else if (!space.Reserved &&
+	} else if selectedSlots := m.selectedTimeSlots(userId); len(selectedSlots) > 0 {
+		if !space.Reserved &&
+			m.canReserveSlots(space, userId, selectedSlots) &&
+			m.data.WorkspacesLot.CanReserve(space, m.data.UserManager.GetGroups(userId)) {
+			var slotNames []string
+			for _, slot := range selectedSlots {
+				slotNames = append(slotNames, slot.String())
+			}
+			reserveSlotsButton := slack.NewButtonBlockElement(
+				reserveWorkspaceActionId,
+				views.ActionValues{SpaceKey: space.Key()}.Encode(),
+				slack.NewTextBlockObject(
+					"plain_text",
+					"Reserve "+strings.Join(slotNames, ", "),
+					true,
+					false,
+				),
+			)
+			reserveSlotsButton = reserveSlotsButton.WithStyle(slack.StylePrimary)
+			buttons = append(buttons, reserveSlotsButton)
+		}
+	} else if ((!space.Reserved &&
 		!m.data.WorkspacesLot.HasSpace(userId) &&
-		!isAdminUser) || (!space.Reserved && isAdminUser) {
+		!m.data.WorkspacesLot.HasAnySlot(userId) &&
+		!isAdminUser) || (!space.Reserved && isAdminUser)) &&
+		!hasOthersSlots(space, userId) &&
+		m.data.WorkspacesLot.CanReserve(space, m.data.UserManager.GetGroups(userId)) {
 		// Only allow user to reserve space if he hasn't already reserved one
+		// & is allowed to reserve it
 		actionButtonText := "Reserve!"
 		reserveWithAutoButton := slack.NewButtonBlockElement(
 			reserveWorkspaceActionId,
@@ -97,27 +196,38 @@ func (m *Manager) generateWorkspaceButtons(
 		reserveWithAutoButton = reserveWithAutoButton.WithStyle(slack.StylePrimary)
 		buttons = append(buttons, reserveWithAutoButton)
 	}
+
+	if space.HasSlots(userId) || (isAdminUser && len(space.Slots) > 0) {
+		releaseSlotsButton := slack.NewButtonBlockElement(
+			releaseSlotsActionId,
+			views.ActionValues{SpaceKey: space.Key()}.Encode(),
+			slack.NewTextBlockObject("plain_text", "Release slots", true, false),
+		)
+		releaseSlotsButton = releaseSlotsButton.WithStyle(slack.StyleDanger)
+		buttons = append(buttons, releaseSlotsButton)
+	}
 	return buttons
 }
 
-func generateWorkspaceTimeBlocks() []slack.Block {
-	now := time.Now()
+func (m *Manager) generateWorkspaceTimeBlocks(selectedFloor string) []slack.Block {
+	resetTime := m.data.WorkspacesLot.ResetTime(selectedFloor)
+	now := m.data.Calendar.BookingDate(m.data.Clock.Now(), resetTime)
 
-	if now.Hour() >= ResetHour && now.Minute() > ResetMin {
-		now = now.Add(24 * time.Hour)
+	txt := fmt.Sprintf(
+		"_Reservation is valid for %d-%d-%d and will be auto released at %s_",
+		now.Year(),
+		now.Month(),
+		now.Day(),
+		resetTime,
+	)
+	if len(m.data.WorkspacesLot.TimeSlots()) > 0 {
+		txt += "\n_Select time slots to reserve only a part of the day (whole day if none are selected)_"
 	}
 
 	selectionEffectTime := slack.NewSectionBlock(
 		slack.NewTextBlockObject(
 			"mrkdwn",
-			fmt.Sprintf(
-				"_Reservation is valid for %d-%d-%d and will be auto released at %d:%02d_",
-				now.Year(),
-				now.Month(),
-				now.Day(),
-				ResetHour,
-				ResetMin,
-			),
+			txt,
 			false,
 			false,
 		),
@@ -186,7 +296,7 @@ func (m *Manager) generateWorkspaceInfoBlocks(
 	workspacePlanBlocks := m.generateWorkspacePlanBlocks(selectedFloor)
 	allBlocks = append(allBlocks, workspacePlanBlocks...)
 
-	descriptionBlocks := generateWorkspaceTimeBlocks()
+	descriptionBlocks := m.generateWorkspaceTimeBlocks(selectedFloor)
 	allBlocks = append(allBlocks, descriptionBlocks...)
 
 	floorOptionBlocks := m.generateFloorOptions(userId)
@@ -198,6 +308,15 @@ func (m *Manager) generateWorkspaceInfoBlocks(
 	showOptionBlocks := m.generateFreeTakenOptions(userId)
 	allBlocks = append(allBlocks, showOptionBlocks...)
 
+	slotOptionBlocks := m.generateSlotOptions(userId)
+	allBlocks = append(allBlocks, slotOptionBlocks...)
+
+	allBlocks = append(allBlocks, views.GenerateAttributesFilter(
+		spaces.WorkspaceAttributes,
+		m.selectedAttributes[userId],
+	))
+	allBlocks = append(allBlocks, generateDaysButtons(len(m.neighbourhoodNames(userId)) > 0))
+
 	if errorTxt != "" {
 		txt := fmt.Sprintf(`:warning: %s`, errorTxt)
 		errorSection := slack.NewSectionBlock(
@@ -211,12 +330,18 @@ func (m *Manager) generateWorkspaceInfoBlocks(
 	div := slack.NewDividerBlock()
 	allBlocks = append(allBlocks, div)
 
+	if m.selectedShowWeek[userId] {
+		allBlocks = append(allBlocks, m.generateWeekBlocks(userId, selectedFloor)...)
+		return allBlocks
+	}
+
 	selectedSpaceType := spaces.SpaceFree
 	if selectedShowTaken {
 		selectedSpaceType = spaces.SpaceTaken
 	}
 	spaces := m.data.WorkspacesLot.
-		GetSpacesByFloor(userId, selectedFloor, selectedSpaceType)
+		GetSpacesByFloor(userId, selectedFloor, selectedSpaceType).
+		WithAttributes(userId, m.selectedAttributes[userId])
 	workspaceSections := m.generateSpacesInfo(spaces)
 
 	for idx, space := range spaces {
@@ -325,6 +450,8 @@ func (m *Manager) generateFreeTakenOptions(userId string) []slack.Block {
 	showTaken := m.selectedShowTaken[userId]
 	if showTaken {
 		selectedOption = showTakenOption
+	} else if m.selectedShowWeek[userId] {
+		selectedOption = showWeekOption
 	}
 
 	// Text shown as title when option box is opened/expanded
@@ -352,3 +479,53 @@ func (m *Manager) generateFreeTakenOptions(userId string) []slack.Block {
 
 	return allBlocks
 }
+
+// generateSlotOptions Time slots to reserve instead of the whole day. Nothing
+// is shown if time slots are not configured.
+func (m *Manager) generateSlotOptions(userId string) []slack.Block {
+	timeSlots := m.data.WorkspacesLot.TimeSlots()
+	if len(timeSlots) == 0 {
+		return nil
+	}
+
+	selected := m.selectedTimeSlots(userId)
+	var optionBlocks []*slack.OptionBlockObject
+	var initialOptions []*slack.OptionBlockObject
+	for _, slot := range timeSlots {
+		optionBlock := slack.NewOptionBlockObject(
+			slot.String(),
+			slack.NewTextBlockObject("plain_text", slot.String(), false, false),
+			nil,
+		)
+		optionBlocks = append(optionBlocks, optionBlock)
+
+		if slices.Contains(selected, slot) {
+			initialOptions = append(initialOptions, optionBlock)
+		}
+	}
+
+	placeholder := slack.NewTextBlockObject("plain_text", "Whole day", false, false)
+	multiSelect := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeStatic,
+		placeholder,
+		slotsOptionId,
+		optionBlocks...,
+	)
+	if len(initialOptions) > 0 {
+		multiSelect.InitialOptions = initialOptions
+	}
+
+	return []slack.Block{slack.NewActionBlock(slotsActionId, multiSelect)}
+}
+
+// selectedTimeSlots Returns time slots selected by the user that are still
+// configured (config might have been reloaded since they were selected)
+func (m *Manager) selectedTimeSlots(userId string) []calendar.TimeSlot {
+	var selected []calendar.TimeSlot
+	for _, slot := range m.data.WorkspacesLot.TimeSlots() {
+		if slices.Contains(m.selectedSlots[userId], slot) {
+			selected = append(selected, slot)
+		}
+	}
+	return selected
+}
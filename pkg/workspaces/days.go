@@ -0,0 +1,501 @@
+package workspaces
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+const (
+	bookDaysActionId         = "bookWorkspaceDays"
+	myWorkspacesActionId     = "myWorkspaces"
+	cancelDayBookingActionId = "cancelWorkspaceDay"
+	dayDeskBlockId           = "workspaceDayDeskBlockId"
+	dayDeskActionId          = "workspaceDayDeskActionId"
+	weekDaysBlockId          = "workspaceWeekDaysBlockId"
+	weekDaysActionId         = "workspaceWeekDaysActionId"
+	rangeStartBlockId        = "workspaceRangeStartBlockId"
+	rangeStartActionId       = "workspaceRangeStartActionId"
+	rangeEndBlockId          = "workspaceRangeEndBlockId"
+	rangeEndActionId         = "workspaceRangeEndActionId"
+
+	// daysInWeek Number of working days shown in the weekly grid
+	daysInWeek = 5
+	// maxDeskOptions Slack rejects selects with more options
+	maxDeskOptions = 100
+	ownEmoji       = ":large_blue_circle:"
+)
+
+var (
+	bookDaysTitle     = Identifier + "Book days"
+	myWorkspacesTitle = Identifier + "My desks"
+)
+
+// weekDays Returns working days of the coming week starting with the date
+// for which reservations are currently made
+func (m *Manager) weekDays(selectedFloor string) []time.Time {
+	resetTime := m.data.WorkspacesLot.ResetTime(selectedFloor)
+	date := m.data.Calendar.BookingDate(m.data.Clock.Now(), resetTime)
+
+	var days []time.Time
+	for len(days) < daysInWeek {
+		if m.data.Calendar.IsWorkingDay(date) {
+			days = append(days, date)
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return days
+}
+
+// dayStatusEmoji Status of the space on the date. Reservations of the first
+// day are already made on the space, later days only have bookings &
+// permanent owners.
+func (m *Manager) dayStatusEmoji(
+	space *spaces.Space,
+	userId string,
+	date time.Time,
+	bookingDate time.Time,
+) string {
+	lot := m.data.WorkspacesLot
+	if common.EqualDate(date, bookingDate) {
+		if (space.Reserved && space.ReservedById == userId) || space.HasSlots(userId) {
+			return ownEmoji
+		}
+		return spaces.StatusEmoji(lot.IsTaken(space))
+	}
+
+	booking := lot.SpaceBookingOn(space.Key(), date)
+	if booking != nil && booking.ReservedById == userId {
+		return ownEmoji
+	}
+	return spaces.StatusEmoji(booking != nil || lot.OwnerUsesOn(space, date))
+}
+
+// generateWeekBlocks Availability grid of the floor with one row per space &
+// one column per working day of the coming week
+func (m *Manager) generateWeekBlocks(userId, selectedFloor string) []slack.Block {
+	days := m.weekDays(selectedFloor)
+
+	var dayNames []string
+	for _, day := range days {
+		dayNames = append(dayNames, day.Format("Mon 01-02"))
+	}
+	lines := []string{
+		fmt.Sprintf("*Days:* %s", strings.Join(dayNames, " | ")),
+		fmt.Sprintf(
+			"_%s free  %s taken  %s yours_",
+			spaces.StatusEmoji(false),
+			spaces.StatusEmoji(true),
+			ownEmoji,
+		),
+	}
+
+	floorSpaces := m.data.WorkspacesLot.
+		GetSpacesByFloor(userId, selectedFloor, spaces.SpaceAny).
+		WithAttributes(userId, m.selectedAttributes[userId])
+	slices.SortFunc(floorSpaces, func(a, b *spaces.Space) int {
+		return a.Number - b.Number
+	})
+
+	for _, space := range floorSpaces {
+		var emojis strings.Builder
+		for _, day := range days {
+			emojis.WriteString(m.dayStatusEmoji(space, userId, day, days[0]))
+		}
+		lines = append(lines, fmt.Sprintf("`%4d` %s", space.Number, emojis.String()))
+	}
+	return common.TextBlocks(lines)
+}
+
+// generateDaysButtons NOTE: team booking is only shown if there are
+// neighbourhoods to choose from
+func generateDaysButtons(showTeamBooking bool) *slack.ActionBlock {
+	bookDaysButton := slack.NewButtonBlockElement(
+		bookDaysActionId,
+		"",
+		slack.NewTextBlockObject("plain_text", "Book days :calendar:", true, false),
+	)
+	myWorkspacesButton := slack.NewButtonBlockElement(
+		myWorkspacesActionId,
+		"",
+		slack.NewTextBlockObject("plain_text", "My desks", true, false),
+	)
+	buttons := []slack.BlockElement{bookDaysButton, myWorkspacesButton}
+	if showTeamBooking {
+		buttons = append(buttons, generateTeamBookingButton())
+	}
+	return slack.NewActionBlock("", buttons...)
+}
+
+// generateBookDaysModal NOTE: this is pushed on top of the booking modal
+// which is refreshed after submission (rootViewId)
+func (m *Manager) generateBookDaysModal(userId, rootViewId string) slack.ModalViewRequest {
+	selectedFloor := m.selectedFloorByChannel(userId)
+	userGroups := m.data.UserManager.GetGroups(userId)
+
+	description := fmt.Sprintf(
+		"Book a workspace on *%s* for some days of the coming week or for "+
+			"a date range. Upcoming days are reserved on the reset before "+
+			"each day. Non-working days of the range are skipped.",
+		selectedFloor,
+	)
+	allBlocks := []slack.Block{createTextBlock(description)}
+
+	floorSpaces := m.data.WorkspacesLot.GetSpacesByFloor(userId, selectedFloor, spaces.SpaceAny)
+	slices.SortFunc(floorSpaces, func(a, b *spaces.Space) int {
+		return a.Number - b.Number
+	})
+
+	var deskOptions []*slack.OptionBlockObject
+	for _, space := range floorSpaces {
+		if !space.IsEligible(userGroups) || len(deskOptions) == maxDeskOptions {
+			continue
+		}
+		deskOptions = append(deskOptions, slack.NewOptionBlockObject(
+			string(space.Key()),
+			slack.NewTextBlockObject(slack.PlainTextType, string(space.Key()), false, false),
+			nil,
+		))
+	}
+	deskSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Choose a workspace", false, false),
+		dayDeskActionId,
+		deskOptions...,
+	)
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		dayDeskBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Workspace", false, false),
+		nil,
+		deskSelect,
+	))
+
+	var dayOptions []*slack.OptionBlockObject
+	for _, day := range m.weekDays(selectedFloor) {
+		dayOptions = append(dayOptions, slack.NewOptionBlockObject(
+			day.Format("2006-01-02"),
+			slack.NewTextBlockObject(slack.PlainTextType, day.Format("Mon 2006-01-02"), false, false),
+			nil,
+		))
+	}
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		weekDaysBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Days of the coming week", false, false),
+		nil,
+		slack.NewCheckboxGroupsBlockElement(weekDaysActionId, dayOptions...),
+		true,
+	))
+
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		rangeStartBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "or from", false, false),
+		nil,
+		slack.NewDatePickerBlockElement(rangeStartActionId),
+		true,
+	))
+	allBlocks = append(allBlocks, common.NewInputBlock(
+		rangeEndBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "until (including)", false, false),
+		nil,
+		slack.NewDatePickerBlockElement(rangeEndActionId),
+		true,
+	))
+
+	modal := common.GenerateModalRequest(bookDaysTitle, allBlocks)
+	modal.PrivateMetadata = views.ActionValues{RootViewId: rootViewId}.Encode()
+	return modal
+}
+
+// generateMyWorkspacesModal Lists current reservations & upcoming bookings
+// of the user. Bookings can be cancelled per day.
+func (m *Manager) generateMyWorkspacesModal(userId, errorTxt string) slack.ModalViewRequest {
+	lot := m.data.WorkspacesLot
+	allBlocks := []slack.Block{}
+
+	if errorTxt != "" {
+		allBlocks = append(allBlocks, createTextBlock(fmt.Sprintf(":warning: %s", errorTxt)))
+	}
+
+	var current []string
+	for _, space := range lot.GetSpacesInfo(userId) {
+		if space.Reserved && space.ReservedById == userId {
+			current = append(current, fmt.Sprintf("%s *%s* - whole day", ownEmoji, space.Key()))
+			continue
+		}
+		for _, reservation := range space.Slots {
+			if reservation.ReservedById == userId {
+				current = append(current, fmt.Sprintf(
+					"%s *%s* - %s",
+					ownEmoji,
+					space.Key(),
+					reservation.Slot,
+				))
+			}
+		}
+	}
+	if len(current) == 0 {
+		current = append(current, "_No reservation_")
+	}
+	allBlocks = append(allBlocks, createTextBlock(
+		"*Current reservation* (release it from the booking modal)\n"+strings.Join(current, "\n"),
+	))
+
+	allBlocks = append(allBlocks, slack.NewDividerBlock(), createTextBlock("*Upcoming days*"))
+	bookings := lot.UserDayBookings(userId)
+	if len(bookings) == 0 {
+		allBlocks = append(allBlocks, createTextBlock("_No upcoming bookings_"))
+	}
+	for _, booking := range bookings {
+		txt := fmt.Sprintf(
+			":calendar: %s - *%s*",
+			booking.Date.Format("Mon 2006-01-02"),
+			booking.SpaceKey,
+		)
+		cancelButton := slack.NewButtonBlockElement(
+			cancelDayBookingActionId,
+			views.ActionValues{BookingId: booking.Id}.Encode(),
+			slack.NewTextBlockObject("plain_text", "Cancel", true, false),
+		).WithStyle(slack.StyleDanger)
+		allBlocks = append(allBlocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", txt, false, false),
+			nil,
+			slack.NewAccessory(cancelButton),
+		))
+	}
+
+	return common.GenerateInfoModalRequest(myWorkspacesTitle, allBlocks)
+}
+
+func (m *Manager) handleBookDays(data *slackApi.BlockAction) []event.ResponseAction {
+	modal := m.generateBookDaysModal(data.UserId, data.ViewId)
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, modal)}
+}
+
+func (m *Manager) handleMyWorkspaces(data *slackApi.BlockAction) []event.ResponseAction {
+	modal := m.generateMyWorkspacesModal(data.UserId, "")
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, modal)}
+}
+
+func (m *Manager) handleCancelDayBooking(
+	data *slackApi.BlockAction,
+	actionValues views.ActionValues,
+) []event.ResponseAction {
+	errorTxt := ""
+	booking, err := m.data.WorkspacesLot.CancelDayBooking(data.UserId, actionValues.BookingId)
+	if err != nil {
+		errorTxt = fmt.Sprintf("Failed to cancel booking: %v", err)
+	} else {
+		slog.Info("Cancelled workspace booking", "user", data.UserName, "booking", booking)
+	}
+
+	modal := m.generateMyWorkspacesModal(data.UserId, errorTxt)
+	return []event.ResponseAction{
+		common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errorTxt),
+	}
+}
+
+func (m *Manager) handleBookDaysSubmission(data *slackApi.ViewSubmission) *common.Response {
+	spaceKey, dates, errTxt := m.bookDays(data)
+	if errTxt != "" {
+		slog.Error("Failed workspace booking", "user", data.UserName, "err", errTxt)
+		errTxt = fmt.Sprintf("Workspace booking failed: %s", errTxt)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+
+	var dateNames []string
+	for _, date := range dates {
+		dateNames = append(dateNames, date.Format("Mon 2006-01-02"))
+	}
+	txt := fmt.Sprintf(
+		":white_check_mark: Workspace *%s* is booked for: %s",
+		spaceKey,
+		strings.Join(dateNames, ", "),
+	)
+	actions := []event.ResponseAction{common.NewPostAction(data.UserId, txt, false)}
+
+	actionValues := views.ActionValues{}.Decode(data.PrivateMetadata)
+	modal := m.generateBookingModalRequest(data, data.UserId, m.selectedShowTaken[data.UserId], "")
+	actions = append(
+		actions,
+		common.NewUpdateViewAction(data.TriggerId, actionValues.RootViewId, modal, ""),
+	)
+	return common.NewResponseEvent(data.UserName, actions...)
+}
+
+// bookDays Validates book days modal & books the workspace. The date for
+// which reservations are currently made is reserved immediately. Nothing is
+// booked if any of the dates can't be booked.
+func (m *Manager) bookDays(data *slackApi.ViewSubmission) (spaces.SpaceKey, []time.Time, string) {
+	lot := m.data.WorkspacesLot
+	spaceKey := spaces.SpaceKey(data.IValueString(dayDeskBlockId, dayDeskActionId))
+	space, found := lot.UnitSpaces[spaceKey]
+	if !found {
+		return "", nil, fmt.Sprintf("couldn't find the workspace %q", spaceKey)
+	}
+
+	now := m.data.Clock.Now()
+	if err := m.data.UserManager.CheckBookingAllowed(data.UserId, now); err != nil {
+		return "", nil, err.Error()
+	}
+	bookingDate := m.data.Calendar.BookingDate(now, lot.ResetTime(spaces.MakeFloorStr(space.Floor)))
+	dates, errTxt := m.selectedDates(data, now.Location())
+	if errTxt != "" {
+		return "", nil, errTxt
+	}
+
+	var upcoming []time.Time
+	bookToday := false
+	quota := m.data.UserManager.GetQuota(data.UserId)
+	for _, date := range dates {
+		if err := spaces.CheckHorizon(quota, now, date); err != nil {
+			return "", nil, err.Error()
+		}
+		if date.Before(bookingDate) {
+			return "", nil, fmt.Sprintf(
+				"can't book for %s - reservations are currently made for %s",
+				date.Format("2006-01-02"),
+				bookingDate.Format("2006-01-02"),
+			)
+		}
+		if common.EqualDate(date, bookingDate) {
+			bookToday = true
+			continue
+		}
+		upcoming = append(upcoming, date)
+	}
+
+	userGroups := m.data.UserManager.GetGroups(data.UserId)
+	isAdminUser := m.data.UserManager.HasFloorPermission(
+		data.UserId,
+		user.PermManageWorkspaces,
+		space.Floor,
+	)
+	if bookToday && !isAdminUser && !(space.Reserved && space.ReservedById == data.UserId) &&
+		(lot.HasSpace(data.UserId) || lot.HasAnySlot(data.UserId)) {
+		return "", nil, fmt.Sprintf(
+			"you already reserved a workspace for %s",
+			bookingDate.Format("2006-01-02"),
+		)
+	}
+
+	var added spaces.DayBookings
+	if len(upcoming) > 0 {
+		var err error
+		added, err = lot.AddDayBookings(spaceKey, data.UserName, data.UserId, userGroups, upcoming)
+		if err != nil {
+			return "", nil, err.Error()
+		}
+	}
+
+	if bookToday {
+		autoRelease := true
+		errStr := lot.Reserve(spaceKey, data.UserName, data.UserId, userGroups, autoRelease)
+		if errStr != "" {
+			for _, booking := range added {
+				lot.CancelDayBooking(data.UserId, booking.Id)
+			}
+			return "", nil, errStr
+		}
+	}
+
+	slog.Info("Booked workspace days", "user", data.UserName, "space", spaceKey, "dates", dates)
+	return spaceKey, dates, ""
+}
+
+// selectedDates Returns sorted working days selected in the book days
+// modal (days of the week & date range)
+func (m *Manager) selectedDates(
+	data *slackApi.ViewSubmission,
+	location *time.Location,
+) ([]time.Time, string) {
+	var dates []time.Time
+	addDate := func(date time.Time) {
+		if !slices.ContainsFunc(dates, func(d time.Time) bool { return common.EqualDate(d, date) }) {
+			dates = append(dates, date)
+		}
+	}
+
+	for _, value := range data.IValue(weekDaysBlockId, weekDaysActionId) {
+		date, err := time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			return nil, fmt.Sprintf("invalid date %q", value)
+		}
+		addDate(date)
+	}
+
+	startStr := data.IValueString(rangeStartBlockId, rangeStartActionId)
+	endStr := data.IValueString(rangeEndBlockId, rangeEndActionId)
+	if (startStr == "") != (endStr == "") {
+		return nil, "select both the start & the end of the date range"
+	}
+	if startStr != "" {
+		start, err := time.ParseInLocation("2006-01-02", startStr, location)
+		if err != nil {
+			return nil, fmt.Sprintf("invalid date %q", startStr)
+		}
+		end, err := time.ParseInLocation("2006-01-02", endStr, location)
+		if err != nil {
+			return nil, fmt.Sprintf("invalid date %q", endStr)
+		}
+		if end.Before(start) {
+			return nil, fmt.Sprintf("range ends (%s) before it starts (%s)", endStr, startStr)
+		}
+
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			if m.data.Calendar.IsWorkingDay(date) {
+				addDate(date)
+			}
+		}
+	}
+
+	if len(dates) == 0 {
+		return nil, "select days of the week or a date range with working days"
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	return dates, ""
+}
+
+// applyDayBookings Reserves workspaces booked for the next working day &
+// informs users whose workspace was not free
+func (m *Manager) applyDayBookings(nextWorkingDay time.Time, resetTime calendar.TimeOfDay) {
+	applied, failed := m.data.WorkspacesLot.ApplyDayBookings(nextWorkingDay, resetTime)
+	slog.Info("ApplyDayBookings", "applied", len(applied), "failed", len(failed))
+
+	var actions []event.ResponseAction
+	for _, booking := range failed {
+		txt := fmt.Sprintf(
+			":warning: Your booked workspace *%s* is not free on *%s*. The booking was cancelled.",
+			booking.SpaceKey,
+			booking.Date.Format("Mon 2006-01-02"),
+		)
+		actions = append(actions, common.NewPostAction(booking.ReservedById, txt, false))
+	}
+	if len(actions) == 0 {
+		return
+	}
+	m.eventManager.Publish(common.NewResponseEvent("Workspaces ApplyDayBookings Timer", actions...))
+}
+
+func createTextBlock(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn", text, false, false),
+		nil,
+		nil,
+	)
+}
@@ -1,30 +1,32 @@
 package workspaces
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
+	"github.com/AngelVI13/slack-bot/pkg/calendar"
 	"github.com/AngelVI13/slack-bot/pkg/common"
 	"github.com/AngelVI13/slack-bot/pkg/config"
 	"github.com/AngelVI13/slack-bot/pkg/event"
 	"github.com/AngelVI13/slack-bot/pkg/model"
 	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/model/user"
 	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
 	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
 	"github.com/slack-go/slack"
 )
 
 const (
-	Identifier       = "Workspaces: "
-	SlashCmd         = "/workspace"
-	TestSlashCmd     = "/test-workspace"
-	ChannelNameQDev  = "qdev_technologies"
-	ChannelNameQDigi = "quadigi"
+	Identifier   = "Workspaces: "
+	SlashCmd     = "/workspace"
+	TestSlashCmd = "/test-workspace"
 
 	defaultUserOption = ""
 
 	ResetWorkspaces = "Reset workspaces status"
-	ResetHour       = 17
-	ResetMin        = 0
 )
 
 type Manager struct {
@@ -35,8 +37,18 @@ type Manager struct {
 	selectedFloor     map[string]string
 	selectedChannel   map[string]string
 	selectedShowTaken map[string]bool
-	reportPersonId    string
-	testingActive     bool
+	// selectedShowWeek Show weekly availability grid instead of spaces
+	selectedShowWeek map[string]bool
+	// selectedAttributes Attributes that shown workspaces must have
+	selectedAttributes map[string][]spaces.Attribute
+	// selectedSlots Time slots to reserve (whole day if empty)
+	selectedSlots map[string][]calendar.TimeSlot
+	// seedChannels channel name -> floors from config. Used to create the
+	// channel mapping the first time the command is used in the channel,
+	// afterwards the mapping is managed by admins.
+	seedChannels   map[string][]int
+	reportPersonId string
+	testingActive  bool
 }
 
 func NewManager(
@@ -45,18 +57,32 @@ func NewManager(
 	conf *config.Config,
 ) *Manager {
 	workspaceBookingTitle = common.MakeTitle(workspaceBookingTitle, conf.TestingActive)
+
+	seedChannels := map[string][]int{}
+	for _, channel := range conf.Channels {
+		seedChannels[channel.Name] = channel.Floors
+	}
+
 	return &Manager{
-		eventManager:      eventManager,
-		data:              data,
-		selectedFloor:     map[string]string{},
-		selectedChannel:   map[string]string{},
-		selectedShowTaken: map[string]bool{},
-		reportPersonId:    conf.ReportPersonId,
-		testingActive:     conf.TestingActive,
+		eventManager:       eventManager,
+		data:               data,
+		selectedFloor:      map[string]string{},
+		selectedChannel:    map[string]string{},
+		selectedShowTaken:  map[string]bool{},
+		selectedShowWeek:   map[string]bool{},
+		selectedAttributes: map[string][]spaces.Attribute{},
+		selectedSlots:      map[string][]calendar.TimeSlot{},
+		seedChannels:       seedChannels,
+		reportPersonId:     conf.ReportPersonId,
+		testingActive:      conf.TestingActive,
 	}
 }
 
 func (m *Manager) Consume(e event.Event) {
+	// NOTE: data must not be reloaded while an event is being handled
+	m.data.Lock()
+	defer m.data.Unlock()
+
 	switch e.Type() {
 	case event.SlashCmdEvent:
 		data := e.(*slackApi.Slash)
@@ -69,12 +95,19 @@ func (m *Manager) Consume(e event.Event) {
 			return
 		}
 
-		if !m.isValidChannel(data.ChannelName) {
-			// command is only allowed in a specific channel
+		channelId, found := m.resolveChannel(data)
+		if !found && isDirectMessage(data) {
+			errTxt := "There is no workspace channel configured for your team. " +
+				"Please use the command in your team's channel."
+			action := common.NewPostAction(data.UserId, errTxt, false)
+			m.eventManager.Publish(common.NewResponseEvent(data.UserName, action))
+			return
+		} else if !found {
+			// command is only allowed in configured channels
 			return
 		}
 
-		response := m.handleSlashCmd(data)
+		response := m.handleSlashCmd(data, channelId)
 
 		m.eventManager.Publish(response)
 	case event.BlockActionEvent:
@@ -87,14 +120,40 @@ func (m *Manager) Consume(e event.Event) {
 
 		m.eventManager.Publish(response)
 
+	case event.ViewSubmissionEvent:
+		data := e.(*slackApi.ViewSubmission)
+		var response *common.Response
+		switch data.Title {
+		case bookDaysTitle:
+			response = m.handleBookDaysSubmission(data)
+		case teamBookingTitle:
+			response = m.handleTeamBookingSubmission(data)
+		default:
+			return
+		}
+
+		m.eventManager.Publish(response)
+
 	case event.TimerEvent:
 		data := e.(*event.TimerDone)
 		if data.Label != ResetWorkspaces {
 			return
 		}
 
-		slog.Info("ReleaseWorkspaces")
-		err := m.data.WorkspacesLot.ReleaseSpaces(data.Time)
+		// NOTE: reservations made on a non-working day are for the next
+		// working day so they should not be reset
+		if !m.data.Calendar.IsWorkingDay(data.Scheduled) {
+			slog.Info("Skip ReleaseWorkspaces: not a working day", "scheduled", data.Scheduled)
+			return
+		}
+
+		// NOTE: every floor reset time has its own job so only floors
+		// that reset at the scheduled time are released
+		resetTime := calendar.TimeOfDayOf(data.Scheduled)
+		slog.Info("ReleaseWorkspaces", "resetTime", resetTime)
+		// NOTE: scheduled time is used so that a caught up reset is done for
+		// the same next working day as the missed one
+		err := m.data.WorkspacesLot.ReleaseSpaces(data.Scheduled, resetTime, m.data.Calendar)
 		if err != nil {
 			postAction := common.NewPostAction(
 				m.reportPersonId,
@@ -108,6 +167,8 @@ func (m *Manager) Consume(e event.Event) {
 			)
 			m.eventManager.Publish(response)
 		}
+
+		m.applyDayBookings(m.data.Calendar.NextWorkingDay(data.Scheduled), resetTime)
 	}
 }
 
@@ -115,9 +176,18 @@ func (m *Manager) Context() string {
 	return Identifier
 }
 
-func (m *Manager) handleSlashCmd(data *slackApi.Slash) *common.Response {
+func (m *Manager) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop Nothing to flush - workspaces are written to file by model.Data
+func (m *Manager) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *Manager) handleSlashCmd(data *slackApi.Slash, channelId string) *common.Response {
 	errorTxt := ""
-	m.selectedChannel[data.UserId] = data.ChannelName
+	m.selectedChannel[data.UserId] = channelId
 	modal := m.generateBookingModalRequest(
 		data,
 		data.UserId,
@@ -134,10 +204,10 @@ func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Respons
 	var actions []event.ResponseAction
 
 	if _, ok := m.selectedFloor[data.UserId]; !ok {
-		channelName, found := m.selectedChannel[data.UserId]
+		channelId, found := m.selectedChannel[data.UserId]
 		defaultFloorOption := ""
 		if found {
-			defaultFloorOption = m.defaultFloorOption(channelName)
+			defaultFloorOption = m.defaultFloorOption(channelId)
 		}
 		m.selectedFloor[data.UserId] = defaultFloorOption
 	}
@@ -176,10 +246,70 @@ func (m *Manager) handleBlockActions(data *slackApi.BlockAction) *common.Respons
 				m.selectedFloor[data.UserId],
 				m.selectedShowTaken[data.UserId],
 			)
+		case releaseSlotsActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleReleaseSlots(
+				data,
+				actionValues.SpaceKey,
+				m.selectedShowTaken[data.UserId],
+			)
+		case slotsOptionId:
+			var selectedSlots []calendar.TimeSlot
+			errorTxt := ""
+			for _, option := range action.SelectedOptions {
+				slot, err := calendar.ParseTimeSlot(option.Value)
+				if err != nil {
+					errorTxt = err.Error()
+					continue
+				}
+				selectedSlots = append(selectedSlots, slot)
+			}
+			m.selectedSlots[data.UserId] = selectedSlots
+			modal := m.generateBookingModalRequest(
+				data,
+				data.UserId,
+				m.selectedShowTaken[data.UserId],
+				errorTxt,
+			)
+			actions = append(
+				actions,
+				common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errorTxt),
+			)
+		case views.AttributesOptionId:
+			var selectedValues []string
+			for _, option := range action.SelectedOptions {
+				selectedValues = append(selectedValues, option.Value)
+			}
+			selectedAttributes, err := spaces.ParseAttributes(selectedValues)
+			errorTxt := ""
+			if err != nil {
+				errorTxt = err.Error()
+			}
+			m.selectedAttributes[data.UserId] = selectedAttributes
+			modal := m.generateBookingModalRequest(
+				data,
+				data.UserId,
+				m.selectedShowTaken[data.UserId],
+				errorTxt,
+			)
+			actions = append(
+				actions,
+				common.NewUpdateViewAction(data.TriggerId, data.ViewId, modal, errorTxt),
+			)
+		case bookDaysActionId:
+			actions = m.handleBookDays(data)
+		case myWorkspacesActionId:
+			actions = m.handleMyWorkspaces(data)
+		case teamBookingActionId:
+			actions = m.handleTeamBooking(data)
+		case cancelDayBookingActionId:
+			actionValues := views.ActionValues{}.Decode(action.Value)
+			actions = m.handleCancelDayBooking(data, actionValues)
 		case showOptionId:
 			selectedShowValue := data.Values[showActionId][showOptionId].SelectedOption.Value
 			selectedShowOption := selectedShowValue == showTakenOption
 			m.selectedShowTaken[data.UserId] = selectedShowOption
+			m.selectedShowWeek[data.UserId] = selectedShowValue == showWeekOption
 			errorTxt := ""
 			modal := m.generateBookingModalRequest(
 				data,
@@ -209,12 +339,27 @@ func (m *Manager) handleReserveWorkspace(
 ) []event.ResponseAction {
 	autoRelease := true // by default workspace reservation is always with auto release
 
-	errStr := m.data.WorkspacesLot.Reserve(
-		workSpace,
-		data.UserName,
-		data.UserId,
-		autoRelease,
-	)
+	var errStr string
+	err := m.data.UserManager.CheckBookingAllowed(data.UserId, m.data.Clock.Now())
+	if err != nil {
+		errStr = fmt.Sprintf("*Error*: Could not reserve *%s* - %v", workSpace, err)
+	} else if selectedSlots := m.selectedTimeSlots(data.UserId); len(selectedSlots) > 0 {
+		errStr = m.data.WorkspacesLot.ReserveSlots(
+			workSpace,
+			data.UserName,
+			data.UserId,
+			m.data.UserManager.GetGroups(data.UserId),
+			selectedSlots,
+		)
+	} else {
+		errStr = m.data.WorkspacesLot.Reserve(
+			workSpace,
+			data.UserName,
+			data.UserId,
+			m.data.UserManager.GetGroups(data.UserId),
+			autoRelease,
+		)
+	}
 
 	bookingModal := m.generateBookingModalRequest(
 		data,
@@ -249,7 +394,12 @@ func (m *Manager) handleReleaseWorkspace(
 	}
 
 	// Only remove release info from a space if an Admin is permanently releasing the space
-	if m.data.UserManager.IsAdminId(data.UserId) {
+	space := m.data.WorkspacesLot.GetSpace(workSpace)
+	if space != nil && m.data.UserManager.HasFloorPermission(
+		data.UserId,
+		user.PermManageWorkspaces,
+		space.Floor,
+	) {
 		m.data.WorkspacesLot.ToBeReleased.RemoveAllReleases(workSpace)
 	}
 
@@ -271,25 +421,103 @@ func (m *Manager) handleReleaseWorkspace(
 	return actions
 }
 
-func (m *Manager) isValidChannel(channelName string) bool {
-	return channelName == ChannelNameQDev || channelName == ChannelNameQDigi
+// handleReleaseSlots Releases time slots of the space reserved by the user.
+// Admins release slots of everyone.
+func (m *Manager) handleReleaseSlots(
+	data *slackApi.BlockAction,
+	workSpace spaces.SpaceKey,
+	selectedShowTaken bool,
+) []event.ResponseAction {
+	actions := []event.ResponseAction{}
+
+	space := m.data.WorkspacesLot.GetSpace(workSpace)
+	if space != nil {
+		isAdminUser := m.data.UserManager.HasFloorPermission(
+			data.UserId,
+			user.PermManageWorkspaces,
+			space.Floor,
+		)
+		victimIds, msg := m.data.WorkspacesLot.
+			ReleaseSlots(workSpace, data.UserName, data.UserId, isAdminUser)
+		for _, victimId := range victimIds {
+			slog.Info(msg, "victim", victimId)
+			actions = append(actions, common.NewPostAction(victimId, msg, false))
+		}
+	}
+
+	errTxt := ""
+	bookingModal := m.generateBookingModalRequest(
+		data,
+		data.UserId,
+		selectedShowTaken,
+		errTxt,
+	)
+	action := common.NewUpdateViewAction(
+		data.TriggerId,
+		data.ViewId,
+		bookingModal,
+		errTxt,
+	)
+	actions = append(actions, action)
+
+	return actions
+}
+
+func isDirectMessage(data *slackApi.Slash) bool {
+	return data.ChannelName == "directmessage" || strings.HasPrefix(data.ChannelId, "D")
 }
 
-func (m *Manager) floorsForChannel(channelName string) []int {
-	var floors []int
+// resolveChannel Finds the channel whose floors are shown to the user. In
+// direct messages the channel of user's team is used.
+func (m *Manager) resolveChannel(data *slackApi.Slash) (string, bool) {
+	lot := m.data.WorkspacesLot
+	if channel, found := lot.Channel(data.ChannelId); found {
+		// NOTE: channel selected by admin only has an ID -> remember its name
+		if channel.Name != data.ChannelName && !isDirectMessage(data) {
+			channel.Name = data.ChannelName
+			lot.SynchronizeToFile()
+		}
+		return data.ChannelId, true
+	}
+
+	if isDirectMessage(data) {
+		return lot.ChannelOfCompanies(m.data.UserManager.GetCompanies(data.UserId))
+	}
 
-	switch channelName {
-	case ChannelNameQDev:
-		floors = []int{4, 6}
-	case ChannelNameQDigi:
-		floors = []int{5, 7}
+	floors, found := m.seedChannels[data.ChannelName]
+	if !found {
+		return "", false
 	}
 
-	return floors
+	slog.Info(
+		"Adding workspace channel from config",
+		"channel", data.ChannelName,
+		"channelId", data.ChannelId,
+		"floors", floors,
+	)
+	lot.SetChannel(data.ChannelId, spaces.ChannelFloors{
+		Name:   data.ChannelName,
+		Floors: floors,
+	})
+	lot.SynchronizeToFile()
+	return data.ChannelId, true
 }
 
-func (m *Manager) defaultFloorOption(channelName string) string {
-	floors := m.data.WorkspacesLot.GetExistingFloors(m.floorsForChannel(channelName))
+func (m *Manager) floorsForChannel(channelId string) []int {
+	channel, found := m.data.WorkspacesLot.Channel(channelId)
+	if !found {
+		return nil
+	}
+	return channel.Floors
+}
+
+func (m *Manager) defaultFloorOption(channelId string) string {
+	floors := m.data.WorkspacesLot.GetExistingFloors(m.floorsForChannel(channelId))
+
+	channel, found := m.data.WorkspacesLot.Channel(channelId)
+	if found && slices.Contains(floors, spaces.MakeFloorStr(channel.DefaultFloor)) {
+		return spaces.MakeFloorStr(channel.DefaultFloor)
+	}
 
 	defaultFloorOption := ""
 	if len(floors) > 0 {
@@ -0,0 +1,175 @@
+package workspaces
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/AngelVI13/slack-bot/pkg/common"
+	"github.com/AngelVI13/slack-bot/pkg/event"
+	"github.com/AngelVI13/slack-bot/pkg/model/spaces"
+	"github.com/AngelVI13/slack-bot/pkg/parking_spaces/views"
+	slackApi "github.com/AngelVI13/slack-bot/pkg/slack"
+	"github.com/slack-go/slack"
+)
+
+const (
+	teamBookingActionId         = "workspaceTeamBooking"
+	teamNeighbourhoodBlockId    = "workspaceTeamNeighbourhoodBlockId"
+	teamNeighbourhoodActionId   = "workspaceTeamNeighbourhoodActionId"
+	teamMembersBlockId          = "workspaceTeamMembersBlockId"
+	teamMembersActionId         = "workspaceTeamMembersActionId"
+	maxNeighbourhoodOptionCount = 100
+)
+
+var teamBookingTitle = Identifier + "Team booking"
+
+// neighbourhoodNames Neighbourhoods on floors of the channel selected by the
+// user
+func (m *Manager) neighbourhoodNames(userId string) []string {
+	return m.data.WorkspacesLot.NeighbourhoodNames(m.floorsForChannel(m.selectedChannel[userId]))
+}
+
+func generateTeamBookingButton() slack.BlockElement {
+	return slack.NewButtonBlockElement(
+		teamBookingActionId,
+		"",
+		slack.NewTextBlockObject("plain_text", "Book for my team :busts_in_silhouette:", true, false),
+	)
+}
+
+// generateTeamBookingModal NOTE: this is pushed on top of the booking modal
+// which is refreshed after submission (rootViewId)
+func (m *Manager) generateTeamBookingModal(userId, rootViewId string) slack.ModalViewRequest {
+	description := "Reserve adjacent workspaces of a neighbourhood for your team. " +
+		"Either everyone gets a workspace or nobody does. Each team member " +
+		"receives a message with their workspace."
+	allBlocks := []slack.Block{createTextBlock(description)}
+
+	var neighbourhoodOptions []*slack.OptionBlockObject
+	for _, name := range m.neighbourhoodNames(userId) {
+		if len(neighbourhoodOptions) == maxNeighbourhoodOptionCount {
+			break
+		}
+		neighbourhood := m.data.WorkspacesLot.Neighbourhoods[name]
+		label := fmt.Sprintf(
+			"%s (%s, %d desks)",
+			name,
+			spaces.MakeFloorStr(neighbourhood.Floor),
+			len(neighbourhood.Spaces),
+		)
+		neighbourhoodOptions = append(neighbourhoodOptions, slack.NewOptionBlockObject(
+			name,
+			slack.NewTextBlockObject(slack.PlainTextType, label, false, false),
+			nil,
+		))
+	}
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		teamNeighbourhoodBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Neighbourhood", false, false),
+		nil,
+		slack.NewOptionsSelectBlockElement(
+			slack.OptTypeStatic,
+			slack.NewTextBlockObject(slack.PlainTextType, "Choose a neighbourhood", false, false),
+			teamNeighbourhoodActionId,
+			neighbourhoodOptions...,
+		),
+	))
+
+	membersSelect := slack.NewOptionsMultiSelectBlockElement(
+		slack.MultiOptTypeUser,
+		slack.NewTextBlockObject(slack.PlainTextType, "Select team members", false, false),
+		teamMembersActionId,
+	)
+	membersSelect.InitialUsers = []string{userId}
+	allBlocks = append(allBlocks, slack.NewInputBlock(
+		teamMembersBlockId,
+		slack.NewTextBlockObject(slack.PlainTextType, "Team members", false, false),
+		nil,
+		membersSelect,
+	))
+
+	modal := common.GenerateModalRequest(teamBookingTitle, allBlocks)
+	modal.PrivateMetadata = views.ActionValues{RootViewId: rootViewId}.Encode()
+	return modal
+}
+
+func (m *Manager) handleTeamBooking(data *slackApi.BlockAction) []event.ResponseAction {
+	modal := m.generateTeamBookingModal(data.UserId, data.ViewId)
+	return []event.ResponseAction{common.NewPushViewAction(data.TriggerId, modal)}
+}
+
+func (m *Manager) handleTeamBookingSubmission(data *slackApi.ViewSubmission) *common.Response {
+	name := data.IValueString(teamNeighbourhoodBlockId, teamNeighbourhoodActionId)
+
+	var members []spaces.TeamMember
+	var limited []string
+	now := m.data.Clock.Now()
+	for _, memberId := range data.IValue(teamMembersBlockId, teamMembersActionId) {
+		memberName := m.data.UserManager.GetNameFromId(memberId)
+		if memberId == data.UserId {
+			memberName = data.UserName
+		} else if memberName == "" {
+			memberName = fmt.Sprintf("<@%s>", memberId)
+		}
+		members = append(members, spaces.TeamMember{Name: memberName, Id: memberId})
+
+		if m.data.UserManager.CheckBookingAllowed(memberId, now) != nil {
+			limited = append(limited, memberName)
+		}
+	}
+
+	var team spaces.SpacesInfo
+	var err error
+	if len(limited) > 0 {
+		err = fmt.Errorf("booking rights are limited: %s", strings.Join(limited, ", "))
+	} else {
+		team, err = m.data.WorkspacesLot.ReserveForTeam(name, members, m.data.UserManager.GetGroups)
+	}
+	if err != nil {
+		slog.Error("Failed team booking", "user", data.UserName, "neighbourhood", name, "err", err)
+		errTxt := fmt.Sprintf("Team booking failed - nobody was booked: %v", err)
+		return common.NewResponseEvent(
+			data.UserName,
+			common.NewPostAction(data.UserId, errTxt, false),
+		)
+	}
+
+	resetTime := m.data.WorkspacesLot.ResetTime(spaces.MakeFloorStr(team[0].Floor))
+	date := m.data.Calendar.BookingDate(m.data.Clock.Now(), resetTime)
+
+	var actions []event.ResponseAction
+	var summary []string
+	for i, space := range team {
+		member := members[i]
+		summary = append(summary, fmt.Sprintf("• <@%s> - *%s*", member.Id, space.Key()))
+		if member.Id == data.UserId {
+			continue
+		}
+
+		txt := fmt.Sprintf(
+			":busts_in_silhouette: *%s* reserved workspace *%s* for you on *%s* "+
+				"(neighbourhood *%s*).",
+			data.UserName,
+			space.Key(),
+			date.Format("Mon 2006-01-02"),
+			name,
+		)
+		actions = append(actions, common.NewPostAction(member.Id, txt, false))
+	}
+	txt := fmt.Sprintf(
+		":white_check_mark: Workspaces in neighbourhood *%s* are reserved for %s:\n%s",
+		name,
+		date.Format("Mon 2006-01-02"),
+		strings.Join(summary, "\n"),
+	)
+	actions = append(actions, common.NewPostAction(data.UserId, txt, false))
+
+	actionValues := views.ActionValues{}.Decode(data.PrivateMetadata)
+	modal := m.generateBookingModalRequest(data, data.UserId, m.selectedShowTaken[data.UserId], "")
+	actions = append(
+		actions,
+		common.NewUpdateViewAction(data.TriggerId, actionValues.RootViewId, modal, ""),
+	)
+	return common.NewResponseEvent(data.UserName, actions...)
+}